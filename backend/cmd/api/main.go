@@ -7,16 +7,46 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	authDomain "github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/keys"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/oidc"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/password"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/repository/cache"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/repository/memory"
 	"github.com/Ex6linz/BookSwap/backend/internal/auth/repository/postgres"
 	authService "github.com/Ex6linz/BookSwap/backend/internal/auth/service"
 	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	bookPostgres "github.com/Ex6linz/BookSwap/backend/internal/book/repository/postgres"
+	bookService "github.com/Ex6linz/BookSwap/backend/internal/book/service"
+	bookRest "github.com/Ex6linz/BookSwap/backend/internal/book/transport/rest"
+	messagesPostgres "github.com/Ex6linz/BookSwap/backend/internal/messages/repository/postgres"
+	messagesService "github.com/Ex6linz/BookSwap/backend/internal/messages/service"
+	messagesRest "github.com/Ex6linz/BookSwap/backend/internal/messages/transport/rest"
+	moderationPostgres "github.com/Ex6linz/BookSwap/backend/internal/moderation/repository/postgres"
+	moderationService "github.com/Ex6linz/BookSwap/backend/internal/moderation/service"
+	moderationRest "github.com/Ex6linz/BookSwap/backend/internal/moderation/transport/rest"
+	oauthPostgres "github.com/Ex6linz/BookSwap/backend/internal/oauth/repository/postgres"
+	oauthService "github.com/Ex6linz/BookSwap/backend/internal/oauth/service"
+	oauthRest "github.com/Ex6linz/BookSwap/backend/internal/oauth/transport/rest"
+	reviewPostgres "github.com/Ex6linz/BookSwap/backend/internal/review/repository/postgres"
+	reviewService "github.com/Ex6linz/BookSwap/backend/internal/review/service"
+	reviewRest "github.com/Ex6linz/BookSwap/backend/internal/review/transport/rest"
+	transactionsPostgres "github.com/Ex6linz/BookSwap/backend/internal/transactions/repository/postgres"
+	transactionsService "github.com/Ex6linz/BookSwap/backend/internal/transactions/service"
+	transactionsRest "github.com/Ex6linz/BookSwap/backend/internal/transactions/transport/rest"
+	usersPostgres "github.com/Ex6linz/BookSwap/backend/internal/users/repository/postgres"
+	usersService "github.com/Ex6linz/BookSwap/backend/internal/users/service"
+	usersRest "github.com/Ex6linz/BookSwap/backend/internal/users/transport/rest"
 	"github.com/Ex6linz/BookSwap/backend/pkg/config"
+	"github.com/Ex6linz/BookSwap/backend/pkg/mailer"
+	"github.com/Ex6linz/BookSwap/backend/pkg/storage"
 )
 
 func main() {
@@ -43,31 +73,344 @@ func main() {
 	defer dbPool.Close()
 
 	// 3. Inicjalizacja komponentów autentykacji
+	staticKeys := make([]keys.StaticKey, 0, len(cfg.JWT.Keys))
+	for _, k := range cfg.JWT.Keys {
+		staticKeys = append(staticKeys, keys.StaticKey(k))
+	}
+	keyManager, err := keys.NewManager(keys.Options{
+		Algorithm:        cfg.JWT.Algorithm,
+		Dir:              cfg.JWT.KeyDir,
+		StaticKeys:       staticKeys,
+		RotationInterval: cfg.JWT.RotationInterval,
+		Retention:        cfg.JWT.KeyRetention,
+		ReloadInterval:   cfg.JWT.KeyReloadInterval,
+	})
+	if err != nil {
+		log.Fatalf("Błąd ładowania kluczy JWT: %v", err)
+	}
+
+	// Zadania w tle (rotacja kluczy, czyszczenie kont) kończą się razem z serwerem
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go keyManager.Run(backgroundCtx)
+
+	mail, err := mailer.New(cfg.Mail.Driver, cfg.Mail.From, cfg.Mail.Dir)
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji poczty: %v", err)
+	}
+
+	fileStorage, err := storage.New(storage.Config{
+		Driver:       cfg.Storage.Driver,
+		LocalDir:     cfg.Storage.Local.Dir,
+		LocalBaseURL: cfg.Storage.Local.BaseURL,
+		S3: storage.S3Config{
+			Endpoint:        cfg.Storage.S3.Endpoint,
+			Region:          cfg.Storage.S3.Region,
+			Bucket:          cfg.Storage.S3.Bucket,
+			AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+			PublicURL:       cfg.Storage.S3.PublicURL,
+			PathStyle:       cfg.Storage.S3.PathStyle,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji magazynu plików: %v", err)
+	}
+
 	userRepo := postgres.NewUserRepository(dbPool)
-	authSvc := authService.NewAuthService(userRepo, cfg.JWT.Secret)
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(dbPool)
+	sessionRepo := postgres.NewSessionRepository(dbPool)
+	apiKeyRepo := postgres.NewAPIKeyRepository(dbPool)
+	oneTimeTokenRepo := postgres.NewOneTimeTokenRepository(dbPool)
+	revocations := cache.NewRevocationStore(
+		postgres.NewRevocationStore(dbPool),
+		cfg.JWT.RevocationCacheTTL,
+	)
+
+	var loginAttempts authService.LoginAttemptStore
+	switch cfg.Auth.Lockout.Store {
+	case "memory":
+		loginAttempts = memory.NewLoginAttemptStore()
+	case "postgres":
+		loginAttempts = postgres.NewLoginAttemptStore(dbPool)
+	default:
+		log.Fatalf("Nieznany magazyn blokad logowania: %q", cfg.Auth.Lockout.Store)
+	}
+	loginGuard := authService.NewLoginGuard(
+		loginAttempts,
+		authDomain.LockoutPolicy(cfg.Auth.Lockout.Account),
+		authDomain.LockoutPolicy(cfg.Auth.Lockout.IP),
+	)
+
+	verificationSvc := authService.NewEmailVerificationService(
+		userRepo,
+		oneTimeTokenRepo,
+		mail,
+		cfg.Auth.EmailVerificationTTL,
+		cfg.App.BaseURL,
+	)
+	mfaRepo := postgres.NewMFARepository(dbPool)
+	mfaSecrets, err := authService.NewSecretBox(cfg.MFA.EncryptionKey)
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji MFA: %v", err)
+	}
+
+	passwordHasher, err := password.New(
+		cfg.Auth.Password.Algorithm,
+		password.Argon2Params{
+			Memory:      cfg.Auth.Password.Argon2.Memory,
+			Iterations:  cfg.Auth.Password.Argon2.Iterations,
+			Parallelism: cfg.Auth.Password.Argon2.Parallelism,
+		},
+		cfg.Auth.Password.BcryptCost,
+	)
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji haszowania haseł: %v", err)
+	}
+
+	var breachedPasswords authService.BreachedPasswords
+	if dir := cfg.Auth.Password.Breached.Dir; dir != "" {
+		list, err := password.NewBreachedList(dir, cfg.Auth.Password.Breached.MinCount)
+		if err != nil {
+			log.Fatalf("Błąd ładowania listy haseł z wycieków: %v", err)
+		}
+		breachedPasswords = list
+	}
+	passwordChecker := authService.NewPasswordChecker(
+		authDomain.PasswordPolicy(cfg.Auth.Password.Policy),
+		breachedPasswords,
+	)
+
+	authSvc := authService.NewAuthService(
+		userRepo,
+		refreshTokenRepo,
+		sessionRepo,
+		revocations,
+		verificationSvc,
+		loginGuard,
+		mfaRepo,
+		apiKeyRepo,
+		passwordHasher,
+		passwordChecker,
+		keyManager,
+		cfg.JWT.AccessTTL,
+		cfg.JWT.RefreshTTL,
+	)
+	passwordResetSvc := authService.NewPasswordResetService(
+		userRepo,
+		oneTimeTokenRepo,
+		mail,
+		authSvc,
+		passwordHasher,
+		passwordChecker,
+		cfg.Auth.PasswordResetTTL,
+		cfg.App.BaseURL,
+	)
+	emailChangeSvc := authService.NewEmailChangeService(
+		userRepo,
+		oneTimeTokenRepo,
+		mail,
+		authSvc,
+		cfg.Auth.EmailChangeTTL,
+		cfg.App.BaseURL,
+	)
+	mfaSvc := authService.NewMFAService(mfaRepo, userRepo, authSvc, mfaSecrets, cfg.MFA.Issuer)
+
+	oidcProviders := make([]*oidc.Provider, 0, len(cfg.OIDC.Providers))
+	for _, p := range cfg.OIDC.Providers {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			Scopes:       p.Scopes,
+		}, nil))
+	}
+	oidcSvc := authService.NewOIDCService(
+		authSvc,
+		oidcProviders,
+		postgres.NewOIDCStateRepository(dbPool),
+		postgres.NewIdentityRepository(dbPool),
+		cfg.OIDC.RedirectURL,
+		cfg.OIDC.StateTTL,
+	)
+
 	authHandler := authRest.NewAuthHandler(authSvc)
+	passwordResetHandler := authRest.NewPasswordResetHandler(passwordResetSvc)
+	verificationHandler := authRest.NewEmailVerificationHandler(verificationSvc)
+	mfaHandler := authRest.NewMFAHandler(mfaSvc)
+	emailChangeHandler := authRest.NewEmailChangeHandler(emailChangeSvc)
+	oidcHandler := authRest.NewOIDCHandler(oidcSvc)
+	apiKeyHandler := authRest.NewAPIKeyHandler(authService.NewAPIKeyService(apiKeyRepo))
+
+	impersonationSvc := authService.NewImpersonationService(
+		authSvc,
+		userRepo,
+		postgres.NewAuditRepository(dbPool),
+		cfg.Auth.ImpersonationTTL,
+	)
+	impersonationHandler := authRest.NewImpersonationHandler(impersonationSvc)
+
+	bookSvc := bookService.NewBookService(
+		bookPostgres.NewBookRepository(dbPool),
+		bookPostgres.NewImageRepository(dbPool),
+		fileStorage,
+		bookService.ImageConfig{
+			MaxSize:    cfg.Images.MaxSize,
+			MaxPerBook: cfg.Images.MaxPerBook,
+			Quality:    cfg.Images.Quality,
+		},
+	)
+	bookHandler := bookRest.NewBookHandler(bookSvc)
+	transactionHandler := transactionsRest.NewTransactionHandler(
+		transactionsService.NewTransactionService(transactionsPostgres.NewTransactionRepository(dbPool)),
+	)
+	messageHandler := messagesRest.NewMessageHandler(
+		messagesService.NewMessageService(messagesPostgres.NewMessageRepository(dbPool)),
+	)
+	reviewHandler := reviewRest.NewReviewHandler(
+		reviewService.NewReviewService(reviewPostgres.NewReviewRepository(dbPool)),
+	)
+
+	moderationSvc := moderationService.NewModerationService(moderationPostgres.NewModerationRepository(dbPool))
+	moderationHandler := moderationRest.NewModerationHandler(moderationSvc)
+
+	profileRepo := usersPostgres.NewUserRepository(dbPool)
+	userSvc := usersService.NewUserService(profileRepo)
+	accountSvc := usersService.NewAccountService(
+		profileRepo,
+		usersPostgres.NewExportRepository(dbPool),
+		authSvc,
+		authSvc,
+		bookSvc,
+		mail,
+		cfg.Auth.AccountDeletionGrace,
+	)
+	go accountSvc.RunPurger(backgroundCtx, cfg.Auth.AccountPurgeInterval)
+
+	userHandler := usersRest.NewUserHandler(userSvc)
+	accountHandler := usersRest.NewAccountHandler(accountSvc)
+
+	oauthSvc := oauthService.NewOAuthService(
+		oauthPostgres.NewClientRepository(dbPool),
+		oauthPostgres.NewCodeRepository(dbPool),
+		oauthPostgres.NewConsentRepository(dbPool),
+		authSvc,
+		cfg.OAuth.AuthorizationCodeTTL,
+		cfg.OAuth.IntrospectionClients,
+	)
+	oauthHandler := oauthRest.NewOAuthHandler(oauthSvc)
 
 	// 4. Konfiguracja routera Gin
 	router := gin.Default()
 
 	// 5. Rejestracja endpointów
+	router.GET("/.well-known/jwks.json", authRest.JWKSHandler(keyManager))
+
+	// Lokalny magazyn zdjęć pod ścieżką względną serwuje samo API
+	if cfg.Storage.Driver == "local" && strings.HasPrefix(cfg.Storage.Local.BaseURL, "/") {
+		router.Static(cfg.Storage.Local.BaseURL, cfg.Storage.Local.Dir)
+	}
+
 	// Publiczne endpointy
 	public := router.Group("/api/v1")
 	{
 		public.POST("/auth/register", authHandler.Register)
 		public.POST("/auth/login", authHandler.Login)
+		public.POST("/auth/refresh", authHandler.Refresh)
+		public.POST("/auth/introspect", oauthHandler.Introspect)
+		public.POST("/auth/password/forgot", passwordResetHandler.Forgot)
+		public.POST("/auth/password/reset", passwordResetHandler.Reset)
+		public.POST("/auth/verify-email", verificationHandler.Verify)
+		public.POST("/auth/mfa/verify", mfaHandler.Verify)
+		public.GET("/auth/oidc/providers", oidcHandler.Providers)
+		public.POST("/auth/oidc/:provider/start", oidcHandler.Start)
+		public.POST("/auth/oidc/callback", oidcHandler.Callback)
+		public.POST("/auth/email/confirm", emailChangeHandler.Confirm)
+		public.GET("/users/:id", userHandler.GetProfile)
+		public.GET("/users/:id/reviews", reviewHandler.ListForUser)
+		public.GET("/books", bookHandler.List)
+		public.GET("/books/:id", authRest.OptionalAuth(authSvc), authRest.ImpersonationGuard(impersonationSvc), bookHandler.Get)
+		public.POST("/oauth/token", oauthHandler.Token)
 	}
 
-	// Chronione endpointy (wymagają JWT)
+	// Chronione endpointy (wymagają JWT lub klucza API). Żądania administratora
+	// w imieniu użytkownika trafiają do dziennika audytu.
 	protected := router.Group("/api/v1")
-	protected.Use(authRest.AuthMiddleware(cfg.JWT.Secret))
+	protected.Use(authRest.AuthMiddleware(authSvc), authRest.ImpersonationGuard(impersonationSvc))
 	{
+		protected.GET("/me", authRest.RequireScope(authDomain.ScopeProfileRead), userHandler.GetMe)
+		protected.PATCH("/me", authRest.RequireScope(authDomain.ScopeProfileWrite), userHandler.UpdateMe)
+		protected.GET("/me/books", authRest.RequireScope(authDomain.ScopeBooksRead), bookHandler.ListMine)
+		protected.GET("/me/transactions", authRest.RequireScope(authDomain.ScopeTransactionsRead), transactionHandler.ListMine)
+		protected.GET("/me/messages", authRest.RequireScope(authDomain.ScopeMessagesRead), messageHandler.ListMine)
+
 		// Tutaj dodamy później chronione endpointy
 		protected.GET("/test-auth", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"message": "Jesteś zalogowany!"})
 		})
 	}
 
+	// Operacje na sesjach i danych logowania - niedostępne dla kluczy API
+	session := protected.Group("")
+	session.Use(authRest.RequireSession())
+	{
+		session.POST("/auth/logout", authHandler.Logout)
+		session.POST("/auth/logout-all", authHandler.LogoutAll)
+		session.POST("/auth/verify-email/resend", verificationHandler.Resend)
+		session.GET("/me/sessions", authHandler.ListSessions)
+		session.DELETE("/me/sessions/:id", authHandler.RevokeSession)
+		session.POST("/auth/mfa/enroll", mfaHandler.Enroll)
+		session.POST("/auth/mfa/confirm", mfaHandler.Confirm)
+		session.POST("/auth/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+		session.POST("/auth/mfa/disable", mfaHandler.Disable)
+		session.DELETE("/me", accountHandler.DeleteMe)
+		session.POST("/me/deletion/cancel", accountHandler.CancelDeletion)
+		session.GET("/me/export", accountHandler.Export)
+		session.POST("/me/password", authHandler.ChangePassword)
+		session.POST("/me/email", emailChangeHandler.RequestChange)
+		session.POST("/me/api-keys", apiKeyHandler.Create)
+		session.GET("/me/api-keys", apiKeyHandler.List)
+		session.DELETE("/me/api-keys/:id", apiKeyHandler.Revoke)
+		session.GET("/oauth/authorize", oauthHandler.PrepareAuthorization)
+		session.POST("/oauth/authorize", oauthHandler.Authorize)
+		session.POST("/oauth/clients", oauthHandler.RegisterClient)
+		session.GET("/oauth/clients", oauthHandler.ListClients)
+		session.DELETE("/oauth/clients/:clientId", oauthHandler.DeleteClient)
+		session.GET("/me/oauth/consents", oauthHandler.ListConsents)
+		session.DELETE("/me/oauth/consents/:clientId", oauthHandler.RevokeConsent)
+	}
+
+	// Endpointy wymagające potwierdzonego adresu email
+	verified := protected.Group("")
+	verified.Use(authRest.RequireVerifiedEmail())
+
+	{
+		verified.POST("/books", authRest.RequireScope(authDomain.ScopeBooksWrite), bookHandler.Create)
+		verified.PATCH("/books/:id", authRest.RequireScope(authDomain.ScopeBooksWrite), bookHandler.Update)
+		verified.DELETE("/books/:id", authRest.RequireScope(authDomain.ScopeBooksWrite), bookHandler.Delete)
+		verified.POST("/books/:id/images", authRest.RequireScope(authDomain.ScopeBooksWrite), bookHandler.UploadImage)
+		verified.DELETE("/books/:id/images/:imageId", authRest.RequireScope(authDomain.ScopeBooksWrite), bookHandler.DeleteImage)
+
+		// Tutaj trafią endpointy tworzące transakcje i wiadomości,
+		// każdy z RequireScope odpowiedniego zasobu (np. messages:write)
+	}
+
+	// Panel administracyjny (moderatorzy i administratorzy) tylko z sesji -
+	// klucz API ani token aplikacji OAuth nie dają uprawnień moderatora
+	admin := protected.Group("/admin")
+	admin.Use(authRest.RequireRole(authDomain.RoleModerator, authDomain.RoleAdmin), authRest.RequireSession())
+	{
+		admin.DELETE("/books/:id", moderationHandler.RemoveBook)
+		admin.DELETE("/reviews/:id", moderationHandler.RemoveReview)
+
+		// Zarządzanie rolami tylko dla administratorów
+		admin.PUT("/users/:id/role", authRest.RequireRole(authDomain.RoleAdmin), authHandler.ChangeRole)
+
+		admin.POST("/users/:id/impersonate", authRest.RequireRole(authDomain.RoleAdmin), impersonationHandler.Start)
+		admin.GET("/audit-log", authRest.RequireRole(authDomain.RoleAdmin), impersonationHandler.ListAudit)
+	}
+
 	// 6. Konfiguracja serwera HTTP
 	srv := &http.Server{
 		Addr:    ":" + cfg.Server.Port,
@@ -0,0 +1,132 @@
+// Program breached-passwords dopisuje hasła z wycieków do lokalnego katalogu
+// zakresów używanego przy sprawdzaniu nowych haseł (auth.password.breached.dir).
+// Działa offline: wejściem jest plik z hasłami albo ze skrótami SHA-1.
+//
+//	go run ./backend/cmd/breached-passwords -dir ./data/breached -input rockyou.txt
+//	go run ./backend/cmd/breached-passwords -dir ./data/breached -input pwned.txt -format sha1
+//
+// Format sha1 to linie "SKRÓT" lub "SKRÓT:LICZBA" (jak w zbiorach Pwned Passwords).
+// Katalog pobrany w całości narzędziem PwnedPasswordsDownloader ma już właściwy
+// układ i nie wymaga importu.
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/password"
+)
+
+func main() {
+	dir := flag.String("dir", "", "katalog zakresów (auth.password.breached.dir)")
+	input := flag.String("input", "-", "plik wejściowy; - oznacza standardowe wejście")
+	format := flag.String("format", "plain", "format wejścia: plain (hasło w linii) lub sha1")
+	flag.Parse()
+
+	if *dir == "" {
+		log.Fatal("podaj -dir")
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		log.Fatalf("Nie udało się utworzyć katalogu: %v", err)
+	}
+
+	var reader io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			log.Fatalf("Nie udało się otworzyć pliku: %v", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	ranges, skipped, err := readEntries(reader, *format)
+	if err != nil {
+		log.Fatalf("Błąd odczytu: %v", err)
+	}
+
+	added := 0
+	for prefix, entries := range ranges {
+		existing, err := password.ReadRange(*dir, prefix)
+		if err != nil {
+			log.Fatalf("Błąd odczytu zakresu %s: %v", prefix, err)
+		}
+
+		// Przy powtórnym imporcie zachowujemy większą liczbę wystąpień
+		for suffix, count := range entries {
+			if _, ok := existing[suffix]; !ok {
+				added++
+			}
+			if count > existing[suffix] {
+				existing[suffix] = count
+			}
+		}
+
+		if err := password.WriteRange(*dir, prefix, existing); err != nil {
+			log.Fatalf("Błąd zapisu zakresu %s: %v", prefix, err)
+		}
+	}
+
+	log.Printf("Zaktualizowano %d zakresów, nowych skrótów: %d, pominiętych linii: %d", len(ranges), added, skipped)
+}
+
+// readEntries grupuje wpisy według zakresów: prefiks -> reszta skrótu -> liczba wystąpień
+func readEntries(reader io.Reader, format string) (map[string]map[string]int, int, error) {
+	ranges := make(map[string]map[string]int)
+	skipped := 0
+
+	add := func(prefix, suffix string, count int) {
+		if ranges[prefix] == nil {
+			ranges[prefix] = make(map[string]int)
+		}
+		ranges[prefix][suffix] += count
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		switch format {
+		case "plain":
+			if line == "" {
+				skipped++
+				continue
+			}
+			prefix, suffix := password.RangeKey(line)
+			add(prefix, suffix, 1)
+
+		case "sha1":
+			hash, countText, hasCount := strings.Cut(strings.TrimSpace(line), ":")
+			if _, err := hex.DecodeString(hash); err != nil || len(hash) != 40 {
+				skipped++
+				continue
+			}
+			count := 1
+			if hasCount {
+				n, err := strconv.Atoi(countText)
+				if err != nil {
+					skipped++
+					continue
+				}
+				count = n
+			}
+			hash = strings.ToUpper(hash)
+			add(hash[:5], hash[5:], count)
+
+		default:
+			return nil, 0, fmt.Errorf("unsupported format %q", format)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	return ranges, skipped, nil
+}
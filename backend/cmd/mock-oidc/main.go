@@ -0,0 +1,239 @@
+// Program mock-oidc to minimalny dostawca OpenID Connect do testów lokalnych.
+// Każde żądanie autoryzacji jest od razu akceptowane, a użytkownik jest opisany
+// flagami (lub parametrem login_hint z adresem email).
+//
+//	go run ./backend/cmd/mock-oidc -email jan@example.com -name "Jan Kowalski"
+//
+// W konfiguracji API dodaj dostawcę z issuer http://localhost:9999,
+// clientID bookswap i clientSecret mock-secret.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mock-1"
+
+type pendingCode struct {
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	email         string
+	expiresAt     time.Time
+}
+
+type issuer struct {
+	url           string
+	clientID      string
+	clientSecret  string
+	name          string
+	email         string
+	emailVerified bool
+	key           *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]pendingCode
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9999", "adres nasłuchu")
+	issuerURL := flag.String("issuer", "http://localhost:9999", "identyfikator wystawcy (claim iss)")
+	clientID := flag.String("client-id", "bookswap", "identyfikator klienta")
+	clientSecret := flag.String("client-secret", "mock-secret", "sekret klienta (pusty = klient publiczny)")
+	email := flag.String("email", "mock.user@example.com", "email zalogowanego użytkownika")
+	name := flag.String("name", "Mock User", "imię i nazwisko użytkownika")
+	emailVerified := flag.Bool("email-verified", true, "wartość claimu email_verified")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("Nie udało się wygenerować klucza: %v", err)
+	}
+
+	iss := &issuer{
+		url:           *issuerURL,
+		clientID:      *clientID,
+		clientSecret:  *clientSecret,
+		name:          *name,
+		email:         *email,
+		emailVerified: *emailVerified,
+		key:           key,
+		codes:         make(map[string]pendingCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", iss.discovery)
+	mux.HandleFunc("/jwks", iss.jwks)
+	mux.HandleFunc("/authorize", iss.authorize)
+	mux.HandleFunc("/token", iss.token)
+
+	log.Printf("mock-oidc: %s (nasłuch %s)", iss.url, *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (i *issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                i.url,
+		"authorization_endpoint":                i.url + "/authorize",
+		"token_endpoint":                        i.url + "/token",
+		"jwks_uri":                              i.url + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (i *issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	public := i.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize od razu "loguje" użytkownika i przekierowuje z kodem
+func (i *issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != i.clientID || q.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id or response_type", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE S256 required", http.StatusBadRequest)
+		return
+	}
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Host == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email := i.email
+	if hint := q.Get("login_hint"); hint != "" {
+		email = hint
+	}
+
+	code := randomString()
+	i.mu.Lock()
+	i.codes[code] = pendingCode{
+		redirectURI:   q.Get("redirect_uri"),
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		email:         email,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	i.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+
+	log.Printf("mock-oidc: zalogowano %s, przekierowanie do %s", email, redirect.Host)
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (i *issuer) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+	if clientID != i.clientID || subtle.ConstantTimeCompare([]byte(secret), []byte(i.clientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	i.mu.Lock()
+	pending, found := i.codes[code]
+	delete(i.codes, code)
+	i.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !found || time.Now().After(pending.expiresAt) ||
+		pending.redirectURI != r.PostForm.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != pending.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	subject := sha256.Sum256([]byte(pending.email))
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            i.url,
+		"sub":            hex.EncodeToString(subject[:16]),
+		"aud":            i.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          pending.nonce,
+		"email":          pending.email,
+		"email_verified": i.emailVerified,
+		"name":           i.name,
+	})
+	token.Header["kid"] = keyID
+
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
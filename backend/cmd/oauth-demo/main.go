@@ -0,0 +1,250 @@
+// Program oauth-demo przechodzi cały przepływ authorization code + PKCE na
+// działającym lokalnie API: rejestruje publiczną aplikację, uruchamia własny
+// adres zwrotny, wyraża zgodę w imieniu użytkownika, wymienia kod na tokeny,
+// odświeża je i sprawdza przez introspekcję. Na końcu usuwa aplikację.
+//
+//	go run ./backend/cmd/oauth-demo -email jan@example.com -password Zielony-Las42
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type demo struct {
+	api     string
+	session string // token dostępowy z logowania - zastępuje przeglądarkę użytkownika
+	http    *http.Client
+}
+
+func main() {
+	api := flag.String("api", "http://localhost:8080/api/v1", "adres API")
+	email := flag.String("email", "", "email użytkownika")
+	password := flag.String("password", "", "hasło użytkownika")
+	scope := flag.String("scope", "profile:read books:read", "zakresy, o które prosi aplikacja")
+	flag.Parse()
+
+	if *email == "" || *password == "" {
+		log.Fatal("podaj -email i -password")
+	}
+
+	d := &demo{api: strings.TrimSuffix(*api, "/"), http: &http.Client{Timeout: 10 * time.Second}}
+	if err := d.run(*email, *password, *scope); err != nil {
+		log.Fatalf("oauth-demo: %v", err)
+	}
+}
+
+func (d *demo) run(email, password, scope string) error {
+	// 1. Logowanie użytkownika (w prawdziwej aplikacji robi to frontend BookSwap)
+	var login struct {
+		AccessToken string `json:"accessToken"`
+		MFARequired bool   `json:"mfaRequired"`
+	}
+	if err := d.call(http.MethodPost, "/auth/login", "", map[string]string{
+		"email": email, "password": password,
+	}, &login); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	if login.MFARequired {
+		return errors.New("konto ma włączone MFA - użyj konta bez drugiego składnika")
+	}
+	d.session = login.AccessToken
+	log.Println("zalogowano użytkownika")
+
+	// 2. Adres zwrotny aplikacji na losowym porcie pętli zwrotnej
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	callbacks := make(chan url.Values, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callbacks <- r.URL.Query()
+		fmt.Fprintln(w, "Możesz zamknąć to okno.")
+	})}
+	go server.Serve(listener)
+	defer server.Shutdown(context.Background())
+
+	// 3. Rejestracja publicznej aplikacji
+	var client struct {
+		ClientID string `json:"clientId"`
+	}
+	if err := d.call(http.MethodPost, "/oauth/clients", d.session, map[string]any{
+		"name":         "oauth-demo",
+		"redirectUris": []string{redirectURI},
+		"public":       true,
+	}, &client); err != nil {
+		return fmt.Errorf("register client: %w", err)
+	}
+	defer d.call(http.MethodDelete, "/oauth/clients/"+url.PathEscape(client.ClientID), d.session, nil, nil)
+	log.Printf("zarejestrowano aplikację %s", client.ClientID)
+
+	// 4. Żądanie autoryzacji z PKCE i zgoda użytkownika
+	verifier := randomString(48)
+	challenge := sha256.Sum256([]byte(verifier))
+	state := randomString(16)
+
+	var authorization struct {
+		RedirectURI string `json:"redirectUri"`
+	}
+	if err := d.call(http.MethodPost, "/oauth/authorize", d.session, map[string]any{
+		"response_type":         "code",
+		"client_id":             client.ClientID,
+		"redirect_uri":          redirectURI,
+		"scope":                 scope,
+		"state":                 state,
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(challenge[:]),
+		"code_challenge_method": "S256",
+		"approve":               true,
+	}, &authorization); err != nil {
+		return fmt.Errorf("authorize: %w", err)
+	}
+
+	// Przeglądarka podąża za przekierowaniem do aplikacji
+	resp, err := d.http.Get(authorization.RedirectURI)
+	if err != nil {
+		return fmt.Errorf("follow redirect: %w", err)
+	}
+	resp.Body.Close()
+
+	params := <-callbacks
+	if params.Get("state") != state {
+		return errors.New("state w odpowiedzi nie zgadza się z wysłanym")
+	}
+	if e := params.Get("error"); e != "" {
+		return fmt.Errorf("autoryzacja odrzucona: %s", e)
+	}
+	log.Println("otrzymano kod autoryzacyjny")
+
+	// 5. Wymiana kodu na tokeny
+	tokens, err := d.token(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {params.Get("code")},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+		"client_id":     {client.ClientID},
+	})
+	if err != nil {
+		return fmt.Errorf("exchange code: %w", err)
+	}
+	log.Printf("tokeny aplikacji: scope=%q expires_in=%d", tokens.Scope, tokens.ExpiresIn)
+
+	var me struct {
+		Name string `json:"name"`
+	}
+	if err := d.call(http.MethodGet, "/me", tokens.AccessToken, nil, &me); err != nil {
+		log.Printf("GET /me tokenem aplikacji: %v", err)
+	} else {
+		log.Printf("GET /me tokenem aplikacji: %s", me.Name)
+	}
+
+	// 6. Odświeżenie tokenów
+	tokens, err = d.token(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {tokens.RefreshToken},
+		"client_id":     {client.ClientID},
+	})
+	if err != nil {
+		return fmt.Errorf("refresh: %w", err)
+	}
+	log.Println("odświeżono tokeny")
+
+	// 7. Introspekcja nowego tokenu dostępowego
+	var introspection map[string]any
+	if err := d.call(http.MethodPost, "/auth/introspect", "", map[string]string{
+		"token": tokens.AccessToken,
+	}, &introspection); err != nil {
+		return fmt.Errorf("introspect: %w", err)
+	}
+	out, _ := json.MarshalIndent(introspection, "", "  ")
+	log.Printf("introspekcja:\n%s", out)
+	return nil
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope"`
+}
+
+func (d *demo) token(form url.Values) (*tokenResponse, error) {
+	resp, err := d.http.PostForm(d.api+"/oauth/token", form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tokens tokenResponse
+	if err := decode(resp, &tokens); err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+func (d *demo) call(method, path, bearer string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, d.api+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decode(resp, out)
+}
+
+func decode(resp *http.Response, out any) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// randomString zwraca losowy ciąg znaków dozwolonych w code_verifier
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)[:n]
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrInvalidAPIKey       = errors.New("invalid api key")
+	ErrInvalidAPIKeyExpiry = errors.New("api key expiry must be in the future")
+	ErrAPIKeyLimitReached  = errors.New("api key limit reached")
+)
+
+// APIKey reprezentuje osobisty klucz API użytkownika
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // początek klucza, pozwala go rozpoznać
+	KeyHash    string     `json:"-"`      // SHA-256 jawnego klucza
+	Scopes     []string   `json:"scopes"` // pusta lista = pełne uprawnienia użytkownika
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// IsActive informuje, czy klucz można jeszcze używać
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// APIKeyCreate reprezentuje dane do utworzenia klucza API
+type APIKeyCreate struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreatedAPIKey zwraca jawny klucz - jedyny raz, kiedy jest widoczny
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
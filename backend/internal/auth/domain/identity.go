@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOIDCProviderNotFound = errors.New("oidc provider not found")
+	ErrIdentityNotFound     = errors.New("user identity not found")
+	ErrInvalidOIDCState     = errors.New("invalid or expired oidc state")
+	ErrOIDCLoginFailed      = errors.New("oidc login failed")
+	ErrOIDCEmailNotVerified = errors.New("oidc provider did not return a verified email")
+	ErrIdentityConflict     = errors.New("email belongs to an account that cannot be linked automatically")
+)
+
+// UserIdentity wiąże konto u zewnętrznego dostawcy (provider + sub) z użytkownikiem
+type UserIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"-"`
+	UserID    uuid.UUID `json:"-"`
+	Email     string    `json:"email"` // adres z ID tokenu w chwili powiązania
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// OIDCState przechowuje dane rozpoczętego logowania u dostawcy do chwili powrotu użytkownika
+type OIDCState struct {
+	StateHash    string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// OIDCStart zawiera adres logowania u dostawcy. Frontend zapamiętuje state
+// i porównuje go z wartością, z którą dostawca przekieruje użytkownika.
+type OIDCStart struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+	State            string `json:"state"`
+}
+
+// OIDCCallbackRequest to parametry, z którymi dostawca przekierował użytkownika
+type OIDCCallbackRequest struct {
+	State     string `json:"state" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+	IP        string `json:"-"` // uzupełniany przez handler
+	UserAgent string `json:"-"` // uzupełniany przez handler
+}
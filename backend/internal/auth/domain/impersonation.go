@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCannotImpersonateSelf  = errors.New("cannot impersonate self")
+	ErrCannotImpersonateAdmin = errors.New("cannot impersonate administrator")
+)
+
+// Akcje zapisywane w dzienniku audytu
+const (
+	AuditImpersonationStart   = "impersonation.start"
+	AuditImpersonationRequest = "impersonation.request"
+)
+
+// ImpersonationRequest reprezentuje prośbę administratora o token użytkownika.
+// Token pozwala tylko na odczyt, chyba że administrator jawnie zażąda zapisu.
+type ImpersonationRequest struct {
+	Reason     string `json:"reason" binding:"required,min=10,max=500"` // np. numer zgłoszenia
+	AllowWrite bool   `json:"allowWrite"`
+	IP         string `json:"-"` // uzupełniany przez handler
+	UserAgent  string `json:"-"` // uzupełniany przez handler
+}
+
+// ImpersonationToken to krótkotrwały token dostępowy działający w imieniu
+// użytkownika. Nie ma tokenu odświeżającego - po wygaśnięciu trzeba wystawić nowy.
+type ImpersonationToken struct {
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	UserID      uuid.UUID `json:"userId"`
+	ReadOnly    bool      `json:"readOnly"`
+}
+
+// AuditEntry to wpis w dzienniku audytu działań administratorów w imieniu użytkowników
+type AuditEntry struct {
+	ID        uuid.UUID `json:"id"`
+	ActorID   uuid.UUID `json:"actorId"` // administrator
+	UserID    uuid.UUID `json:"userId"`  // użytkownik, w którego imieniu działał
+	Action    string    `json:"action"`
+	TokenID   string    `json:"tokenId"` // jti tokenu - łączy żądania z jego wystawieniem
+	ReadOnly  bool      `json:"readOnly"`
+	Reason    string    `json:"reason,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AuditFilter zawęża listę wpisów dziennika audytu
+type AuditFilter struct {
+	ActorID *uuid.UUID
+	UserID  *uuid.UUID
+	TokenID string
+	Limit   int
+}
+
+// IsImpersonated informuje, czy token wystawiono administratorowi działającemu w imieniu użytkownika
+func (c *AccessClaims) IsImpersonated() bool {
+	return c.ActorID != nil
+}
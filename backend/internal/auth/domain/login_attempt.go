@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrTooManyAttempts = errors.New("too many login attempts")
+
+// TooManyAttemptsError informuje, po jakim czasie można ponowić próbę logowania
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter)
+}
+
+func (e *TooManyAttemptsError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// LoginAttempts reprezentuje licznik nieudanych logowań dla jednego klucza (konta lub IP)
+type LoginAttempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// AttemptKeyPrefix zwraca rodzaj klucza licznika ("account:" lub "ip:").
+// Liczniki jednego rodzaju podlegają tej samej polityce, więc sprzątanie
+// wygasłych liczników musi się ograniczać do klucza tego samego rodzaju.
+func AttemptKeyPrefix(key string) string {
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[:i+1]
+	}
+	return key
+}
+
+// RetryAfter zwraca czas pozostały do końca blokady (zero, jeśli blokady nie ma)
+func (a LoginAttempts) RetryAfter(now time.Time) time.Duration {
+	if now.Before(a.LockedUntil) {
+		return a.LockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// LockoutPolicy opisuje, kiedy i na jak długo blokujemy logowanie.
+// Po przekroczeniu progu każda kolejna porażka podwaja czas blokady aż do MaxDelay.
+type LockoutPolicy struct {
+	Threshold int           // liczba porażek bez blokady
+	BaseDelay time.Duration // blokada po pierwszej porażce ponad próg
+	MaxDelay  time.Duration
+	Window    time.Duration // po takim czasie bez porażek licznik się zeruje
+}
+
+// RegisterFailure zwraca stan licznika po kolejnej nieudanej próbie w chwili now
+func (p LockoutPolicy) RegisterFailure(state LoginAttempts, now time.Time) LoginAttempts {
+	if now.Sub(state.LastFailureAt) > p.Window {
+		state.Failures = 0
+	}
+
+	state.Failures++
+	state.LastFailureAt = now
+
+	if over := state.Failures - p.Threshold; over > 0 {
+		delay := p.BaseDelay
+		for i := 1; i < over && delay < p.MaxDelay; i++ {
+			delay *= 2
+		}
+		if delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+		state.LockedUntil = now.Add(delay)
+	}
+
+	return state
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMFANotEnabled     = errors.New("mfa not enabled")
+	ErrMFAAlreadyEnabled = errors.New("mfa already enabled")
+	ErrMFANotEnrolled    = errors.New("mfa enrollment not started")
+	ErrInvalidMFACode    = errors.New("invalid mfa code")
+	ErrInvalidMFAToken   = errors.New("invalid mfa token")
+)
+
+// MFA reprezentuje konfigurację uwierzytelniania dwuskładnikowego (TOTP) użytkownika
+type MFA struct {
+	UserID          uuid.UUID
+	SecretEncrypted string     // sekret TOTP zaszyfrowany AES-GCM
+	ConfirmedAt     *time.Time // pusty, dopóki użytkownik nie potwierdzi kodu z aplikacji
+	LastUsedStep    int64      // ostatnio użyte okno czasowe - blokuje ponowne użycie kodu
+	CreatedAt       time.Time
+}
+
+// IsEnabled informuje, czy MFA jest aktywne (zapis został potwierdzony)
+func (m *MFA) IsEnabled() bool {
+	return m != nil && m.ConfirmedAt != nil
+}
+
+// RecoveryCode reprezentuje jednorazowy kod awaryjny
+type RecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// LoginResult reprezentuje wynik pierwszego kroku logowania. Jeśli konto ma
+// włączone MFA, zamiast tokenów zwracamy krótkotrwały token MFA do wymiany na kod.
+type LoginResult struct {
+	User        *User
+	Tokens      *TokenPair
+	MFARequired bool
+	MFAToken    string
+}
+
+// MFAEnrollment reprezentuje dane potrzebne do dodania konta w aplikacji uwierzytelniającej
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"` // do wyświetlenia jako kod QR
+}
+
+// MFACodeRequest reprezentuje kod z aplikacji uwierzytelniającej lub kod awaryjny
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAVerifyRequest reprezentuje drugi krok logowania
+type MFAVerifyRequest struct {
+	MFAToken  string `json:"mfaToken" binding:"required"`
+	Code      string `json:"code" binding:"required"` // kod TOTP lub kod awaryjny
+	IP        string `json:"-"`                       // uzupełniany przez handler
+	UserAgent string `json:"-"`                       // uzupełniany przez handler
+}
+
+// RecoveryCodesResponse reprezentuje nowo wygenerowane kody awaryjne (pokazywane tylko raz)
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
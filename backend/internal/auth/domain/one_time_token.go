@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOneTimeTokenInvalid = errors.New("one-time token invalid")
+	ErrInvalidResetToken   = errors.New("invalid password reset token")
+
+	ErrInvalidVerificationToken = errors.New("invalid email verification token")
+	ErrInvalidEmailChangeToken  = errors.New("invalid email change token")
+)
+
+// TokenPurpose określa, do czego służy jednorazowy token
+type TokenPurpose string
+
+const (
+	PurposePasswordReset     TokenPurpose = "password_reset"
+	PurposeEmailVerification TokenPurpose = "email_verification"
+	PurposeEmailChange       TokenPurpose = "email_change"
+)
+
+// OneTimeToken reprezentuje jednorazowy token wysyłany użytkownikowi mailem
+type OneTimeToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   TokenPurpose
+	TokenHash string
+	Payload   string // dane zależne od przeznaczenia, np. nowy adres email
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// ForgotPasswordRequest reprezentuje prośbę o reset hasła
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest reprezentuje dane do ustawienia nowego hasła
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"` // sprawdzane polityką haseł
+}
+
+// VerifyEmailRequest reprezentuje dane do potwierdzenia adresu email
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ChangeEmailRequest reprezentuje prośbę o zmianę adresu email
+type ChangeEmailRequest struct {
+	NewEmail string `json:"newEmail" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	IP       string `json:"-"` // uzupełniany przez handler
+}
+
+// ConfirmEmailChangeRequest reprezentuje dane do potwierdzenia nowego adresu
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
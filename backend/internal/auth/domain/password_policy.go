@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrWeakPassword = errors.New("password violates policy")
+
+// Reguły polityki haseł. Wartości są jednocześnie kodami błędów API.
+const (
+	PasswordTooShort     = "password-too-short"
+	PasswordTooLong      = "password-too-long"
+	PasswordTooWeak      = "password-too-weak"
+	PasswordPersonalInfo = "password-contains-personal-info"
+	PasswordBreached     = "password-breached"
+)
+
+// Fragmenty imienia i adresu email krótsze niż to nie są sprawdzane w haśle
+const minPersonalFragment = 3
+
+// PasswordPolicyError informuje, którą regułę polityki haseł naruszono
+type PasswordPolicyError struct {
+	Rule  string
+	Limit int // np. minimalna długość albo liczba grup znaków
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return fmt.Sprintf("password violates policy: %s", e.Rule)
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+// PasswordPolicy opisuje wymagania wobec nowych haseł. Istniejących haseł nie
+// sprawdzamy - obowiązuje przy rejestracji, zmianie i resecie hasła.
+type PasswordPolicy struct {
+	MinLength          int  // w znakach
+	MaxLength          int  // w znakach; 0 oznacza brak limitu
+	MinCharClasses     int  // z grup: małe litery, wielkie litery, cyfry, pozostałe znaki
+	RejectPersonalInfo bool // hasło nie może zawierać imienia ani adresu email
+}
+
+// Validate sprawdza hasło. personal to dane użytkownika (imię, email), których
+// hasło nie może zawierać.
+func (p PasswordPolicy) Validate(password string, personal ...string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return &PasswordPolicyError{Rule: PasswordTooShort, Limit: p.MinLength}
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return &PasswordPolicyError{Rule: PasswordTooLong, Limit: p.MaxLength}
+	}
+
+	if charClasses(password) < p.MinCharClasses {
+		return &PasswordPolicyError{Rule: PasswordTooWeak, Limit: p.MinCharClasses}
+	}
+
+	if p.RejectPersonalInfo {
+		lower := strings.ToLower(password)
+		for _, value := range personal {
+			for _, fragment := range personalFragments(value) {
+				if strings.Contains(lower, fragment) {
+					return &PasswordPolicyError{Rule: PasswordPersonalInfo}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	count := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// personalFragments dzieli imię lub adres email na słowa (np. "jan.kowalski@example.com"
+// daje jan i kowalski), pomijając domenę i zbyt krótkie fragmenty
+func personalFragments(value string) []string {
+	value = strings.ToLower(value)
+	if at := strings.LastIndex(value, "@"); at >= 0 {
+		value = value[:at]
+	}
+
+	var fragments []string
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(word) >= minPersonalFragment {
+			fragments = append(fragments, word)
+		}
+	}
+	return fragments
+}
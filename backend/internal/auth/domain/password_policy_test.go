@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, MaxLength: 128, MinCharClasses: 2, RejectPersonalInfo: true}
+	personal := []string{"Jan Kowalski", "jan.kowalski@example.com"}
+
+	tests := []struct {
+		name      string
+		policy    PasswordPolicy
+		password  string
+		wantRule  string // pusta, gdy hasło spełnia politykę
+		wantLimit int
+	}{
+		{"valid", policy, "zielony-Las42", "", 0},
+		{"too short", policy, "Ab1-Ab1-A", PasswordTooShort, 10},
+		{"exactly minimal length", policy, "abcdefghi1", "", 0},
+		{"length counted in characters", policy, "żółćżółć1ź", "", 0},
+		{"too long", policy, strings.Repeat("ab1", 43), PasswordTooLong, 128},
+		{"exactly maximal length", policy, strings.Repeat("a", 127) + "1", "", 0},
+		{"no maximal length", PasswordPolicy{MinLength: 10}, strings.Repeat("a", 500), "", 0},
+		{"single character class", policy, "abcdefghijkl", PasswordTooWeak, 2},
+		{"spaces are a class", policy, "correct horse battery", "", 0},
+		{"contains name", policy, "kowalski-2024", PasswordPersonalInfo, 0},
+		{"contains name in other case", policy, "MojJAN1234", PasswordPersonalInfo, 0},
+		{"email domain is allowed", policy, "example-2024!", "", 0},
+		{"personal info allowed when disabled", PasswordPolicy{MinLength: 10, MinCharClasses: 2}, "kowalski-2024", "", 0},
+	}
+
+	for _, tt := range tests {
+		err := tt.policy.Validate(tt.password, personal...)
+		if tt.wantRule == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+
+		var policyErr *PasswordPolicyError
+		if !errors.As(err, &policyErr) || policyErr.Rule != tt.wantRule || policyErr.Limit != tt.wantLimit {
+			t.Errorf("%s: err = %#v, want rule %s with limit %d", tt.name, err, tt.wantRule, tt.wantLimit)
+			continue
+		}
+		if !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("%s: error does not match ErrWeakPassword", tt.name)
+		}
+	}
+}
+
+func TestCharClasses(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"ABC", 1},
+		{"123", 1},
+		{"!@#", 1},
+		{"abcABC", 2},
+		{"abc123", 2},
+		{"abc ", 2},
+		{"aB3$", 4},
+		{"zażółć", 1},
+		{"ZAŻÓŁĆ9", 2},
+	}
+	for _, tt := range tests {
+		if got := charClasses(tt.password); got != tt.want {
+			t.Errorf("charClasses(%q) = %d, want %d", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestPersonalFragments(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"Jan Kowalski", []string{"jan", "kowalski"}},
+		{"jan.kowalski@example.com", []string{"jan", "kowalski"}},
+		{"Anna-Maria Żółkiewska", []string{"anna", "maria", "żółkiewska"}},
+		{"jo@example.com", nil},
+		{"Al B", nil},
+		{"user+books2024@mail.example.com", []string{"user", "books2024"}},
+		{"a@b@example.com", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := personalFragments(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("personalFragments(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
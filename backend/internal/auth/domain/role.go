@@ -0,0 +1,31 @@
+package domain
+
+import "errors"
+
+var (
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrCannotChangeOwnRole = errors.New("cannot change own role")
+)
+
+// Role określa uprawnienia użytkownika w systemie
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// IsValid informuje, czy rola jest jedną ze znanych ról
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// RoleUpdate reprezentuje dane do zmiany roli użytkownika
+type RoleUpdate struct {
+	Role Role `json:"role" binding:"required,oneof=user moderator admin"`
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidScope = errors.New("invalid scope")
+
+// Zakresy uprawnień tokenów i kluczy API (format zasób:operacja)
+const (
+	ScopeProfileRead       = "profile:read"
+	ScopeProfileWrite      = "profile:write"
+	ScopeBooksRead         = "books:read"
+	ScopeBooksWrite        = "books:write"
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+	ScopeMessagesRead      = "messages:read"
+	ScopeMessagesWrite     = "messages:write"
+	ScopeReviewsWrite      = "reviews:write"
+)
+
+// KnownScopes to wszystkie obsługiwane zakresy. Sesja z logowania dostaje je wszystkie.
+var KnownScopes = []string{
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeBooksRead,
+	ScopeBooksWrite,
+	ScopeTransactionsRead,
+	ScopeTransactionsWrite,
+	ScopeMessagesRead,
+	ScopeMessagesWrite,
+	ScopeReviewsWrite,
+}
+
+// IsKnownScope informuje, czy zakres jest obsługiwany
+func IsKnownScope(scope string) bool {
+	for _, known := range KnownScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// AllScopes zwraca kopię listy wszystkich zakresów
+func AllScopes() []string {
+	return append([]string(nil), KnownScopes...)
+}
+
+// ReadScopes zwraca zakresy pozwalające wyłącznie na odczyt
+func ReadScopes() []string {
+	var scopes []string
+	for _, scope := range KnownScopes {
+		if strings.HasSuffix(scope, ":read") {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// ParseScope dzieli claim "scope" (zakresy rozdzielone spacjami, RFC 6749)
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// FormatScope łączy zakresy w wartość claimu "scope"
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// HasScope informuje, czy token obejmuje podany zakres
+func (c *AccessClaims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Introspection opisuje stan tokenu (RFC 7662). Dla nieaktywnego tokenu
+// zwracamy wyłącznie active=false.
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"` // aplikacja OAuth, której wystawiono token
+	Actor     *Actor `json:"act,omitempty"`       // administrator działający w imieniu użytkownika (RFC 8693)
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"` // access_token, api_key
+	TokenID   string `json:"jti,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// Actor identyfikuje podmiot działający w imieniu właściciela tokenu (RFC 8693 4.1)
+type Actor struct {
+	Subject string `json:"sub"`
+}
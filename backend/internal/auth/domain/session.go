@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session to jedno logowanie na urządzeniu. Odpowiada rodzinie tokenów
+// odświeżających - identyfikator sesji to FamilyID.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"-"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	Device     string    `json:"device"` // opis urządzenia wyliczony z User-Agent
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"` // ostatnie żądanie lub odświeżenie tokenów
+}
+
+// SessionClient opisuje urządzenie, z którego wysłano żądanie (uzupełniany przez handler)
+type SessionClient struct {
+	IP        string
+	UserAgent string
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrInvalidToken        = errors.New("invalid token")
+	ErrTokenRevoked        = errors.New("token revoked")
+)
+
+// AccessClaims reprezentuje zweryfikowaną zawartość tokenu dostępowego
+type AccessClaims struct {
+	TokenID       string // jti
+	UserID        uuid.UUID
+	Name          string
+	Email         string
+	Role          Role
+	EmailVerified bool // stan z chwili wystawienia tokenu
+	IssuedAt      time.Time
+	ExpiresAt     time.Time
+	APIKeyID      *uuid.UUID // ustawiony, gdy żądanie uwierzytelniono kluczem API
+	ClientID      string     // ustawiony dla tokenów wystawionych aplikacji OAuth
+	SessionID     *uuid.UUID // sesja z logowania (claim sid)
+	Scopes        []string   // efektywne zakresy uprawnień
+	ActorID       *uuid.UUID // administrator działający w imieniu użytkownika (claim act)
+	ReadOnly      bool       // token podszywania się pozwala tylko na odczyt
+}
+
+// RefreshToken reprezentuje zapisany w bazie token odświeżający
+type RefreshToken struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	FamilyID   uuid.UUID // wspólny dla wszystkich rotacji jednego logowania
+	TokenHash  string    // SHA-256 jawnego tokenu
+	ClientID   string    // aplikacja OAuth; pusty dla sesji z logowania
+	Scopes     []string  // zakresy nadane aplikacji; nil dla sesji (pełne uprawnienia)
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	ReplacedBy *uuid.UUID
+	CreatedAt  time.Time
+}
+
+// IsExpired informuje, czy token stracił ważność w chwili now
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// TokenPair reprezentuje parę tokenów zwracaną po zalogowaniu lub odświeżeniu
+type TokenPair struct {
+	AccessToken      string    `json:"accessToken"`
+	AccessExpiresAt  time.Time `json:"accessExpiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+// GrantedTokens to para tokenów wystawiona aplikacji wraz z nadanymi zakresami
+type GrantedTokens struct {
+	*TokenPair
+	FamilyID uuid.UUID
+	Scopes   []string
+}
+
+// RefreshRequest reprezentuje dane do odświeżenia tokenu
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+	IP           string `json:"-"` // uzupełniany przez handler
+	UserAgent    string `json:"-"` // uzupełniany przez handler
+}
+
+// LogoutRequest reprezentuje opcjonalne dane do wylogowania
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -11,34 +11,60 @@ var (
 	ErrEmailExists        = errors.New("email already exists")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	ErrInvalidCurrentPassword = errors.New("invalid current password")
+	ErrPasswordNotSet         = errors.New("account has no password")
 )
 
 // User reprezentuje użytkownika aplikacji
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	Name         string    `json:"name"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // nigdy nie wysyłamy hasza w JSONie
-	Location     string    `json:"location,omitempty"`
-	Bio          string    `json:"bio,omitempty"`
-	AvatarURL    string    `json:"avatarUrl,omitempty"`
-	Rating       float64   `json:"rating"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID              uuid.UUID  `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"` // nigdy nie wysyłamy hasza w JSONie
+	Location        string     `json:"location,omitempty"`
+	Bio             string     `json:"bio,omitempty"`
+	AvatarURL       string     `json:"avatarUrl,omitempty"`
+	Rating          float64    `json:"rating"`
+	Role            Role       `json:"role"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"` // pusty do potwierdzenia adresu
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// IsEmailVerified informuje, czy użytkownik potwierdził adres email
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// HasPassword informuje, czy konto ma hasło. Konta założone przez zewnętrznego
+// dostawcę tożsamości nie mają go, dopóki użytkownik nie ustawi go resetem hasła.
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
 }
 
 // UserRegister reprezentuje dane do rejestracji
 type UserRegister struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 	Location string `json:"location"`
 }
 
 // UserLogin reprezentuje dane do logowania
 type UserLogin struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required"`
+	IP        string `json:"-"` // uzupełniany przez handler
+	UserAgent string `json:"-"` // uzupełniany przez handler
+}
+
+// ChangePasswordRequest reprezentuje zmianę hasła przez zalogowanego użytkownika
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"` // sprawdzane polityką haseł
+	IP              string `json:"-"`                              // uzupełniany przez handler
+	UserAgent       string `json:"-"`                              // uzupełniany przez handler
 }
 
 // UserUpdate reprezentuje dane do aktualizacji profilu
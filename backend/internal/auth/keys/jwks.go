@@ -0,0 +1,49 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK reprezentuje klucz publiczny w formacie RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"` // OKP
+	X         string `json:"x,omitempty"`   // OKP
+	N         string `json:"n,omitempty"`   // RSA
+	E         string `json:"e,omitempty"`   // RSA
+}
+
+// JWKSet reprezentuje odpowiedź endpointu /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ToJWK zwraca publiczną część klucza w formacie JWK
+func (k *Key) ToJWK() (JWK, bool) {
+	jwk := JWK{
+		Use:       "sig",
+		KeyID:     k.ID,
+		Algorithm: k.Algorithm,
+	}
+
+	switch public := k.Public.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
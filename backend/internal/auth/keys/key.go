@@ -0,0 +1,148 @@
+// Package keys zarządza kluczami asymetrycznymi do podpisywania i weryfikacji tokenów JWT.
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Obsługiwane algorytmy podpisu
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+)
+
+const rsaKeyBits = 3072
+
+// Nagłówek PEM z datą utworzenia klucza, od której liczy się rotacja i retencja
+const createdHeader = "Created"
+
+var ErrUnsupportedKey = errors.New("unsupported key type")
+
+// Key reprezentuje klucz podpisu. Klucze służące wyłącznie do weryfikacji nie mają części prywatnej.
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+	CreatedAt time.Time
+}
+
+// CanSign informuje, czy kluczem można podpisywać tokeny
+func (k *Key) CanSign() bool {
+	return k.Private != nil
+}
+
+// SigningMethod zwraca metodę podpisu z biblioteki jwt odpowiadającą algorytmowi klucza
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+// Generate tworzy nowy klucz prywatny dla podanego algorytmu
+func Generate(id, algorithm string, now time.Time) (*Key, error) {
+	var signer crypto.Signer
+	switch algorithm {
+	case AlgEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		signer = private
+	case AlgRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		signer = private
+	default:
+		return nil, fmt.Errorf("%w: algorithm %q", ErrUnsupportedKey, algorithm)
+	}
+
+	return &Key{
+		ID:        id,
+		Algorithm: algorithm,
+		Private:   signer,
+		Public:    signer.Public(),
+		CreatedAt: now,
+	}, nil
+}
+
+// ParsePEM odczytuje klucz prywatny (PKCS#8) lub publiczny (PKIX) w formacie PEM.
+// Algorytm wynika z typu klucza. Datę utworzenia bierze z nagłówka Created
+// zapisanego przez EncodePrivatePEM; createdAt obowiązuje dla plików bez niego
+// (np. kluczy wygenerowanych przez openssl).
+func ParsePEM(id string, data []byte, createdAt time.Time) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", id)
+	}
+
+	if created, ok := block.Headers[createdHeader]; ok {
+		parsed, err := time.Parse(time.RFC3339, created)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: invalid %s header: %w", id, createdHeader, err)
+		}
+		createdAt = parsed.UTC()
+	}
+
+	key := &Key{ID: id, CreatedAt: createdAt}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("key %s: %w", id, ErrUnsupportedKey)
+		}
+		key.Private = signer
+		key.Public = signer.Public()
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		key.Public = parsed
+	default:
+		return nil, fmt.Errorf("key %s: unexpected PEM block %q", id, block.Type)
+	}
+
+	switch key.Public.(type) {
+	case ed25519.PublicKey:
+		key.Algorithm = AlgEdDSA
+	case *rsa.PublicKey:
+		key.Algorithm = AlgRS256
+	default:
+		return nil, fmt.Errorf("key %s: %w", id, ErrUnsupportedKey)
+	}
+
+	return key, nil
+}
+
+// EncodePrivatePEM koduje klucz prywatny jako PEM (PKCS#8) z datą utworzenia
+// w nagłówku - czas modyfikacji pliku zmienia się przy kopiowaniu i backupie
+func EncodePrivatePEM(key *Key) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{createdHeader: key.CreatedAt.UTC().Format(time.RFC3339)},
+		Bytes:   der,
+	}), nil
+}
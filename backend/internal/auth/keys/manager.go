@@ -0,0 +1,307 @@
+package keys
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoSigningKey = errors.New("no signing key available")
+
+const keyFileExt = ".pem"
+
+// StaticKey to klucz wskazany wprost w konfiguracji. Wystarczy sam klucz publiczny,
+// jeśli służy tylko do weryfikacji (np. klucz innej instancji w trakcie migracji).
+type StaticKey struct {
+	ID             string
+	PrivateKeyFile string
+	PublicKeyFile  string
+}
+
+// Options opisuje źródła kluczy i harmonogram rotacji
+type Options struct {
+	Algorithm        string        // algorytm nowych kluczy: EdDSA lub RS256
+	Dir              string        // katalog kluczy zarządzanych (rotowanych) przez Manager
+	StaticKeys       []StaticKey   // dodatkowe klucze z konfiguracji
+	RotationInterval time.Duration // co ile generować nowy klucz podpisu (0 - bez rotacji)
+	Retention        time.Duration // jak długo wycofany klucz nadal weryfikuje tokeny
+	ReloadInterval   time.Duration // co ile odczytywać katalog (klucze z innych replik)
+}
+
+// Manager przechowuje aktywny klucz podpisu oraz wszystkie klucze weryfikacyjne.
+// Kluczem podpisu jest zawsze najnowszy klucz z częścią prywatną.
+type Manager struct {
+	opts Options
+	now  func() time.Time
+
+	mu      sync.RWMutex
+	static  []*Key
+	managed []*Key
+}
+
+func NewManager(opts Options) (*Manager, error) {
+	m := &Manager{opts: opts, now: time.Now}
+
+	for _, sk := range opts.StaticKeys {
+		key, err := loadStaticKey(sk)
+		if err != nil {
+			return nil, err
+		}
+		m.static = append(m.static, key)
+	}
+
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+
+	active, err := m.SigningKey()
+	needsKey := err != nil || active.Algorithm != opts.Algorithm
+	if needsKey && opts.Dir != "" {
+		if _, err := m.Rotate(); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// SigningKey zwraca klucz, którym podpisujemy nowe tokeny
+func (m *Manager) SigningKey() (*Key, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var active *Key
+	for _, key := range m.allLocked() {
+		if key.CanSign() && (active == nil || key.CreatedAt.After(active.CreatedAt)) {
+			active = key
+		}
+	}
+
+	if active == nil {
+		return nil, ErrNoSigningKey
+	}
+	return active, nil
+}
+
+// VerificationKey zwraca klucz o podanym identyfikatorze (nagłówek kid)
+func (m *Manager) VerificationKey(kid string) (*Key, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.allLocked() {
+		if key.ID == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// JWKS zwraca publiczne części wszystkich kluczy weryfikacyjnych
+func (m *Manager) JWKS() JWKSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range m.allLocked() {
+		if jwk, ok := key.ToJWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// Rotate generuje nowy klucz podpisu i zapisuje go w katalogu kluczy
+func (m *Manager) Rotate() (*Key, error) {
+	if m.opts.Dir == "" {
+		return nil, errors.New("key rotation requires a key directory")
+	}
+
+	now := m.now().UTC()
+	key, err := Generate(newKeyID(now), m.opts.Algorithm, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	data, err := EncodePrivatePEM(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	if err := os.MkdirAll(m.opts.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	// Zapis przez plik tymczasowy, żeby inne repliki nie odczytały połowy klucza
+	path := filepath.Join(m.opts.Dir, key.ID+keyFileExt)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write key: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("failed to write key: %w", err)
+	}
+
+	m.mu.Lock()
+	m.managed = append(m.managed, key)
+	m.mu.Unlock()
+
+	return key, nil
+}
+
+// Run cyklicznie odczytuje katalog kluczy, rotuje klucz podpisu i usuwa wycofane klucze.
+// Blokuje do anulowania kontekstu.
+func (m *Manager) Run(ctx context.Context) {
+	if m.opts.Dir == "" || m.opts.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.opts.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.maintain(m.now().UTC()); err != nil {
+				log.Printf("Błąd obsługi kluczy JWT: %v", err)
+			}
+		}
+	}
+}
+
+func (m *Manager) maintain(now time.Time) error {
+	if err := m.reload(); err != nil {
+		return err
+	}
+
+	if m.opts.RotationInterval > 0 {
+		active, err := m.SigningKey()
+		if err != nil || now.Sub(active.CreatedAt) >= m.opts.RotationInterval {
+			if _, err := m.Rotate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return m.prune(now)
+}
+
+// prune usuwa klucze zarządzane, które zostały zastąpione dawniej niż Retention temu
+func (m *Manager) prune(now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sort.Slice(m.managed, func(i, j int) bool {
+		return m.managed[i].CreatedAt.Before(m.managed[j].CreatedAt)
+	})
+
+	kept := m.managed[:0]
+	var errs []error
+	for i, key := range m.managed {
+		// Klucz przestał podpisywać w chwili utworzenia następnego
+		if i+1 < len(m.managed) && now.Sub(m.managed[i+1].CreatedAt) > m.opts.Retention {
+			path := filepath.Join(m.opts.Dir, key.ID+keyFileExt)
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		kept = append(kept, key)
+	}
+	m.managed = kept
+
+	return errors.Join(errs...)
+}
+
+// reload odczytuje klucze z katalogu, w tym wygenerowane przez inne repliki
+func (m *Manager) reload() error {
+	if m.opts.Dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(m.opts.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read key directory: %w", err)
+	}
+
+	var managed []*Key
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), keyFileExt) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(m.opts.Dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read key: %w", err)
+		}
+
+		// Czas modyfikacji liczy się tylko dla plików bez nagłówka Created
+		key, err := ParsePEM(strings.TrimSuffix(entry.Name(), keyFileExt), data, info.ModTime().UTC())
+		if err != nil {
+			return err
+		}
+		managed = append(managed, key)
+	}
+
+	m.mu.Lock()
+	m.managed = managed
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Manager) allLocked() []*Key {
+	all := make([]*Key, 0, len(m.static)+len(m.managed))
+	all = append(all, m.static...)
+	return append(all, m.managed...)
+}
+
+func loadStaticKey(sk StaticKey) (*Key, error) {
+	path := sk.PrivateKeyFile
+	if path == "" {
+		path = sk.PublicKeyFile
+	}
+	if sk.ID == "" || path == "" {
+		return nil, errors.New("static key requires an id and a key file")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", sk.ID, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", sk.ID, err)
+	}
+
+	return ParsePEM(sk.ID, data, info.ModTime().UTC())
+}
+
+// newKeyID tworzy identyfikator klucza zawierający datę utworzenia
+func newKeyID(now time.Time) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return now.Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,236 @@
+package keys
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// clock to zegar testowy przesuwany ręcznie
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestManager(t *testing.T, dir string, c *clock) *Manager {
+	t.Helper()
+	m := &Manager{
+		opts: Options{Algorithm: AlgEdDSA, Dir: dir, Retention: 24 * time.Hour, RotationInterval: 7 * 24 * time.Hour},
+		now:  c.Now,
+	}
+	if err := m.reload(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func rotate(t *testing.T, m *Manager) *Key {
+	t.Helper()
+	key, err := m.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func inJWKS(m *Manager, kid string) bool {
+	for _, jwk := range m.JWKS().Keys {
+		if jwk.KeyID == kid {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSigningKeyIsNewestPrivateKey(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	m := newTestManager(t, dir, c)
+
+	if _, err := m.SigningKey(); err != ErrNoSigningKey {
+		t.Fatalf("empty manager: err = %v, want ErrNoSigningKey", err)
+	}
+
+	rotate(t, m)
+	c.advance(time.Hour)
+	newest := rotate(t, m)
+
+	// Nowszy klucz bez części prywatnej (np. klucz innej instancji) nie podpisuje
+	m.static = append(m.static, &Key{ID: "other", Algorithm: AlgEdDSA, Public: newest.Public, CreatedAt: c.now.Add(time.Hour)})
+
+	active, err := m.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active.ID != newest.ID {
+		t.Errorf("signing key = %s, want newest %s", active.ID, newest.ID)
+	}
+	if _, ok := m.VerificationKey("other"); !ok {
+		t.Error("public-only key is not available for verification")
+	}
+}
+
+func TestRotateWritesKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	m := newTestManager(t, dir, c)
+
+	key := rotate(t, m)
+	if !key.CreatedAt.Equal(c.now) {
+		t.Errorf("CreatedAt = %v, want %v", key.CreatedAt, c.now)
+	}
+	if !inJWKS(m, key.ID) {
+		t.Errorf("kid %s missing from JWKS", key.ID)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != key.ID+keyFileExt {
+		t.Fatalf("key directory = %v, want only %s%s", entries, key.ID, keyFileExt)
+	}
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Data utworzenia pochodzi z pliku, a nie z czasu jego modyfikacji
+	path := filepath.Join(dir, key.ID+keyFileExt)
+	copied := c.now.Add(30 * 24 * time.Hour)
+	if err := os.Chtimes(path, copied, copied); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := newTestManager(t, dir, c)
+	got, ok := reloaded.VerificationKey(key.ID)
+	if !ok {
+		t.Fatalf("key %s not loaded from disk", key.ID)
+	}
+	if !got.CreatedAt.Equal(c.now) || !got.CanSign() {
+		t.Errorf("reloaded key: CreatedAt %v, can sign %v; want %v and a private key", got.CreatedAt, got.CanSign(), c.now)
+	}
+}
+
+func TestParsePEMWithoutCreatedHeader(t *testing.T) {
+	key, err := Generate("legacy", AlgEdDSA, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	fallback := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	parsed, err := ParsePEM("legacy", data, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.CreatedAt.Equal(fallback) {
+		t.Errorf("CreatedAt = %v, want fallback %v", parsed.CreatedAt, fallback)
+	}
+}
+
+func TestPruneKeepsRetiredKeyForRetention(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	m := newTestManager(t, dir, c)
+
+	retired := rotate(t, m)
+	c.advance(7 * 24 * time.Hour)
+	active := rotate(t, m)
+
+	// Wycofany klucz weryfikuje tokeny jeszcze przez Retention od utworzenia następnego
+	if err := m.prune(active.CreatedAt.Add(m.opts.Retention)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.VerificationKey(retired.ID); !ok {
+		t.Fatal("retired key pruned before retention elapsed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, retired.ID+keyFileExt)); err != nil {
+		t.Fatalf("retired key file removed before retention elapsed: %v", err)
+	}
+
+	if err := m.prune(active.CreatedAt.Add(m.opts.Retention + time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.VerificationKey(retired.ID); ok || inJWKS(m, retired.ID) {
+		t.Error("retired key still published after retention")
+	}
+	if _, err := os.Stat(filepath.Join(dir, retired.ID+keyFileExt)); !os.IsNotExist(err) {
+		t.Errorf("retired key file still present: %v", err)
+	}
+
+	// Aktywnego klucza nie usuwamy niezależnie od wieku
+	if err := m.prune(active.CreatedAt.Add(365 * 24 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := m.SigningKey(); err != nil || key.ID != active.ID {
+		t.Errorf("signing key = %v, %v; want %s", key, err, active.ID)
+	}
+}
+
+func TestMaintainRotatesAfterInterval(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	m := newTestManager(t, dir, c)
+	first := rotate(t, m)
+
+	c.advance(m.opts.RotationInterval - time.Second)
+	if err := m.maintain(c.now); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := m.SigningKey(); key.ID != first.ID {
+		t.Fatalf("rotated before interval: signing key %s", key.ID)
+	}
+
+	c.advance(time.Second)
+	if err := m.maintain(c.now); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := m.SigningKey()
+	if key.ID == first.ID || !key.CreatedAt.Equal(c.now) {
+		t.Errorf("signing key %s created %v, want a new key created %v", key.ID, key.CreatedAt, c.now)
+	}
+	if _, ok := m.VerificationKey(first.ID); !ok {
+		t.Error("previous key dropped right after rotation")
+	}
+}
+
+func TestReloadPicksUpKeysFromOtherInstance(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	a := newTestManager(t, dir, c)
+	b := newTestManager(t, dir, c)
+
+	rotate(t, a)
+	c.advance(time.Hour)
+	key := rotate(t, a)
+
+	if _, ok := b.VerificationKey(key.ID); ok {
+		t.Fatal("key visible before reload")
+	}
+	if err := b.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.VerificationKey(key.ID); !ok || !inJWKS(b, key.ID) {
+		t.Fatalf("key %s not picked up by reload", key.ID)
+	}
+	if active, err := b.SigningKey(); err != nil || active.ID != key.ID {
+		t.Errorf("signing key = %v, %v; want %s written by the other instance", active, err, key.ID)
+	}
+}
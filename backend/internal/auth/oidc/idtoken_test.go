@@ -0,0 +1,220 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "bookswap"
+	testKeyID    = "test-1"
+	testNonce    = "n-0S6_WzA2Mj"
+)
+
+// testIssuer to dostawca OIDC w pamięci: discovery, JWKS i endpoint tokenów
+type testIssuer struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	idToken string // zwracany przez endpoint tokenów
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{
+			Issuer:                iss.server.URL,
+			AuthorizationEndpoint: iss.server.URL + "/authorize",
+			TokenEndpoint:         iss.server.URL + "/token",
+			JWKSURI:               iss.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []jwk{{
+			KeyType: "RSA",
+			Use:     "sig",
+			KeyID:   testKeyID,
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != testClientID || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": iss.idToken})
+	})
+	iss.server = httptest.NewServer(mux)
+	t.Cleanup(iss.server.Close)
+	return iss
+}
+
+func (iss *testIssuer) provider() *Provider {
+	return NewProvider(Config{Name: "test", Issuer: iss.server.URL, ClientID: testClientID, ClientSecret: "secret"}, iss.server.Client())
+}
+
+// claims zwraca poprawny zestaw claimów, który przypadki testowe psują
+func (iss *testIssuer) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            iss.server.URL,
+		"sub":            "248289761001",
+		"aud":            testClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          testNonce,
+		"email":          "jan@example.com",
+		"email_verified": true,
+		"name":           "Jan Kowalski",
+	}
+}
+
+func (iss *testIssuer) sign(t *testing.T, claims jwt.MapClaims, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(iss.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	iss := newTestIssuer(t)
+	p := iss.provider()
+
+	tests := []struct {
+		name    string
+		modify  func(jwt.MapClaims)
+		kid     string
+		wantErr bool
+	}{
+		{"valid", func(c jwt.MapClaims) {}, testKeyID, false},
+		{"no kid with single key", func(c jwt.MapClaims) {}, "", false},
+		{"unknown kid", func(c jwt.MapClaims) {}, "rotated-away", true},
+		{"other issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, testKeyID, true},
+		{"other audience", func(c jwt.MapClaims) { c["aud"] = "someone-else" }, testKeyID, true},
+		{"several audiences without azp", func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other"} }, testKeyID, true},
+		{"several audiences with foreign azp", func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other"}
+			c["azp"] = "other"
+		}, testKeyID, true},
+		{"several audiences with our azp", func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other"}
+			c["azp"] = testClientID
+		}, testKeyID, false},
+		{"nonce mismatch", func(c jwt.MapClaims) { c["nonce"] = "other" }, testKeyID, true},
+		{"missing nonce", func(c jwt.MapClaims) { delete(c, "nonce") }, testKeyID, true},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * clockSkew).Unix() }, testKeyID, true},
+		{"expired within skew", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-clockSkew / 2).Unix() }, testKeyID, false},
+		{"missing exp", func(c jwt.MapClaims) { delete(c, "exp") }, testKeyID, true},
+		{"missing sub", func(c jwt.MapClaims) { delete(c, "sub") }, testKeyID, true},
+	}
+
+	for _, tt := range tests {
+		claims := iss.claims()
+		tt.modify(claims)
+
+		_, err := p.VerifyIDToken(context.Background(), iss.sign(t, claims, tt.kid), testNonce)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidIDToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidIDToken", tt.name, err)
+		}
+	}
+}
+
+func TestVerifyIDTokenRejectsUnsignedAndHMAC(t *testing.T) {
+	iss := newTestIssuer(t)
+	p := iss.provider()
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, iss.claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// HMAC z kluczem publicznym jako sekretem - klasyczny atak na pomylenie algorytmów
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, iss.claims())
+	hmacToken.Header["kid"] = testKeyID
+	hmacSigned, err := hmacToken.SignedString(iss.key.N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, raw := range map[string]string{"none": unsigned, "HS256": hmacSigned} {
+		if _, err := p.VerifyIDToken(context.Background(), raw, testNonce); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+}
+
+func TestVerifyIDTokenEmailVerified(t *testing.T) {
+	iss := newTestIssuer(t)
+	p := iss.provider()
+
+	tests := []struct {
+		value any
+		want  bool
+	}{
+		{true, true},
+		{false, false},
+		{"true", true},
+		{"false", false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		claims := iss.claims()
+		claims["email_verified"] = tt.value
+		if tt.value == nil {
+			delete(claims, "email_verified")
+		}
+
+		result, err := p.VerifyIDToken(context.Background(), iss.sign(t, claims, testKeyID), testNonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.EmailVerified != tt.want {
+			t.Errorf("email_verified %v: got %v, want %v", tt.value, result.EmailVerified, tt.want)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	iss := newTestIssuer(t)
+	iss.idToken = iss.sign(t, iss.claims(), testKeyID)
+
+	claims, err := iss.provider().Exchange(context.Background(), "code", "verifier", "https://app.example.com/cb", testNonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "248289761001" || claims.Email != "jan@example.com" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	// Ten sam token nie przechodzi z nonce innego logowania
+	if _, err := iss.provider().Exchange(context.Background(), "code", "verifier", "https://app.example.com/cb", "other"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("err = %v, want ErrInvalidIDToken", err)
+	}
+}
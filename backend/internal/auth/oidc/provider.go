@@ -0,0 +1,194 @@
+// Package oidc implementuje stronę klienta (Relying Party) OpenID Connect:
+// discovery, przepływ authorization code z PKCE i weryfikację ID tokenu.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDiscovery      = errors.New("oidc discovery failed")
+	ErrTokenExchange  = errors.New("oidc token exchange failed")
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// Po nieznanym kid pobieramy klucze ponownie, ale nie częściej niż co minutę
+const keyRefreshInterval = time.Minute
+
+// Config opisuje dostawcę tożsamości
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string   // pusty dla klientów publicznych
+	Scopes       []string // domyślnie openid email profile
+}
+
+// Metadata to fragment dokumentu discovery, z którego korzystamy
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider to skonfigurowany dostawca. Metadane i klucze są pobierane leniwie
+// przy pierwszym użyciu, więc niedostępny dostawca nie blokuje startu serwera.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *Metadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL zwraca adres, na który przekierowujemy użytkownika do logowania u dostawcy
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint", ErrDiscovery)
+	}
+
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange wymienia kod na tokeny i zwraca zweryfikowane dane z ID tokenu
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, redirectURI, nonce string) (*Claims, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", codeVerifier)
+
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic - domyślna metoda uwierzytelnienia klienta w OIDC
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var response struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(req, &response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d %s %s", ErrTokenExchange, status, response.Error, response.ErrorDescription)
+	}
+	if response.IDToken == "" {
+		return nil, fmt.Errorf("%w: response without id_token", ErrTokenExchange)
+	}
+
+	return p.VerifyIDToken(ctx, response.IDToken, nonce)
+}
+
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	var metadata Metadata
+	status, err := p.doJSON(req, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrDiscovery, status)
+	}
+
+	// Dokument musi dotyczyć dokładnie tego wystawcy (OIDC Discovery 4.3)
+	if metadata.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("%w: issuer mismatch %q", ErrDiscovery, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete metadata", ErrDiscovery)
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, out any) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, out); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
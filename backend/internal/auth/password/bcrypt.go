@@ -0,0 +1,70 @@
+package password
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const AlgBcrypt = "bcrypt"
+
+// bcrypt uwzględnia najwyżej 72 bajty hasła, a dłuższe biblioteka odrzuca
+const bcryptMaxBytes = 72
+
+// Bcrypt obsługuje skróty w formacie $2a$/$2b$/$2y$, w którym koszt jest
+// zapisany w samym skrócie
+type Bcrypt struct {
+	cost int
+}
+
+func NewBcrypt(cost int) *Bcrypt {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &Bcrypt{cost: cost}
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(bcryptInput(password), b.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func (b *Bcrypt) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), bcryptInput(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (b *Bcrypt) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *Bcrypt) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.cost
+}
+
+// bcryptInput zastępuje hasła dłuższe niż 72 bajty ich skrótem SHA-256
+// zakodowanym w base64. Krótsze hasła przechodzą bez zmian, więc skróty
+// zapisane wcześniej pozostają ważne.
+func bcryptInput(password string) []byte {
+	if len(password) <= bcryptMaxBytes {
+		return []byte(password)
+	}
+	sum := sha256.Sum256([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(sum[:]))
+}
@@ -0,0 +1,129 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Długość prefiksu SHA-1 wyznaczającego plik zakresu (jak w API Pwned Passwords)
+const rangePrefixLength = 5
+
+// BreachedList sprawdza hasła w lokalnej kopii bazy haseł z wycieków.
+// Katalog ma układ zakresów Pwned Passwords: plik <prefiks>.txt (5 pierwszych
+// znaków SHA-1 hasła) zawiera linie "<pozostałe 35 znaków>:<liczba wystąpień>".
+// Sprawdzenie czyta tylko jeden plik zakresu, a katalog można podmieniać
+// offline - pobranym zbiorem albo programem breached-passwords.
+type BreachedList struct {
+	dir      string
+	minCount int
+}
+
+// NewBreachedList otwiera katalog zakresów. Hasła występujące rzadziej niż
+// minCount razy (np. wpisy dopełniające z licznikiem 0) są akceptowane.
+func NewBreachedList(dir string, minCount int) (*BreachedList, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached password list %s is not a directory", dir)
+	}
+	if minCount < 1 {
+		minCount = 1
+	}
+	return &BreachedList{dir: dir, minCount: minCount}, nil
+}
+
+// Contains informuje, czy hasło występuje w bazie wycieków
+func (l *BreachedList) Contains(password string) (bool, error) {
+	prefix, suffix := RangeKey(password)
+
+	entries, err := ReadRange(l.dir, prefix)
+	if err != nil {
+		return false, err
+	}
+	return entries[suffix] >= l.minCount, nil
+}
+
+// RangeKey dzieli szesnastkowy SHA-1 hasła (wielkie litery) na prefiks zakresu i resztę
+func RangeKey(password string) (prefix, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:rangePrefixLength], hash[rangePrefixLength:]
+}
+
+// ReadRange wczytuje plik zakresu. Brak pliku oznacza pusty zakres.
+func ReadRange(dir, prefix string) (map[string]int, error) {
+	file, err := os.Open(rangePath(dir, prefix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]int{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read breached password range: %w", err)
+	}
+	defer file.Close()
+
+	entries := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		suffix, countText, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found {
+			continue
+		}
+		count, err := strconv.Atoi(countText)
+		if err != nil {
+			continue
+		}
+		entries[strings.ToUpper(suffix)] = count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password range: %w", err)
+	}
+	return entries, nil
+}
+
+// WriteRange zapisuje plik zakresu posortowany po skrócie. Plik jest
+// podmieniany atomowo, więc działający serwer nie przeczyta go w połowie zapisu.
+func WriteRange(dir, prefix string, entries map[string]int) error {
+	suffixes := make([]string, 0, len(entries))
+	for suffix := range entries {
+		suffixes = append(suffixes, suffix)
+	}
+	sort.Strings(suffixes)
+
+	tmp, err := os.CreateTemp(dir, prefix+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write breached password range: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	for _, suffix := range suffixes {
+		fmt.Fprintf(writer, "%s:%d\r\n", suffix, entries[suffix])
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write breached password range: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write breached password range: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), rangePath(dir, prefix)); err != nil {
+		return fmt.Errorf("failed to write breached password range: %w", err)
+	}
+	return nil
+}
+
+func rangePath(dir, prefix string) string {
+	return filepath.Join(dir, prefix+".txt")
+}
@@ -0,0 +1,130 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRangeKey(t *testing.T) {
+	// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	prefix, suffix := RangeKey("password")
+	if prefix != "5BAA6" || suffix != "1E4C9B93F3F0682250B6CF8331B7EE68FD8" {
+		t.Errorf("RangeKey = %s, %s", prefix, suffix)
+	}
+}
+
+func TestBreachedListContains(t *testing.T) {
+	dir := t.TempDir()
+	prefix, suffix := RangeKey("password")
+	rarePrefix, rareSuffix := RangeKey("rare-password")
+
+	// Plik w formacie API Pwned Passwords: CRLF, wpisy dopełniające z licznikiem 0
+	rangeFile := "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n" +
+		suffix + ":9659365\r\n" +
+		"not a valid line\r\n" +
+		"00D4F6E8FA6EECAD2A3AA415EEC418D38EC:0\r\n"
+	if err := os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(rangeFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Małe litery w pliku nie przeszkadzają w dopasowaniu
+	if err := os.WriteFile(filepath.Join(dir, rarePrefix+".txt"), []byte(strings.ToLower(rareSuffix)+":2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		minCount int
+		password string
+		want     bool
+	}{
+		{"breached", 1, "password", true},
+		{"lower-case suffix", 1, "rare-password", true},
+		{"below minimal count", 3, "rare-password", false},
+		{"other password", 1, "password1", false},
+		{"range file missing", 1, "correct horse battery staple", false},
+	}
+	for _, tt := range tests {
+		list, err := NewBreachedList(dir, tt.minCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := list.Contains(tt.password)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Contains = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestBreachedListPaddingEntries(t *testing.T) {
+	dir := t.TempDir()
+	prefix, suffix := RangeKey("padded")
+	if err := WriteRange(dir, prefix, map[string]int{suffix: 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	// minCount poniżej 1 nie sprawia, że wpisy z licznikiem 0 blokują hasło
+	list, err := NewBreachedList(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := list.Contains("padded"); err != nil || got {
+		t.Errorf("Contains = %v, %v; want false for a padding entry", got, err)
+	}
+}
+
+func TestNewBreachedListRequiresDirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "list.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{filepath.Join(dir, "missing"), file} {
+		if _, err := NewBreachedList(path, 1); err == nil {
+			t.Errorf("NewBreachedList(%s) succeeded", path)
+		}
+	}
+}
+
+func TestWriteRangeRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	entries := map[string]int{
+		"FFFFF0000000000000000000000000000000": 3,
+		"00000000000000000000000000000000000A": 1,
+		"80000000000000000000000000000000000B": 0,
+	}
+	if err := WriteRange(dir, "ABCDE", entries); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "ABCDE.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "00000000000000000000000000000000000A:1\r\n" +
+		"80000000000000000000000000000000000B:0\r\n" +
+		"FFFFF0000000000000000000000000000000:3\r\n"
+	if string(data) != want {
+		t.Errorf("range file =\n%q\nwant\n%q", data, want)
+	}
+
+	got, err := ReadRange(dir, "ABCDE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, entries) {
+		t.Errorf("ReadRange = %v, want %v", got, entries)
+	}
+
+	// Plik tymczasowy nie zostaje w katalogu
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("directory contains %d files, want only the range file", len(files))
+	}
+}
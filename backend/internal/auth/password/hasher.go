@@ -0,0 +1,77 @@
+// Package password haszuje hasła użytkowników. Zakodowany skrót zawiera
+// identyfikator algorytmu i jego parametry, dzięki czemu koszty można
+// podnosić stopniowo - stare skróty są przeliczane przy najbliższym logowaniu.
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Scheme to pojedynczy algorytm haszowania haseł
+type Scheme interface {
+	// Hash zwraca zakodowany skrót hasła z losową solą
+	Hash(password string) (string, error)
+	// Verify porównuje hasło ze skrótem w stałym czasie
+	Verify(password, encoded string) (bool, error)
+	// Recognizes mówi, czy skrót został wytworzony tym algorytmem
+	Recognizes(encoded string) bool
+	// Outdated mówi, czy skrót ma inne parametry niż obecnie skonfigurowane
+	Outdated(encoded string) bool
+}
+
+// Hasher haszuje nowe hasła preferowanym algorytmem, a weryfikuje
+// skróty każdego znanego algorytmu
+type Hasher struct {
+	preferred Scheme
+	schemes   []Scheme
+}
+
+// NewHasher tworzy hasher; legacy to algorytmy akceptowane tylko przy weryfikacji
+func NewHasher(preferred Scheme, legacy ...Scheme) *Hasher {
+	return &Hasher{
+		preferred: preferred,
+		schemes:   append([]Scheme{preferred}, legacy...),
+	}
+}
+
+// New tworzy hasher na podstawie nazwy algorytmu z konfiguracji.
+// Drugi z obsługiwanych algorytmów pozostaje dostępny do weryfikacji.
+func New(algorithm string, argon Argon2Params, bcryptCost int) (*Hasher, error) {
+	argonScheme := NewArgon2id(argon)
+	bcryptScheme := NewBcrypt(bcryptCost)
+
+	switch algorithm {
+	case "", AlgArgon2id:
+		return NewHasher(argonScheme, bcryptScheme), nil
+	case AlgBcrypt:
+		return NewHasher(bcryptScheme, argonScheme), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", algorithm)
+	}
+}
+
+// Hash haszuje hasło preferowanym algorytmem
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify sprawdza hasło. rehash oznacza, że hasło jest poprawne, ale skrót
+// należy przeliczyć, bo powstał innym algorytmem lub ze starymi parametrami.
+func (h *Hasher) Verify(password, encoded string) (ok, rehash bool, err error) {
+	for _, scheme := range h.schemes {
+		if !scheme.Recognizes(encoded) {
+			continue
+		}
+
+		ok, err = scheme.Verify(password, encoded)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, scheme != h.preferred || scheme.Outdated(encoded), nil
+	}
+
+	return false, false, ErrUnknownHash
+}
@@ -0,0 +1,204 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Niskie koszty, żeby testy trwały milisekundy
+var testArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func mustHash(t *testing.T, scheme Scheme, password string) string {
+	t.Helper()
+	encoded, err := scheme.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	argon := NewArgon2id(testArgon2Params)
+
+	encoded := mustHash(t, argon, "correct horse battery")
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") || strings.Count(encoded, "$") != 5 {
+		t.Fatalf("encoded = %q, want PHC string with the configured params", encoded)
+	}
+	if other := mustHash(t, argon, "correct horse battery"); other == encoded {
+		t.Error("two hashes of the same password are equal - salt is not random")
+	}
+
+	params, salt, key, err := decodeArgon2(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testArgon2Params
+	want.SaltLength, want.KeyLength = DefaultArgon2Params.SaltLength, DefaultArgon2Params.KeyLength
+	if params != want || len(salt) != 16 || len(key) != 32 {
+		t.Errorf("decoded params %+v, salt %d bytes, key %d bytes; want %+v, 16, 32", params, len(salt), len(key), want)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"correct horse battery", true},
+		{"correct horse batter", false},
+		{"Correct horse battery", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		ok, err := argon.Verify(tt.password, encoded)
+		if err != nil || ok != tt.want {
+			t.Errorf("Verify(%q) = %v, %v; want %v", tt.password, ok, err, tt.want)
+		}
+	}
+}
+
+func TestArgon2idRejectsMalformedHashes(t *testing.T) {
+	argon := NewArgon2id(testArgon2Params)
+	valid := mustHash(t, argon, "secret")
+	parts := strings.Split(valid, "$")
+
+	for name, encoded := range map[string]string{
+		"too few parts": "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+		"other version": strings.Replace(valid, "v=19", "v=16", 1),
+		"bad params":    strings.Replace(valid, "m=1024,t=1,p=1", "m=x", 1),
+		"bad salt":      strings.Join([]string{"", parts[1], parts[2], parts[3], "!!!", parts[5]}, "$"),
+		"bad key":       strings.Join([]string{"", parts[1], parts[2], parts[3], parts[4], "!!!"}, "$"),
+	} {
+		if ok, err := argon.Verify("secret", encoded); ok || err == nil {
+			t.Errorf("%s: Verify = %v, %v; want error", name, ok, err)
+		}
+		if !argon.Outdated(encoded) {
+			t.Errorf("%s: malformed hash is not outdated", name)
+		}
+	}
+}
+
+func TestHasherVerifyRehash(t *testing.T) {
+	argon := NewArgon2id(testArgon2Params)
+	weakArgon := NewArgon2id(Argon2Params{Memory: 512, Iterations: 1, Parallelism: 1})
+	bcryptScheme := NewBcrypt(bcrypt.MinCost)
+	cheaperBcrypt := NewBcrypt(bcrypt.MinCost + 1)
+
+	argonFirst := NewHasher(argon, bcryptScheme)
+	bcryptFirst := NewHasher(bcryptScheme, argon)
+
+	tests := []struct {
+		name       string
+		hasher     *Hasher
+		encoded    string
+		password   string
+		wantOK     bool
+		wantRehash bool
+	}{
+		{"argon2id with current params", argonFirst, mustHash(t, argon, "secret"), "secret", true, false},
+		{"argon2id with weaker params", argonFirst, mustHash(t, weakArgon, "secret"), "secret", true, true},
+		{"argon2id with longer salt", argonFirst, mustHash(t, NewArgon2id(Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 32}), "secret"), "secret", true, true},
+		{"legacy bcrypt", argonFirst, mustHash(t, bcryptScheme, "secret"), "secret", true, true},
+		{"wrong password is never rehashed", argonFirst, mustHash(t, weakArgon, "secret"), "other", false, false},
+		{"wrong password for bcrypt", argonFirst, mustHash(t, bcryptScheme, "secret"), "other", false, false},
+		{"bcrypt with current cost", bcryptFirst, mustHash(t, bcryptScheme, "secret"), "secret", true, false},
+		{"bcrypt with other cost", bcryptFirst, mustHash(t, cheaperBcrypt, "secret"), "secret", true, true},
+		{"argon2id when bcrypt is preferred", bcryptFirst, mustHash(t, argon, "secret"), "secret", true, true},
+	}
+
+	for _, tt := range tests {
+		ok, rehash, err := tt.hasher.Verify(tt.password, tt.encoded)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if ok != tt.wantOK || rehash != tt.wantRehash {
+			t.Errorf("%s: Verify = (%v, %v), want (%v, %v)", tt.name, ok, rehash, tt.wantOK, tt.wantRehash)
+		}
+	}
+}
+
+func TestHasherUnknownHash(t *testing.T) {
+	hasher := NewHasher(NewArgon2id(testArgon2Params), NewBcrypt(bcrypt.MinCost))
+
+	for _, encoded := range []string{
+		"",
+		"secret",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$scrypt$ln=15,r=8,p=1$c2FsdA$a2V5",
+		"$1$salt$hash",
+	} {
+		if ok, rehash, err := hasher.Verify("secret", encoded); err != ErrUnknownHash || ok || rehash {
+			t.Errorf("Verify(%q) = %v, %v, %v; want ErrUnknownHash", encoded, ok, rehash, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		wantPHC   string
+		wantErr   bool
+	}{
+		{"", "$argon2id$", false},
+		{AlgArgon2id, "$argon2id$", false},
+		{AlgBcrypt, "$2a$", false},
+		{"md5", "", true},
+	}
+	for _, tt := range tests {
+		hasher, err := New(tt.algorithm, testArgon2Params, bcrypt.MinCost)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: want error", tt.algorithm)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.algorithm, err)
+		}
+		if encoded, _ := hasher.Hash("secret"); !strings.HasPrefix(encoded, tt.wantPHC) {
+			t.Errorf("%q: hash %q, want prefix %s", tt.algorithm, encoded, tt.wantPHC)
+		}
+	}
+}
+
+func TestBcryptLongPasswords(t *testing.T) {
+	b := NewBcrypt(bcrypt.MinCost)
+
+	// 128 znaków spoza ASCII to nawet 512 bajtów UTF-8
+	long := strings.Repeat("żółw", 32)
+	samePrefix := long[:72] + "inny koniec hasła"
+
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{"exactly 72 bytes", strings.Repeat("a", 72)},
+		{"73 bytes", strings.Repeat("a", 73)},
+		{"128 multibyte characters", long},
+	}
+	for _, tt := range tests {
+		encoded, err := b.Hash(tt.password)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ok, err := b.Verify(tt.password, encoded); err != nil || !ok {
+			t.Errorf("%s: Verify = %v, %v; want true", tt.name, ok, err)
+		}
+	}
+
+	// Hasła różniące się dopiero za 72. bajtem nie mogą mieć wspólnego skrótu
+	encoded := mustHash(t, b, long)
+	if ok, err := b.Verify(samePrefix, encoded); err != nil || ok {
+		t.Errorf("password sharing the first 72 bytes: Verify = %v, %v; want false", ok, err)
+	}
+
+	// Skróty krótszych haseł zapisane bez przekształcenia nadal pasują
+	legacy, err := bcrypt.GenerateFromPassword([]byte(strings.Repeat("a", 72)), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := b.Verify(strings.Repeat("a", 72), string(legacy)); err != nil || !ok {
+		t.Errorf("legacy 72-byte hash: Verify = %v, %v; want true", ok, err)
+	}
+}
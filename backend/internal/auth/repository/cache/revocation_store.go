@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevocationBackend to trwały magazyn unieważnień (np. Postgres), który cache opakowuje
+type RevocationBackend interface {
+	RevokeToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, before time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	RevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error)
+}
+
+type tokenEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+type userEntry struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+// RevocationStore trzyma w pamięci wyniki sprawdzeń unieważnień, żeby middleware
+// nie odpytywał bazy przy każdym żądaniu. Unieważnienia wykonane na tej instancji
+// są widoczne natychmiast, a te z innych replik - najpóźniej po upływie ttl.
+type RevocationStore struct {
+	backend RevocationBackend
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	tokens    map[string]tokenEntry
+	users     map[uuid.UUID]userEntry
+	lastSweep time.Time
+}
+
+func NewRevocationStore(backend RevocationBackend, ttl time.Duration) *RevocationStore {
+	return &RevocationStore{
+		backend:   backend,
+		ttl:       ttl,
+		tokens:    make(map[string]tokenEntry),
+		users:     make(map[uuid.UUID]userEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *RevocationStore) RevokeToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	if err := s.backend.RevokeToken(ctx, jti, userID, expiresAt); err != nil {
+		return err
+	}
+
+	// Unieważnienie jest ostateczne, więc możemy je pamiętać aż do wygaśnięcia tokenu
+	s.mu.Lock()
+	s.tokens[jti] = tokenEntry{revoked: true, expiresAt: expiresAt}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *RevocationStore) RevokeAllForUser(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	if err := s.backend.RevokeAllForUser(ctx, userID, before); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.users, userID)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *RevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.tokens[jti]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := s.backend.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = tokenEntry{revoked: revoked, expiresAt: now.Add(s.ttl)}
+	s.sweepLocked(now)
+	s.mu.Unlock()
+	return revoked, nil
+}
+
+func (s *RevocationStore) RevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.users[userID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.before, nil
+	}
+
+	before, err := s.backend.RevokedBefore(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	s.mu.Lock()
+	s.users[userID] = userEntry{before: before, expiresAt: now.Add(s.ttl)}
+	s.sweepLocked(now)
+	s.mu.Unlock()
+	return before, nil
+}
+
+// sweepLocked usuwa przeterminowane wpisy, co najwyżej raz na ttl
+func (s *RevocationStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	s.lastSweep = now
+
+	for jti, entry := range s.tokens {
+		if !now.Before(entry.expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, entry := range s.users {
+		if !now.Before(entry.expiresAt) {
+			delete(s.users, userID)
+		}
+	}
+}
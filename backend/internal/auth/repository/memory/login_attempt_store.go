@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// LoginAttemptStore trzyma liczniki nieudanych logowań w pamięci procesu.
+// Wystarcza dla pojedynczej instancji; przy wielu replikach należy użyć wersji Postgres.
+type LoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginAttempts
+}
+
+func NewLoginAttemptStore() *LoginAttemptStore {
+	return &LoginAttemptStore{
+		attempts: make(map[string]domain.LoginAttempts),
+	}
+}
+
+func (s *LoginAttemptStore) Get(_ context.Context, key string) (domain.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.attempts[key]
+	if !ok {
+		return domain.LoginAttempts{Key: key}, nil
+	}
+	return state, nil
+}
+
+func (s *LoginAttemptStore) RecordFailure(_ context.Context, key string, now time.Time, policy domain.LockoutPolicy) (domain.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(domain.AttemptKeyPrefix(key), now, policy.Window)
+
+	state, ok := s.attempts[key]
+	if !ok {
+		state = domain.LoginAttempts{Key: key}
+	}
+
+	state = policy.RegisterFailure(state, now)
+	s.attempts[key] = state
+	return state, nil
+}
+
+func (s *LoginAttemptStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// sweepLocked usuwa liczniki danego rodzaju, które i tak zostałyby wyzerowane
+func (s *LoginAttemptStore) sweepLocked(prefix string, now time.Time, window time.Duration) {
+	for key, state := range s.attempts {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if now.Sub(state.LastFailureAt) > window && !now.Before(state.LockedUntil) {
+			delete(s.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+func TestRecordFailureSweepsOnlyKeysOfSameKind(t *testing.T) {
+	ctx := context.Background()
+	store := NewLoginAttemptStore()
+
+	accountPolicy := domain.LockoutPolicy{Threshold: 5, BaseDelay: time.Second, MaxDelay: time.Minute, Window: time.Hour}
+	ipPolicy := domain.LockoutPolicy{Threshold: 20, BaseDelay: time.Second, MaxDelay: time.Minute, Window: time.Minute}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := store.RecordFailure(ctx, "account:a@example.com", start, accountPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RecordFailure(ctx, "ip:10.0.0.1", start, ipPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	// Po 10 minutach licznik IP wygasł, ale licznik konta wciąż obowiązuje
+	later := start.Add(10 * time.Minute)
+	if _, err := store.RecordFailure(ctx, "ip:10.0.0.2", later, ipPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	account, _ := store.Get(ctx, "account:a@example.com")
+	if account.Failures != 1 {
+		t.Errorf("account counter swept by ip policy: failures = %d, want 1", account.Failures)
+	}
+	expired, _ := store.Get(ctx, "ip:10.0.0.1")
+	if expired.Failures != 0 {
+		t.Errorf("expired ip counter kept: failures = %d, want 0", expired.Failures)
+	}
+}
+
+func TestAttemptKeyPrefix(t *testing.T) {
+	tests := map[string]string{
+		"account:a@example.com": "account:",
+		"ip:2001:db8::1":        "ip:",
+		"other":                 "other",
+	}
+	for key, want := range tests {
+		if got := domain.AttemptKeyPrefix(key); got != want {
+			t.Errorf("AttemptKeyPrefix(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
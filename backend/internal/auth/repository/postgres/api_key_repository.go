@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// Rzadsze zapisy last_used_at - skrypty potrafią wysyłać wiele żądań na sekundę
+const lastUsedResolution = time.Minute
+
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `INSERT INTO api_keys 
+		(id, user_id, name, prefix, key_hash, scopes, expires_at, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.db.Exec(ctx, query,
+		key.ID,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes,
+		key.ExpiresAt,
+		key.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// ListByUser zwraca klucze użytkownika, od najnowszych
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.APIKey, error) {
+		key, err := scanAPIKey(row)
+		if err != nil {
+			return domain.APIKey{}, err
+		}
+		return *key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.db.QueryRow(ctx, query, keyHash))
+	if err != nil && !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return key, err
+}
+
+// CountActive zwraca liczbę nieunieważnionych i niewygasłych kluczy użytkownika
+func (r *APIKeyRepository) CountActive(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM api_keys 
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, userID, now).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	return count, nil
+}
+
+// Revoke unieważnia klucz należący do użytkownika
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID, id uuid.UUID, at time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $3) WHERE id = $1 AND user_id = $2`
+
+	tag, err := r.db.Exec(ctx, query, id, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed zapisuje czas użycia klucza, najwyżej raz na lastUsedResolution
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $2 
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`
+
+	if _, err := r.db.Exec(ctx, query, id, at, at.Add(-lastUsedResolution)); err != nil {
+		return fmt.Errorf("failed to update api key last use: %w", err)
+	}
+	return nil
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
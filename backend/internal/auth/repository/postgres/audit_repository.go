@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// AuditRepository przechowuje dziennik audytu. Wpisy są tylko dopisywane -
+// nie usuwamy ich nawet przy anonimizacji konta.
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `INSERT INTO audit_log 
+		(id, actor_id, user_id, action, token_id, read_only, reason, method, path, status, ip, user_agent, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := r.db.Exec(ctx, query,
+		entry.ID,
+		entry.ActorID,
+		entry.UserID,
+		entry.Action,
+		entry.TokenID,
+		entry.ReadOnly,
+		entry.Reason,
+		entry.Method,
+		entry.Path,
+		entry.Status,
+		entry.IP,
+		entry.UserAgent,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+	return nil
+}
+
+// List zwraca wpisy pasujące do filtra, od najnowszych
+func (r *AuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	var conditions []string
+	var args []any
+
+	if filter.ActorID != nil {
+		args = append(args, *filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.TokenID != "" {
+		args = append(args, filter.TokenID)
+		conditions = append(conditions, fmt.Sprintf("token_id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d`, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.AuditEntry, error) {
+		entry, err := scanAuditEntry(row)
+		if err != nil {
+			return domain.AuditEntry{}, err
+		}
+		return *entry, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+const auditColumns = `id, actor_id, user_id, action, token_id, read_only, reason, method, path, status, ip, user_agent, created_at`
+
+func scanAuditEntry(row pgx.Row) (*domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.ActorID,
+		&entry.UserID,
+		&entry.Action,
+		&entry.TokenID,
+		&entry.ReadOnly,
+		&entry.Reason,
+		&entry.Method,
+		&entry.Path,
+		&entry.Status,
+		&entry.IP,
+		&entry.UserAgent,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
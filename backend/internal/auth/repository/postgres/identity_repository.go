@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+type IdentityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepository(db *pgxpool.Pool) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+func (r *IdentityRepository) Get(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	query := `SELECT provider, subject, user_id, email, created_at 
+		FROM user_identities WHERE provider = $1 AND subject = $2`
+
+	var identity domain.UserIdentity
+	err := r.db.QueryRow(ctx, query, provider, subject).Scan(
+		&identity.Provider,
+		&identity.Subject,
+		&identity.UserID,
+		&identity.Email,
+		&identity.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrIdentityNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	query := `INSERT INTO user_identities (provider, subject, user_id, email, created_at) 
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.db.Exec(ctx, query,
+		identity.Provider,
+		identity.Subject,
+		identity.UserID,
+		identity.Email,
+		identity.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// LoginAttemptStore przechowuje liczniki nieudanych logowań w bazie,
+// dzięki czemu blokada obowiązuje na wszystkich replikach
+type LoginAttemptStore struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginAttemptStore(db *pgxpool.Pool) *LoginAttemptStore {
+	return &LoginAttemptStore{db: db}
+}
+
+func (s *LoginAttemptStore) Get(ctx context.Context, key string) (domain.LoginAttempts, error) {
+	query := `SELECT key, failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1`
+
+	state, err := scanLoginAttempts(s.db.QueryRow(ctx, query, key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.LoginAttempts{Key: key}, nil
+	}
+	if err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+	return state, nil
+}
+
+func (s *LoginAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, policy domain.LockoutPolicy) (domain.LoginAttempts, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Upewniamy się, że wiersz istnieje, i blokujemy go na czas wyliczenia nowego stanu
+	_, err = tx.Exec(ctx, `INSERT INTO login_attempts (key, failures, last_failure_at, locked_until) 
+		VALUES ($1, 0, $2, $2) ON CONFLICT (key) DO NOTHING`, key, time.Time{})
+	if err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("failed to create login attempts: %w", err)
+	}
+
+	state, err := scanLoginAttempts(tx.QueryRow(ctx,
+		`SELECT key, failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1 FOR UPDATE`, key))
+	if err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+
+	state = policy.RegisterFailure(state, now)
+
+	_, err = tx.Exec(ctx, `UPDATE login_attempts SET failures = $2, last_failure_at = $3, locked_until = $4 
+		WHERE key = $1`,
+		key,
+		state.Failures,
+		state.LastFailureAt,
+		state.LockedUntil,
+	)
+	if err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("failed to update login attempts: %w", err)
+	}
+
+	// Przy okazji sprzątamy liczniki tego samego rodzaju (konta albo IP), które
+	// i tak zostałyby wyzerowane - inne rodzaje mają własne okno polityki
+	_, err = tx.Exec(ctx, `DELETE FROM login_attempts 
+		WHERE starts_with(key, $1) AND last_failure_at < $2 AND locked_until < $3`,
+		domain.AttemptKeyPrefix(key),
+		now.Add(-policy.Window),
+		now,
+	)
+	if err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("failed to purge login attempts: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return state, nil
+}
+
+func (s *LoginAttemptStore) Reset(ctx context.Context, key string) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM login_attempts WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
+
+func scanLoginAttempts(row pgx.Row) (domain.LoginAttempts, error) {
+	var state domain.LoginAttempts
+	err := row.Scan(
+		&state.Key,
+		&state.Failures,
+		&state.LastFailureAt,
+		&state.LockedUntil,
+	)
+	return state, err
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+type MFARepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepository(db *pgxpool.Pool) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// Get zwraca konfigurację MFA użytkownika lub domain.ErrMFANotEnrolled
+func (r *MFARepository) Get(ctx context.Context, userID uuid.UUID) (*domain.MFA, error) {
+	query := `SELECT user_id, secret_encrypted, confirmed_at, last_used_step, created_at 
+		FROM user_mfa WHERE user_id = $1`
+
+	var mfa domain.MFA
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&mfa.UserID,
+		&mfa.SecretEncrypted,
+		&mfa.ConfirmedAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrMFANotEnrolled
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	return &mfa, nil
+}
+
+// SavePending zapisuje nowy, niepotwierdzony sekret (nadpisując poprzedni niepotwierdzony)
+func (r *MFARepository) SavePending(ctx context.Context, mfa *domain.MFA) error {
+	query := `INSERT INTO user_mfa (user_id, secret_encrypted, created_at) 
+		VALUES ($1, $2, $3) 
+		ON CONFLICT (user_id) DO UPDATE 
+		SET secret_encrypted = EXCLUDED.secret_encrypted, created_at = EXCLUDED.created_at, last_used_step = 0 
+		WHERE user_mfa.confirmed_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, mfa.UserID, mfa.SecretEncrypted, mfa.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save mfa: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// Confirm aktywuje MFA i zapisuje kody awaryjne w jednej transakcji
+func (r *MFARepository) Confirm(ctx context.Context, userID uuid.UUID, step int64, at time.Time, codes []domain.RecoveryCode) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE user_mfa SET confirmed_at = $2, last_used_step = $3 
+		WHERE user_id = $1 AND confirmed_at IS NULL`, userID, at, step)
+	if err != nil {
+		return fmt.Errorf("failed to confirm mfa: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UseStep zapisuje wykorzystane okno czasowe. Zwraca domain.ErrInvalidMFACode,
+// jeśli kod z tego (lub późniejszego) okna został już użyty.
+func (r *MFARepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := `UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+
+	tag, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to update mfa step: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// UseRecoveryCode oznacza kod awaryjny jako wykorzystany lub zwraca domain.ErrInvalidMFACode
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, at time.Time) error {
+	query := `UPDATE mfa_recovery_codes SET used_at = $3 
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, userID, codeHash, at)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes unieważnia dotychczasowe kody awaryjne i zapisuje nowe
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []domain.RecoveryCode) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete wyłącza MFA i usuwa kody awaryjne
+func (r *MFARepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete mfa: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codes []domain.RecoveryCode) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, code := range codes {
+		_, err := tx.Exec(ctx, `INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at) 
+			VALUES ($1, $2, $3, $4)`,
+			code.ID,
+			code.UserID,
+			code.CodeHash,
+			code.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+type OIDCStateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOIDCStateRepository(db *pgxpool.Pool) *OIDCStateRepository {
+	return &OIDCStateRepository{db: db}
+}
+
+// Create zapisuje stan logowania i przy okazji usuwa porzucone, wygasłe wpisy
+func (r *OIDCStateRepository) Create(ctx context.Context, state *domain.OIDCState) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM oidc_states WHERE expires_at < $1`, state.CreatedAt); err != nil {
+		return fmt.Errorf("failed to delete expired oidc states: %w", err)
+	}
+
+	query := `INSERT INTO oidc_states 
+		(state_hash, provider, nonce, code_verifier, expires_at, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.db.Exec(ctx, query,
+		state.StateHash,
+		state.Provider,
+		state.Nonce,
+		state.CodeVerifier,
+		state.ExpiresAt,
+		state.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create oidc state: %w", err)
+	}
+	return nil
+}
+
+// Consume usuwa stan i zwraca go, jeśli jeszcze nie wygasł - każdy state działa raz
+func (r *OIDCStateRepository) Consume(ctx context.Context, stateHash string, now time.Time) (*domain.OIDCState, error) {
+	query := `DELETE FROM oidc_states WHERE state_hash = $1 
+		RETURNING state_hash, provider, nonce, code_verifier, expires_at, created_at`
+
+	var state domain.OIDCState
+	err := r.db.QueryRow(ctx, query, stateHash).Scan(
+		&state.StateHash,
+		&state.Provider,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.ExpiresAt,
+		&state.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrInvalidOIDCState
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume oidc state: %w", err)
+	}
+
+	if !now.Before(state.ExpiresAt) {
+		return nil, domain.ErrInvalidOIDCState
+	}
+	return &state, nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+type OneTimeTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOneTimeTokenRepository(db *pgxpool.Pool) *OneTimeTokenRepository {
+	return &OneTimeTokenRepository{db: db}
+}
+
+func (r *OneTimeTokenRepository) Create(ctx context.Context, token *domain.OneTimeToken) error {
+	query := `INSERT INTO one_time_tokens 
+		(id, user_id, purpose, token_hash, payload, expires_at, created_at) 
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)`
+
+	_, err := r.db.Exec(ctx, query,
+		token.ID,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.Payload,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create one-time token: %w", err)
+	}
+	return nil
+}
+
+// Peek zwraca ważny token bez oznaczania go jako wykorzystany
+func (r *OneTimeTokenRepository) Peek(ctx context.Context, purpose domain.TokenPurpose, tokenHash string, now time.Time) (*domain.OneTimeToken, error) {
+	query := `SELECT ` + oneTimeTokenColumns + ` FROM one_time_tokens 
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3`
+
+	token, err := scanOneTimeToken(r.db.QueryRow(ctx, query, tokenHash, purpose, now))
+	if err != nil && !errors.Is(err, domain.ErrOneTimeTokenInvalid) {
+		return nil, fmt.Errorf("failed to get one-time token: %w", err)
+	}
+	return token, err
+}
+
+// Consume atomowo oznacza token jako wykorzystany i zwraca go.
+// Token wykorzystany, wygasły lub o innym przeznaczeniu daje domain.ErrOneTimeTokenInvalid.
+func (r *OneTimeTokenRepository) Consume(ctx context.Context, purpose domain.TokenPurpose, tokenHash string, now time.Time) (*domain.OneTimeToken, error) {
+	query := `UPDATE one_time_tokens SET used_at = $3 
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3 
+		RETURNING ` + oneTimeTokenColumns
+
+	token, err := scanOneTimeToken(r.db.QueryRow(ctx, query, tokenHash, purpose, now))
+	if err != nil && !errors.Is(err, domain.ErrOneTimeTokenInvalid) {
+		return nil, fmt.Errorf("failed to consume one-time token: %w", err)
+	}
+	return token, err
+}
+
+// InvalidateForUser unieważnia wszystkie niewykorzystane tokeny użytkownika o danym przeznaczeniu
+func (r *OneTimeTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose, now time.Time) error {
+	query := `UPDATE one_time_tokens SET used_at = $3 
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, userID, purpose, now); err != nil {
+		return fmt.Errorf("failed to invalidate one-time tokens: %w", err)
+	}
+	return nil
+}
+
+const oneTimeTokenColumns = `id, user_id, purpose, token_hash, COALESCE(payload, ''), expires_at, used_at, created_at`
+
+func scanOneTimeToken(row pgx.Row) (*domain.OneTimeToken, error) {
+	var token domain.OneTimeToken
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.Payload,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrOneTimeTokenInvalid
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
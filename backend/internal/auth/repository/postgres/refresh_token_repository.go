@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+type RefreshTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRefreshTokenRepository(db *pgxpool.Pool) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens 
+		(id, user_id, family_id, token_hash, client_id, scopes, expires_at, created_at) 
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)`
+
+	_, err := r.db.Exec(ctx, query,
+		token.ID,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ClientID,
+		token.Scopes,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `SELECT id, user_id, family_id, token_hash, COALESCE(client_id, ''), scopes, 
+			expires_at, revoked_at, replaced_by, created_at 
+		FROM refresh_tokens WHERE token_hash = $1`
+
+	var token domain.RefreshToken
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ClientID,
+		&token.Scopes,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.ReplacedBy,
+		&token.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// Rotate unieważnia stary token i zapisuje jego następcę w jednej transakcji.
+// Jeśli stary token został już w międzyczasie unieważniony (np. przez równoległe
+// żądanie), zwraca domain.ErrRefreshTokenReused.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, oldID uuid.UUID, next *domain.RefreshToken) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `INSERT INTO refresh_tokens 
+		(id, user_id, family_id, token_hash, client_id, scopes, expires_at, created_at) 
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)`,
+		next.ID,
+		next.UserID,
+		next.FamilyID,
+		next.TokenHash,
+		next.ClientID,
+		next.Scopes,
+		next.ExpiresAt,
+		next.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = $2, replaced_by = $3 
+		WHERE id = $1 AND revoked_at IS NULL`,
+		oldID,
+		next.CreatedAt,
+		next.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $2 
+		WHERE family_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, familyID, at); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $2 
+		WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, userID, at); err != nil {
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokeForClient unieważnia tokeny odświeżające wystawione użytkownikowi dla danej aplikacji
+func (r *RefreshTokenRepository) RevokeForClient(ctx context.Context, userID uuid.UUID, clientID string, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $3 
+		WHERE user_id = $1 AND client_id = $2 AND revoked_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, userID, clientID, at); err != nil {
+		return fmt.Errorf("failed to revoke client refresh tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RevocationStore struct {
+	db *pgxpool.Pool
+}
+
+func NewRevocationStore(db *pgxpool.Pool) *RevocationStore {
+	return &RevocationStore{db: db}
+}
+
+func (r *RevocationStore) RevokeToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, user_id, expires_at) 
+		VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`
+
+	if _, err := r.db.Exec(ctx, query, jti, userID, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	// Przy okazji sprzątamy wpisy, które i tak nie mają już znaczenia
+	if _, err := r.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < NOW()`); err != nil {
+		return fmt.Errorf("failed to purge expired revocations: %w", err)
+	}
+	return nil
+}
+
+func (r *RevocationStore) RevokeAllForUser(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	query := `INSERT INTO user_token_revocations (user_id, revoked_before) 
+		VALUES ($1, $2) 
+		ON CONFLICT (user_id) DO UPDATE 
+		SET revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before)`
+
+	if _, err := r.db.Exec(ctx, query, userID, before); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *RevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+
+	var revoked bool
+	if err := r.db.QueryRow(ctx, query, jti).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+// RevokedBefore zwraca granicę globalnego unieważnienia lub zerowy czas, jeśli jej nie ustawiono
+func (r *RevocationStore) RevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	query := `SELECT revoked_before FROM user_token_revocations WHERE user_id = $1`
+
+	var before time.Time
+	err := r.db.QueryRow(ctx, query, userID).Scan(&before)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get user revocation: %w", err)
+	}
+	return before, nil
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// Sesja jest aktywna, dopóki jej rodzina ma ważny token odświeżający - dzięki
+// temu wylogowanie, wykrycie wycieku czy LogoutAll kończą ją bez osobnego zapisu
+const activeSessionCondition = `EXISTS (SELECT 1 FROM refresh_tokens rt 
+	WHERE rt.family_id = s.id AND rt.revoked_at IS NULL AND rt.expires_at > $2)`
+
+type SessionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create zapisuje sesję i przy okazji usuwa zakończone sesje użytkownika
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	cleanup := `DELETE FROM sessions s WHERE s.user_id = $1 AND NOT ` + activeSessionCondition
+	if _, err := r.db.Exec(ctx, cleanup, session.UserID, session.CreatedAt); err != nil {
+		return fmt.Errorf("failed to delete ended sessions: %w", err)
+	}
+
+	query := `INSERT INTO sessions (id, user_id, ip, user_agent, created_at, last_seen_at) 
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.db.Exec(ctx, query,
+		session.ID,
+		session.UserID,
+		session.IP,
+		session.UserAgent,
+		session.CreatedAt,
+		session.LastSeenAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// ListActive zwraca aktywne sesje użytkownika, od ostatnio używanej
+func (r *SessionRepository) ListActive(ctx context.Context, userID uuid.UUID, now time.Time) ([]domain.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions s 
+		WHERE s.user_id = $1 AND ` + activeSessionCondition + ` 
+		ORDER BY s.last_seen_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Session, error) {
+		session, err := scanSession(row)
+		if err != nil {
+			return domain.Session{}, err
+		}
+		return *session, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) GetActive(ctx context.Context, userID, id uuid.UUID, now time.Time) (*domain.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions s 
+		WHERE s.user_id = $1 AND ` + activeSessionCondition + ` AND s.id = $3`
+
+	session, err := scanSession(r.db.QueryRow(ctx, query, userID, now, id))
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return session, err
+}
+
+// Touch zapisuje ostatnią aktywność sesji wraz z bieżącym adresem IP i przeglądarką
+func (r *SessionRepository) Touch(ctx context.Context, id uuid.UUID, client domain.SessionClient, at time.Time) error {
+	query := `UPDATE sessions SET ip = $2, user_agent = $3, last_seen_at = $4 WHERE id = $1`
+
+	if _, err := r.db.Exec(ctx, query, id, client.IP, client.UserAgent, at); err != nil {
+		return fmt.Errorf("failed to update session activity: %w", err)
+	}
+	return nil
+}
+
+const sessionColumns = `s.id, s.user_id, s.ip, s.user_agent, s.created_at, s.last_seen_at`
+
+func scanSession(row pgx.Row) (*domain.Session, error) {
+	var session domain.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.IP,
+		&session.UserAgent,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
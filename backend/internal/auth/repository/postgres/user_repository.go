@@ -5,9 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgconn"
+	"time"
 
 	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
-	_ "github.com/google/uuid"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -22,8 +23,8 @@ func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `INSERT INTO users 
-		(id, name, email, password_hash, location, bio, avatar_url, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+		(id, name, email, password_hash, location, bio, avatar_url, role, email_verified_at, created_at, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	_, err := r.db.Exec(ctx, query,
 		user.ID,
@@ -33,6 +34,8 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 		user.Location,
 		user.Bio,
 		user.AvatarURL,
+		user.Role,
+		user.EmailVerifiedAt,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -47,11 +50,88 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `SELECT id, name, email, password_hash, location, bio, avatar_url, rating, 
-		created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
 
+	user, err := scanUser(r.db.QueryRow(ctx, query, email))
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+	return user, err
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, id))
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	return user, err
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $2, updated_at = $3 WHERE id = $1`
+
+	tag, err := r.db.Exec(ctx, query, id, passwordHash, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, $2), updated_at = $2 
+		WHERE id = $1`
+
+	tag, err := r.db.Exec(ctx, query, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateEmail ustawia nowy, już potwierdzony adres email
+func (r *UserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) error {
+	query := `UPDATE users SET email = $2, email_verified_at = $3, updated_at = $3 WHERE id = $1`
+
+	tag, err := r.db.Exec(ctx, query, id, email, verifiedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return domain.ErrEmailExists
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) error {
+	query := `UPDATE users SET role = $2, updated_at = $3 WHERE id = $1`
+
+	tag, err := r.db.Exec(ctx, query, id, role, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+const userColumns = `id, name, email, password_hash, location, bio, avatar_url, rating, 
+	role, email_verified_at, created_at, updated_at`
+
+func scanUser(row pgx.Row) (*domain.User, error) {
 	var user domain.User
-	err := r.db.QueryRow(ctx, query, email).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
@@ -60,6 +140,8 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 		&user.Bio,
 		&user.AvatarURL,
 		&user.Rating,
+		&user.Role,
+		&user.EmailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -69,7 +151,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by email: %w", err)
+		return nil, err
 	}
 
 	return &user, nil
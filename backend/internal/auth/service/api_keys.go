@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+const (
+	// apiKeyPrefix pozwala rozpoznać klucz BookSwap np. w skanerach sekretów
+	apiKeyPrefix      = "bsk_"
+	maxAPIKeysPerUser = 20
+)
+
+// APIKeyRepository interfejs definiujący dostęp do kluczy API
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	CountActive(ctx context.Context, userID uuid.UUID, now time.Time) (int, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type APIKeyService struct {
+	repo APIKeyRepository
+}
+
+func NewAPIKeyService(repo APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		repo: repo,
+	}
+}
+
+// Create tworzy klucz API. Jawny klucz jest zwracany tylko tutaj - w bazie zostaje jego hash.
+func (s *APIKeyService) Create(ctx context.Context, userID uuid.UUID, req *domain.APIKeyCreate) (*domain.CreatedAPIKey, error) {
+	now := time.Now().UTC()
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !domain.IsKnownScope(scope) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidScope, scope)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, domain.ErrInvalidAPIKeyExpiry
+	}
+
+	count, err := s.repo.CountActive(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, domain.ErrAPIKeyLimitReached
+	}
+
+	prefix, err := randomHex(4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret, err := generateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plain := apiKeyPrefix + prefix + "_" + secret
+
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    apiKeyPrefix + prefix,
+		KeyHash:   hashToken(plain),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &domain.CreatedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+// List zwraca klucze użytkownika (bez jawnych wartości)
+func (s *APIKeyService) List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Revoke unieważnia klucz użytkownika
+func (s *APIKeyService) Revoke(ctx context.Context, userID, keyID uuid.UUID) error {
+	return s.repo.Revoke(ctx, userID, keyID, time.Now().UTC())
+}
+
+// ValidateAPIKey uwierzytelnia żądanie kluczem API i zapisuje czas jego użycia.
+// Weryfikacja adresu jest odczytywana z bieżącego stanu konta, a nie zamrażana
+// w chwili utworzenia klucza. Rola moderatora ani administratora nie przechodzi
+// na klucz.
+func (s *AuthService) ValidateAPIKey(ctx context.Context, plainKey string) (*domain.AccessClaims, error) {
+	claims, err := s.apiKeyClaims(ctx, plainKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Zapis czasu użycia nie może blokować żądania
+	if err := s.apiKeys.TouchLastUsed(ctx, *claims.APIKeyID, time.Now().UTC()); err != nil {
+		log.Printf("Nie udało się zapisać użycia klucza API %s: %v", claims.APIKeyID, err)
+	}
+	return claims, nil
+}
+
+// apiKeyClaims sprawdza klucz API bez zapisywania jego użycia - samo
+// sprawdzenie klucza (np. przy introspekcji) nie jest jego użyciem
+func (s *AuthService) apiKeyClaims(ctx context.Context, plainKey string) (*domain.AccessClaims, error) {
+	if !strings.HasPrefix(plainKey, apiKeyPrefix) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeys.GetByHash(ctx, hashToken(plainKey))
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !key.IsActive(now) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	// Klucz bez zakresów działa jak zwykła sesja
+	scopes := key.Scopes
+	if len(scopes) == 0 {
+		scopes = domain.AllScopes()
+	}
+
+	// Klucz bez daty wygaśnięcia ma zerowe ExpiresAt
+	var expiresAt time.Time
+	if key.ExpiresAt != nil {
+		expiresAt = *key.ExpiresAt
+	}
+
+	return &domain.AccessClaims{
+		TokenID:       "apikey:" + key.ID.String(),
+		UserID:        user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          domain.RoleUser,
+		EmailVerified: user.IsEmailVerified(),
+		IssuedAt:      key.CreatedAt,
+		ExpiresAt:     expiresAt,
+		APIKeyID:      &key.ID,
+		Scopes:        scopes,
+	}, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
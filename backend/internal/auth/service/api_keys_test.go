@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// newAPIKeyFixture tworzy użytkownika o podanej roli i jego klucz API
+func newAPIKeyFixture(t *testing.T, role domain.Role, scopes []string) (*AuthService, *fakeAPIKeyRepo, string) {
+	t.Helper()
+
+	verifiedAt := time.Now().UTC()
+	user := &domain.User{ID: uuid.New(), Name: "Ala", Email: "ala@example.com", Role: role, EmailVerifiedAt: &verifiedAt}
+	keys := newFakeAPIKeyRepo()
+
+	created, err := NewAPIKeyService(keys).Create(context.Background(), user.ID, &domain.APIKeyCreate{Name: "test", Scopes: scopes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &AuthService{userRepo: newFakeUserRepo(user), apiKeys: keys}, keys, created.Key
+}
+
+func TestValidateAPIKeyNeverCarriesPrivilegedRole(t *testing.T) {
+	for _, role := range []domain.Role{domain.RoleUser, domain.RoleModerator, domain.RoleAdmin} {
+		svc, _, key := newAPIKeyFixture(t, role, nil)
+
+		claims, err := svc.ValidateAPIKey(context.Background(), key)
+		if err != nil {
+			t.Fatalf("%s: %v", role, err)
+		}
+		if claims.Role != domain.RoleUser {
+			t.Errorf("api key of %s got role %q, want %q", role, claims.Role, domain.RoleUser)
+		}
+		if claims.APIKeyID == nil {
+			t.Errorf("%s: api key claims without APIKeyID", role)
+		}
+	}
+}
+
+func TestValidateAPIKeyScopes(t *testing.T) {
+	svc, _, key := newAPIKeyFixture(t, domain.RoleUser, []string{domain.ScopeBooksRead})
+
+	claims, err := svc.ValidateAPIKey(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claims.HasScope(domain.ScopeBooksRead) || claims.HasScope(domain.ScopeBooksWrite) {
+		t.Errorf("scopes = %v, want only %s", claims.Scopes, domain.ScopeBooksRead)
+	}
+
+	if _, err := svc.ValidateAPIKey(context.Background(), key+"x"); err != domain.ErrInvalidAPIKey {
+		t.Errorf("unknown key: err = %v, want %v", err, domain.ErrInvalidAPIKey)
+	}
+}
+
+func TestIntrospectDoesNotTouchAPIKey(t *testing.T) {
+	svc, keys, key := newAPIKeyFixture(t, domain.RoleUser, nil)
+
+	result, err := svc.Introspect(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Active || result.TokenType != "api_key" {
+		t.Errorf("introspection = %+v, want active api_key", result)
+	}
+	if keys.touches != 0 {
+		t.Errorf("introspection recorded %d key uses, want 0", keys.touches)
+	}
+
+	if _, err := svc.ValidateAPIKey(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	if keys.touches != 1 {
+		t.Errorf("validation recorded %d key uses, want 1", keys.touches)
+	}
+}
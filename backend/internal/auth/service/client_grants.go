@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// IssueClientTokens wystawia aplikacji OAuth parę tokenów działających w imieniu
+// użytkownika, ograniczoną do nadanych zakresów. Każda autoryzacja rozpoczyna
+// nową rodzinę tokenów odświeżających.
+func (s *AuthService) IssueClientTokens(ctx context.Context, userID uuid.UUID, clientID string, scopes []string) (*domain.TokenPair, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueGrantTokens(ctx, user, uuid.New(), clientID, scopes)
+}
+
+// RefreshClientTokens wymienia token odświeżający aplikacji. Pusta lista scopes
+// zachowuje nadane zakresy, niepusta może je tylko zawęzić.
+func (s *AuthService) RefreshClientTokens(ctx context.Context, refreshToken, clientID string, scopes []string) (*domain.GrantedTokens, error) {
+	return s.rotate(ctx, refreshToken, clientID, scopes)
+}
+
+// RevokeClientTokens unieważnia tokeny odświeżające aplikacji po cofnięciu zgody.
+// Wydane tokeny dostępowe wygasają same po accessTTL.
+func (s *AuthService) RevokeClientTokens(ctx context.Context, userID uuid.UUID, clientID string) error {
+	return s.refreshTokenRepo.RevokeForClient(ctx, userID, clientID, time.Now().UTC())
+}
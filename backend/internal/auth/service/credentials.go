@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// VerifyPassword ponownie uwierzytelnia zalogowanego użytkownika przed zmianą
+// danych logowania. Błędne hasła liczą się do blokady tak samo jak przy logowaniu,
+// więc przejęta sesja nie pozwala na zgadywanie hasła bez ograniczeń.
+func (s *AuthService) VerifyPassword(ctx context.Context, userID uuid.UUID, password, ip string) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loginGuard.Check(ctx, user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	if !user.HasPassword() {
+		return nil, domain.ErrPasswordNotSet
+	}
+
+	ok, _, err := s.passwords.Verify(password, user.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify password: %w", err)
+	}
+	if !ok {
+		if err := s.loginGuard.Fail(ctx, user.Email, ip); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidCurrentPassword
+	}
+
+	if err := s.loginGuard.Succeed(ctx, user.Email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword ustawia nowe hasło po podaniu obecnego i wylogowuje wszystkie
+// pozostałe sesje. Bieżący klient dostaje nową parę tokenów.
+func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req *domain.ChangePasswordRequest) (*domain.TokenPair, error) {
+	user, err := s.VerifyPassword(ctx, userID, req.CurrentPassword, req.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.passwordChecker.Check(req.NewPassword, user.Name, user.Email); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.passwords.Hash(req.NewPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.LogoutAll(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, domain.SessionClient{IP: req.IP, UserAgent: req.UserAgent})
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/mailer"
+)
+
+// Reauthenticator sprawdza hasło zalogowanego użytkownika (implementuje go AuthService)
+type Reauthenticator interface {
+	VerifyPassword(ctx context.Context, userID uuid.UUID, password, ip string) (*domain.User, error)
+}
+
+type EmailChangeService struct {
+	userRepo  UserRepository
+	tokenRepo OneTimeTokenRepository
+	mailer    mailer.Mailer
+	reauth    Reauthenticator
+	tokenTTL  time.Duration
+	baseURL   string
+}
+
+func NewEmailChangeService(
+	userRepo UserRepository,
+	tokenRepo OneTimeTokenRepository,
+	mail mailer.Mailer,
+	reauth Reauthenticator,
+	tokenTTL time.Duration,
+	baseURL string,
+) *EmailChangeService {
+	return &EmailChangeService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mail,
+		reauth:    reauth,
+		tokenTTL:  tokenTTL,
+		baseURL:   baseURL,
+	}
+}
+
+// RequestChange wysyła link potwierdzający na nowy adres. Adres w koncie
+// zmienia się dopiero po kliknięciu linku.
+func (s *EmailChangeService) RequestChange(ctx context.Context, userID uuid.UUID, req *domain.ChangeEmailRequest) error {
+	user, err := s.reauth.VerifyPassword(ctx, userID, req.Password, req.IP)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, req.NewEmail)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	if existing != nil {
+		return domain.ErrEmailExists
+	}
+
+	now := time.Now().UTC()
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, domain.PurposeEmailChange, now); err != nil {
+		return err
+	}
+
+	plain, err := generateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate email change token: %w", err)
+	}
+
+	token := &domain.OneTimeToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   domain.PurposeEmailChange,
+		TokenHash: hashToken(plain),
+		Payload:   req.NewEmail,
+		ExpiresAt: now.Add(s.tokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/confirm-email-change?token=%s", s.baseURL, url.QueryEscape(plain))
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      req.NewEmail,
+		Subject: "BookSwap - potwierdź nowy adres email",
+		Body: fmt.Sprintf(
+			"Cześć %s,\n\notrzymaliśmy prośbę o zmianę adresu email Twojego konta na ten adres.\n"+
+				"Aby potwierdzić zmianę, otwórz link:\n\n%s\n\n"+
+				"Link jest ważny przez %s i można go użyć tylko raz.\n"+
+				"Jeśli to nie Ty, zignoruj tę wiadomość.\n",
+			user.Name, link, s.tokenTTL,
+		),
+	})
+}
+
+// ConfirmChange podmienia adres na potwierdzony i powiadamia stary adres
+func (s *EmailChangeService) ConfirmChange(ctx context.Context, plainToken string) error {
+	now := time.Now().UTC()
+
+	token, err := s.tokenRepo.Consume(ctx, domain.PurposeEmailChange, hashToken(plainToken), now)
+	if err != nil {
+		if errors.Is(err, domain.ErrOneTimeTokenInvalid) {
+			return domain.ErrInvalidEmailChangeToken
+		}
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidEmailChangeToken
+		}
+		return err
+	}
+
+	// Adres mógł zostać zajęty między prośbą a potwierdzeniem - wtedy
+	// repozytorium zwraca domain.ErrEmailExists
+	if err := s.userRepo.UpdateEmail(ctx, user.ID, token.Payload, now); err != nil {
+		return err
+	}
+
+	// Zmiana już się dokonała, więc błąd wysyłki tylko logujemy
+	if err := s.notifyOldAddress(ctx, user, token.Payload); err != nil {
+		log.Printf("Nie udało się powiadomić %s o zmianie adresu email: %v", user.Email, err)
+	}
+	return nil
+}
+
+func (s *EmailChangeService) notifyOldAddress(ctx context.Context, user *domain.User, newEmail string) error {
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "BookSwap - adres email został zmieniony",
+		Body: fmt.Sprintf(
+			"Cześć %s,\n\nadres email Twojego konta został zmieniony na %s.\n"+
+				"Jeśli to nie Ty, natychmiast skontaktuj się z nami i zresetuj hasło.\n",
+			user.Name, newEmail,
+		),
+	})
+}
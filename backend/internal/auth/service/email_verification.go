@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/mailer"
+)
+
+type EmailVerificationService struct {
+	userRepo  UserRepository
+	tokenRepo OneTimeTokenRepository
+	mailer    mailer.Mailer
+	tokenTTL  time.Duration
+	baseURL   string
+}
+
+func NewEmailVerificationService(
+	userRepo UserRepository,
+	tokenRepo OneTimeTokenRepository,
+	mail mailer.Mailer,
+	tokenTTL time.Duration,
+	baseURL string,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mail,
+		tokenTTL:  tokenTTL,
+		baseURL:   baseURL,
+	}
+}
+
+// SendVerification wysyła link potwierdzający adres email, unieważniając wcześniejsze linki
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *domain.User) error {
+	now := time.Now().UTC()
+
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, domain.PurposeEmailVerification, now); err != nil {
+		return err
+	}
+
+	plain, err := generateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	token := &domain.OneTimeToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   domain.PurposeEmailVerification,
+		TokenHash: hashToken(plain),
+		ExpiresAt: now.Add(s.tokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.baseURL, url.QueryEscape(plain))
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "BookSwap - potwierdź adres email",
+		Body: fmt.Sprintf(
+			"Cześć %s,\n\ndziękujemy za rejestrację w BookSwap.\n"+
+				"Aby potwierdzić adres email, otwórz link:\n\n%s\n\n"+
+				"Link jest ważny przez %s.\n",
+			user.Name, link, s.tokenTTL,
+		),
+	})
+}
+
+// Resend ponownie wysyła link weryfikacyjny; dla potwierdzonego konta nic nie robi
+func (s *EmailVerificationService) Resend(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// Verify potwierdza adres email na podstawie tokenu z maila
+func (s *EmailVerificationService) Verify(ctx context.Context, plainToken string) error {
+	now := time.Now().UTC()
+
+	token, err := s.tokenRepo.Consume(ctx, domain.PurposeEmailVerification, hashToken(plainToken), now)
+	if err != nil {
+		if errors.Is(err, domain.ErrOneTimeTokenInvalid) {
+			return domain.ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, token.UserID, now); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidVerificationToken
+		}
+		return err
+	}
+	return nil
+}
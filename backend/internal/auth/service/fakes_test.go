@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/keys"
+)
+
+// fakeUserRepo przechowuje użytkowników w pamięci
+type fakeUserRepo struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]*domain.User
+}
+
+func newFakeUserRepo(users ...*domain.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: make(map[uuid.UUID]*domain.User)}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) Create(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) GetByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(_ context.Context, id uuid.UUID, passwordHash string) error {
+	return r.update(id, func(u *domain.User) { u.PasswordHash = passwordHash })
+}
+
+func (r *fakeUserRepo) MarkEmailVerified(_ context.Context, id uuid.UUID, at time.Time) error {
+	return r.update(id, func(u *domain.User) { u.EmailVerifiedAt = &at })
+}
+
+func (r *fakeUserRepo) UpdateEmail(_ context.Context, id uuid.UUID, email string, verifiedAt time.Time) error {
+	return r.update(id, func(u *domain.User) { u.Email, u.EmailVerifiedAt = email, &verifiedAt })
+}
+
+func (r *fakeUserRepo) UpdateRole(_ context.Context, id uuid.UUID, role domain.Role) error {
+	return r.update(id, func(u *domain.User) { u.Role = role })
+}
+
+func (r *fakeUserRepo) update(id uuid.UUID, change func(*domain.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	change(user)
+	return nil
+}
+
+// fakeRefreshTokenRepo przechowuje tokeny odświeżające w pamięci. Rotate
+// zachowuje się jak w Postgresie: drugi obrót tego samego tokenu zwraca
+// domain.ErrRefreshTokenReused.
+type fakeRefreshTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]*domain.RefreshToken
+	// beforeRotate pozwala zasymulować równoległy obrót tego samego tokenu
+	beforeRotate func(oldID uuid.UUID)
+}
+
+func newFakeRefreshTokenRepo() *fakeRefreshTokenRepo {
+	return &fakeRefreshTokenRepo{tokens: make(map[uuid.UUID]*domain.RefreshToken)}
+}
+
+func (r *fakeRefreshTokenRepo) Create(_ context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.ID] = token
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) GetByHash(_ context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrInvalidRefreshToken
+}
+
+func (r *fakeRefreshTokenRepo) Rotate(_ context.Context, oldID uuid.UUID, next *domain.RefreshToken) error {
+	if r.beforeRotate != nil {
+		r.beforeRotate(oldID)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.tokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return domain.ErrRefreshTokenReused
+	}
+	old.RevokedAt, old.ReplacedBy = &next.CreatedAt, &next.ID
+	r.tokens[next.ID] = next
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeFamily(_ context.Context, familyID uuid.UUID, at time.Time) error {
+	return r.revoke(at, func(t *domain.RefreshToken) bool { return t.FamilyID == familyID })
+}
+
+func (r *fakeRefreshTokenRepo) RevokeAllForUser(_ context.Context, userID uuid.UUID, at time.Time) error {
+	return r.revoke(at, func(t *domain.RefreshToken) bool { return t.UserID == userID })
+}
+
+func (r *fakeRefreshTokenRepo) RevokeForClient(_ context.Context, userID uuid.UUID, clientID string, at time.Time) error {
+	return r.revoke(at, func(t *domain.RefreshToken) bool { return t.UserID == userID && t.ClientID == clientID })
+}
+
+func (r *fakeRefreshTokenRepo) revoke(at time.Time, match func(*domain.RefreshToken) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+// active zwraca liczbę nieunieważnionych tokenów rodziny
+func (r *fakeRefreshTokenRepo) active(familyID uuid.UUID) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// fakeSessionRepo przechowuje sesje w pamięci i liczy zapisy aktywności
+type fakeSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*domain.Session
+	touches  int
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{sessions: make(map[uuid.UUID]*domain.Session)}
+}
+
+func (r *fakeSessionRepo) Create(_ context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *fakeSessionRepo) ListActive(_ context.Context, userID uuid.UUID, _ time.Time) ([]domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []domain.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepo) GetActive(_ context.Context, userID, id uuid.UUID, _ time.Time) (*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID {
+		return nil, domain.ErrSessionNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *fakeSessionRepo) Touch(_ context.Context, id uuid.UUID, client domain.SessionClient, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.touches++
+	if session, ok := r.sessions[id]; ok {
+		session.IP, session.UserAgent, session.LastSeenAt = client.IP, client.UserAgent, at
+	}
+	return nil
+}
+
+// fakeRevocationStore przechowuje unieważnienia tokenów dostępowych w pamięci
+type fakeRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]bool
+	before  map[uuid.UUID]time.Time
+}
+
+func newFakeRevocationStore() *fakeRevocationStore {
+	return &fakeRevocationStore{revoked: make(map[string]bool), before: make(map[uuid.UUID]time.Time)}
+}
+
+func (s *fakeRevocationStore) RevokeToken(_ context.Context, jti string, _ uuid.UUID, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[jti] = true
+	return nil
+}
+
+func (s *fakeRevocationStore) RevokeAllForUser(_ context.Context, userID uuid.UUID, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.before[userID] = before
+	return nil
+}
+
+func (s *fakeRevocationStore) IsTokenRevoked(_ context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revoked[jti], nil
+}
+
+func (s *fakeRevocationStore) RevokedBefore(_ context.Context, userID uuid.UUID) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.before[userID], nil
+}
+
+// fakeSigningKeys podpisuje tokeny jednym kluczem wygenerowanym na potrzeby testu
+type fakeSigningKeys struct {
+	key *keys.Key
+}
+
+func newFakeSigningKeys(t *testing.T) *fakeSigningKeys {
+	t.Helper()
+	key, err := keys.Generate("test", keys.AlgEdDSA, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeSigningKeys{key: key}
+}
+
+func (k *fakeSigningKeys) SigningKey() (*keys.Key, error) {
+	return k.key, nil
+}
+
+func (k *fakeSigningKeys) VerificationKey(kid string) (*keys.Key, bool) {
+	return k.key, kid == k.key.ID
+}
+
+// fakeAPIKeyRepo przechowuje klucze API w pamięci i liczy zapisy użycia
+type fakeAPIKeyRepo struct {
+	mu      sync.Mutex
+	keys    map[uuid.UUID]*domain.APIKey
+	touches int
+}
+
+func newFakeAPIKeyRepo() *fakeAPIKeyRepo {
+	return &fakeAPIKeyRepo{keys: make(map[uuid.UUID]*domain.APIKey)}
+}
+
+func (r *fakeAPIKeyRepo) Create(_ context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[key.ID] = key
+	return nil
+}
+
+func (r *fakeAPIKeyRepo) ListByUser(_ context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var keys []domain.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, *key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepo) GetByHash(_ context.Context, keyHash string) (*domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (r *fakeAPIKeyRepo) CountActive(_ context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, key := range r.keys {
+		if key.UserID == userID && key.IsActive(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeAPIKeyRepo) Revoke(_ context.Context, userID, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[id]
+	if !ok || key.UserID != userID {
+		return domain.ErrAPIKeyNotFound
+	}
+	key.RevokedAt = &at
+	return nil
+}
+
+func (r *fakeAPIKeyRepo) TouchLastUsed(_ context.Context, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.touches++
+	if key, ok := r.keys[id]; ok {
+		key.LastUsedAt = &at
+	}
+	return nil
+}
+
+// fakeIdentityRepo przechowuje powiązane konta zewnętrzne w pamięci
+type fakeIdentityRepo struct {
+	identities map[string]*domain.UserIdentity
+}
+
+func newFakeIdentityRepo() *fakeIdentityRepo {
+	return &fakeIdentityRepo{identities: make(map[string]*domain.UserIdentity)}
+}
+
+func (r *fakeIdentityRepo) Get(_ context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	identity, ok := r.identities[provider+"/"+subject]
+	if !ok {
+		return nil, domain.ErrIdentityNotFound
+	}
+	return identity, nil
+}
+
+func (r *fakeIdentityRepo) Create(_ context.Context, identity *domain.UserIdentity) error {
+	r.identities[identity.Provider+"/"+identity.Subject] = identity
+	return nil
+}
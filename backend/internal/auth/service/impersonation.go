@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// Domyślna liczba wpisów dziennika audytu na liście
+const defaultAuditLimit = 50
+
+// AuditRepository interfejs definiujący dostęp do dziennika audytu
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// ImpersonationService pozwala administratorom działać w imieniu użytkowników
+// (np. przy odtwarzaniu zgłoszonego problemu) i zapisuje każde takie działanie
+type ImpersonationService struct {
+	auth     *AuthService
+	userRepo UserRepository
+	audit    AuditRepository
+	tokenTTL time.Duration
+}
+
+func NewImpersonationService(
+	auth *AuthService,
+	userRepo UserRepository,
+	audit AuditRepository,
+	tokenTTL time.Duration,
+) *ImpersonationService {
+	return &ImpersonationService{
+		auth:     auth,
+		userRepo: userRepo,
+		audit:    audit,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// Start wystawia administratorowi token użytkownika. Wpis w dzienniku audytu
+// powstaje przed zwróceniem tokenu - bez niego token nie zostanie wydany.
+func (s *ImpersonationService) Start(ctx context.Context, actor *domain.AccessClaims, userID uuid.UUID, req *domain.ImpersonationRequest) (*domain.ImpersonationToken, error) {
+	if actor.UserID == userID {
+		return nil, domain.ErrCannotImpersonateSelf
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Token administratora dawałby dostęp do panelu bez jego własnego logowania
+	if user.Role == domain.RoleAdmin {
+		return nil, domain.ErrCannotImpersonateAdmin
+	}
+
+	now := time.Now().UTC()
+	readOnly := !req.AllowWrite
+
+	token, claims, err := s.auth.generateImpersonationToken(user, actor.UserID, readOnly, s.tokenTTL, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.Create(ctx, &domain.AuditEntry{
+		ID:        uuid.New(),
+		ActorID:   actor.UserID,
+		UserID:    user.ID,
+		Action:    domain.AuditImpersonationStart,
+		TokenID:   claims.TokenID,
+		ReadOnly:  readOnly,
+		Reason:    req.Reason,
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ImpersonationToken{
+		AccessToken: token,
+		ExpiresAt:   claims.ExpiresAt,
+		UserID:      user.ID,
+		ReadOnly:    readOnly,
+	}, nil
+}
+
+// RecordRequest zapisuje w dzienniku żądanie wykonane tokenem podszywania się
+func (s *ImpersonationService) RecordRequest(ctx context.Context, claims *domain.AccessClaims, entry *domain.AuditEntry) error {
+	if !claims.IsImpersonated() {
+		return fmt.Errorf("token is not an impersonation token")
+	}
+
+	entry.ID = uuid.New()
+	entry.ActorID = *claims.ActorID
+	entry.UserID = claims.UserID
+	entry.Action = domain.AuditImpersonationRequest
+	entry.TokenID = claims.TokenID
+	entry.ReadOnly = claims.ReadOnly
+	entry.CreatedAt = time.Now().UTC()
+
+	return s.audit.Create(ctx, entry)
+}
+
+// ListAudit zwraca wpisy dziennika audytu, od najnowszych
+func (s *ImpersonationService) ListAudit(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	return s.audit.List(ctx, filter)
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// Introspect zwraca stan tokenu dostępowego lub klucza API wraz z efektywnymi
+// zakresami (RFC 7662). Nieprawidłowy token to active=false, a nie błąd.
+// Uwierzytelnienie pytającego serwera zasobów należy do wywołującego
+// (zob. OAuthService.Introspect); sprawdzenie nie zmienia stanu klucza API.
+func (s *AuthService) Introspect(ctx context.Context, token string) (*domain.Introspection, error) {
+	var claims *domain.AccessClaims
+	var err error
+
+	tokenType := "access_token"
+	if strings.HasPrefix(token, apiKeyPrefix) {
+		tokenType = "api_key"
+		claims, err = s.apiKeyClaims(ctx, token)
+	} else {
+		claims, err = s.ValidateAccessToken(ctx, token)
+	}
+
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidToken) ||
+			errors.Is(err, domain.ErrInvalidAPIKey) ||
+			errors.Is(err, domain.ErrTokenRevoked) {
+			return &domain.Introspection{Active: false}, nil
+		}
+		return nil, err
+	}
+
+	result := &domain.Introspection{
+		Active:    true,
+		Scope:     domain.FormatScope(claims.Scopes),
+		ClientID:  claims.ClientID,
+		Subject:   claims.UserID.String(),
+		TokenType: tokenType,
+		TokenID:   claims.TokenID,
+		IssuedAt:  claims.IssuedAt.Unix(),
+	}
+	if claims.ActorID != nil {
+		result.Actor = &domain.Actor{Subject: claims.ActorID.String()}
+	}
+	if !claims.ExpiresAt.IsZero() {
+		result.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	return result, nil
+}
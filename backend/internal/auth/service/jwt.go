@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/keys"
+)
+
+// Rodzaje tokenów JWT (claim "typ") - token MFA nie może posłużyć jako token dostępowy
+const (
+	tokenTypeAccess = "access"
+	tokenTypeMFA    = "mfa_pending"
+)
+
+// ValidateAccessToken weryfikuje podpis i ważność tokenu dostępowego
+// oraz sprawdza, czy nie został on unieważniony po stronie serwera.
+func (s *AuthService) ValidateAccessToken(ctx context.Context, tokenString string) (*domain.AccessClaims, error) {
+	raw, err := s.parseToken(tokenString, tokenTypeAccess)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	claims, err := parseAccessClaims(raw)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if err := s.checkRevocation(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// parseMFAToken weryfikuje token z pierwszego kroku logowania
+func (s *AuthService) parseMFAToken(ctx context.Context, tokenString string) (*domain.AccessClaims, error) {
+	raw, err := s.parseToken(tokenString, tokenTypeMFA)
+	if err != nil {
+		return nil, domain.ErrInvalidMFAToken
+	}
+
+	claims, err := parseAccessClaims(raw)
+	if err != nil {
+		return nil, domain.ErrInvalidMFAToken
+	}
+
+	if err := s.checkRevocation(ctx, claims); err != nil {
+		if errors.Is(err, domain.ErrTokenRevoked) {
+			return nil, domain.ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (s *AuthService) checkRevocation(ctx context.Context, claims *domain.AccessClaims) error {
+	revokedBefore, err := s.revocations.RevokedBefore(ctx, claims.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	// iat ma dokładność sekundy, więc odrzucamy też tokeny z tej samej sekundy
+	// co unieważnienie - mogły zostać wystawione tuż przed nim
+	if !claims.IssuedAt.After(revokedBefore) {
+		return domain.ErrTokenRevoked
+	}
+
+	revoked, err := s.revocations.IsTokenRevoked(ctx, claims.TokenID)
+	if err != nil {
+		return fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return domain.ErrTokenRevoked
+	}
+
+	// Wylogowanie administratora ze wszystkich urządzeń lub odebranie mu roli
+	// kończy też tokeny, którymi działał w imieniu użytkowników
+	if claims.ActorID != nil {
+		actorRevokedBefore, err := s.revocations.RevokedBefore(ctx, *claims.ActorID)
+		if err != nil {
+			return fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if !claims.IssuedAt.After(actorRevokedBefore) {
+			return domain.ErrTokenRevoked
+		}
+	}
+
+	if claims.SessionID != nil {
+		revoked, err := s.revocations.IsTokenRevoked(ctx, sessionRevocationID(*claims.SessionID))
+		if err != nil {
+			return fmt.Errorf("failed to check session revocation: %w", err)
+		}
+		if revoked {
+			return domain.ErrTokenRevoked
+		}
+	}
+	return nil
+}
+
+func (s *AuthService) parseToken(tokenString, tokenType string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.signingKeys.VerificationKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("nieznany klucz podpisu: %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("nieoczekiwana metoda podpisu: %v", token.Header["alg"])
+		}
+		return key.Public, nil
+	},
+		jwt.WithValidMethods([]string{keys.AlgEdDSA, keys.AlgRS256}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("unexpected claims type %T", token.Claims)
+	}
+
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		return nil, fmt.Errorf("unexpected token type %q", typ)
+	}
+	return claims, nil
+}
+
+// generateAccessToken wystawia token dostępowy. Token sesji z logowania niesie
+// jej identyfikator (sid), a token aplikacji OAuth - identyfikator klienta.
+func (s *AuthService) generateAccessToken(user *domain.User, familyID uuid.UUID, clientID string, scopes []string, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(s.accessTTL)
+	claims := jwt.MapClaims{
+		"typ":            tokenTypeAccess,
+		"jti":            uuid.NewString(),
+		"sub":            user.ID.String(),
+		"iat":            now.Unix(),
+		"exp":            expiresAt.Unix(),
+		"name":           user.Name,
+		"email":          user.Email,
+		"email_verified": user.IsEmailVerified(),
+		"scope":          domain.FormatScope(scopes),
+	}
+	// Token aplikacji nie niesie roli - aplikacja działa z uprawnieniami
+	// zwykłego użytkownika, nawet gdy zgodę wydał moderator
+	if clientID != "" {
+		claims["client_id"] = clientID
+	} else {
+		claims["role"] = string(user.Role)
+		claims["sid"] = familyID.String()
+	}
+
+	token, err := s.signJWT(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// generateImpersonationToken wystawia token dostępowy użytkownika dla
+// administratora. Claim act (RFC 8693) wskazuje administratora, a token bez
+// zgody na zapis dostaje tylko zakresy do odczytu.
+func (s *AuthService) generateImpersonationToken(user *domain.User, actorID uuid.UUID, readOnly bool, ttl time.Duration, now time.Time) (string, *domain.AccessClaims, error) {
+	scopes := domain.AllScopes()
+	if readOnly {
+		scopes = domain.ReadScopes()
+	}
+
+	expiresAt := now.Add(ttl)
+	jti := uuid.NewString()
+	token, err := s.signJWT(jwt.MapClaims{
+		"typ":            tokenTypeAccess,
+		"jti":            jti,
+		"sub":            user.ID.String(),
+		"iat":            now.Unix(),
+		"exp":            expiresAt.Unix(),
+		"name":           user.Name,
+		"email":          user.Email,
+		"role":           string(user.Role),
+		"email_verified": user.IsEmailVerified(),
+		"scope":          domain.FormatScope(scopes),
+		"act":            map[string]string{"sub": actorID.String()},
+		"read_only":      readOnly,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+
+	return token, &domain.AccessClaims{
+		TokenID:   jti,
+		UserID:    user.ID,
+		IssuedAt:  now,
+		ExpiresAt: expiresAt,
+		Scopes:    scopes,
+		ActorID:   &actorID,
+		ReadOnly:  readOnly,
+	}, nil
+}
+
+// generateMFAToken wystawia krótkotrwały token potwierdzający, że hasło było poprawne
+func (s *AuthService) generateMFAToken(user *domain.User, now time.Time) (string, error) {
+	token, err := s.signJWT(jwt.MapClaims{
+		"typ": tokenTypeMFA,
+		"jti": uuid.NewString(),
+		"sub": user.ID.String(),
+		"iat": now.Unix(),
+		"exp": now.Add(mfaTokenTTL).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mfa token: %w", err)
+	}
+	return token, nil
+}
+
+// signJWT podpisuje token aktywnym kluczem, wskazując go w nagłówku kid
+func (s *AuthService) signJWT(claims jwt.MapClaims) (string, error) {
+	key, err := s.signingKeys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+func parseAccessClaims(claims jwt.MapClaims) (*domain.AccessClaims, error) {
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, fmt.Errorf("missing jti claim")
+	}
+
+	sub, err := claims.GetSubject()
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return nil, fmt.Errorf("missing iat claim")
+	}
+
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, fmt.Errorf("missing exp claim")
+	}
+
+	name, _ := claims["name"].(string)
+	email, _ := claims["email"].(string)
+	emailVerified, _ := claims["email_verified"].(bool)
+	clientID, _ := claims["client_id"].(string)
+
+	var sessionID *uuid.UUID
+	if sid, ok := claims["sid"].(string); ok {
+		id, err := uuid.Parse(sid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sid claim: %w", err)
+		}
+		sessionID = &id
+	}
+
+	var actorID *uuid.UUID
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		actorSub, _ := act["sub"].(string)
+		id, err := uuid.Parse(actorSub)
+		if err != nil {
+			return nil, fmt.Errorf("invalid act claim: %w", err)
+		}
+		actorID = &id
+	}
+	readOnly, _ := claims["read_only"].(bool)
+
+	roleClaim, _ := claims["role"].(string)
+
+	role := domain.Role(roleClaim)
+	if !role.IsValid() {
+		role = domain.RoleUser
+	}
+
+	// Tokeny wystawione przed wprowadzeniem zakresów dawały pełny dostęp
+	scopes := domain.AllScopes()
+	if scopeClaim, ok := claims["scope"].(string); ok {
+		scopes = domain.ParseScope(scopeClaim)
+	}
+
+	return &domain.AccessClaims{
+		TokenID:       jti,
+		UserID:        userID,
+		Name:          name,
+		Email:         email,
+		Role:          role,
+		EmailVerified: emailVerified,
+		IssuedAt:      issuedAt.Time,
+		ExpiresAt:     expiresAt.Time,
+		ClientID:      clientID,
+		SessionID:     sessionID,
+		Scopes:        scopes,
+		ActorID:       actorID,
+		ReadOnly:      readOnly,
+	}, nil
+}
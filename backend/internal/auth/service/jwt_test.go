@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+func TestClientAccessTokenCarriesNoRole(t *testing.T) {
+	svc := &AuthService{signingKeys: newFakeSigningKeys(t), accessTTL: 15 * time.Minute}
+	moderator := &domain.User{ID: uuid.New(), Email: "mod@example.com", Name: "Mod", Role: domain.RoleModerator}
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		clientID string
+		wantRole domain.Role
+	}{
+		{"session token", "", domain.RoleModerator},
+		{"oauth client token", "bsc_app", domain.RoleUser},
+	}
+	for _, tt := range tests {
+		token, _, err := svc.generateAccessToken(moderator, uuid.New(), tt.clientID, domain.AllScopes(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := svc.parseToken(token, tokenTypeAccess)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := raw["role"]; ok == (tt.clientID != "") {
+			t.Errorf("%s: role claim present = %v", tt.name, ok)
+		}
+
+		claims, err := parseAccessClaims(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.Role != tt.wantRole {
+			t.Errorf("%s: role = %s, want %s", tt.name, claims.Role, tt.wantRole)
+		}
+	}
+}
+
+func TestLogoutAllRevokesTokensIssuedInTheSameSecond(t *testing.T) {
+	f := newTokenFixture(t)
+	pair, _ := f.login(t)
+
+	if err := f.svc.LogoutAll(context.Background(), f.user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.ValidateAccessToken(context.Background(), pair.AccessToken); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("access token: err = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := f.refresh(pair.RefreshToken); err == nil {
+		t.Error("refresh token works after logout from all devices")
+	}
+}
+
+func TestCheckRevocationBoundary(t *testing.T) {
+	f := newTokenFixture(t)
+	before := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	if err := f.revocations.RevokeAllForUser(context.Background(), f.user.ID, before); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		revoked  bool
+	}{
+		{"earlier second", before.Add(-time.Second), true},
+		{"same second", before, true},
+		{"next second", before.Add(time.Second), false},
+	}
+	for _, tt := range tests {
+		err := f.svc.checkRevocation(context.Background(), &domain.AccessClaims{TokenID: uuid.NewString(), UserID: f.user.ID, IssuedAt: tt.issuedAt})
+		if revoked := errors.Is(err, domain.ErrTokenRevoked); revoked != tt.revoked {
+			t.Errorf("%s: err = %v, want revoked %v", tt.name, err, tt.revoked)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// LoginAttemptStore interfejs magazynu liczników nieudanych logowań
+type LoginAttemptStore interface {
+	Get(ctx context.Context, key string) (domain.LoginAttempts, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, policy domain.LockoutPolicy) (domain.LoginAttempts, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginGuard chroni logowanie przed zgadywaniem haseł, licząc porażki
+// osobno dla konta i dla adresu IP
+type LoginGuard struct {
+	store         LoginAttemptStore
+	accountPolicy domain.LockoutPolicy
+	ipPolicy      domain.LockoutPolicy
+}
+
+func NewLoginGuard(store LoginAttemptStore, accountPolicy, ipPolicy domain.LockoutPolicy) *LoginGuard {
+	return &LoginGuard{
+		store:         store,
+		accountPolicy: accountPolicy,
+		ipPolicy:      ipPolicy,
+	}
+}
+
+// Check zwraca *domain.TooManyAttemptsError, jeśli konto lub adres IP są zablokowane
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) error {
+	now := time.Now().UTC()
+
+	var retryAfter time.Duration
+	for _, key := range g.keys(email, ip) {
+		state, err := g.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if d := state.RetryAfter(now); d > retryAfter {
+			retryAfter = d
+		}
+	}
+
+	if retryAfter > 0 {
+		return &domain.TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Fail rejestruje nieudaną próbę logowania
+func (g *LoginGuard) Fail(ctx context.Context, email, ip string) error {
+	now := time.Now().UTC()
+
+	if _, err := g.store.RecordFailure(ctx, accountKey(email), now, g.accountPolicy); err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return nil
+	}
+	_, err := g.store.RecordFailure(ctx, ipKey(ip), now, g.ipPolicy)
+	return err
+}
+
+// Succeed zeruje licznik konta. Licznika IP celowo nie zerujemy - inaczej
+// atakujący mógłby go kasować, logując się co jakiś czas na własne konto.
+func (g *LoginGuard) Succeed(ctx context.Context, email string) error {
+	return g.store.Reset(ctx, accountKey(email))
+}
+
+func (g *LoginGuard) keys(email, ip string) []string {
+	keys := []string{accountKey(email)}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	return keys
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/totp"
+)
+
+const (
+	mfaTokenTTL       = 5 * time.Minute
+	recoveryCodeCount = 10
+	// Tolerancja rozjechanego zegara: akceptujemy kod z poprzedniego i następnego okna
+	totpSkew = 1
+)
+
+// MFARepository interfejs definiujący dostęp do konfiguracji MFA
+type MFARepository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*domain.MFA, error)
+	SavePending(ctx context.Context, mfa *domain.MFA) error
+	Confirm(ctx context.Context, userID uuid.UUID, step int64, at time.Time, codes []domain.RecoveryCode) error
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, at time.Time) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []domain.RecoveryCode) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+type MFAService struct {
+	mfaRepo  MFARepository
+	userRepo UserRepository
+	auth     *AuthService
+	secrets  *SecretBox
+	issuer   string
+	options  totp.Options
+}
+
+func NewMFAService(
+	mfaRepo MFARepository,
+	userRepo UserRepository,
+	auth *AuthService,
+	secrets *SecretBox,
+	issuer string,
+) *MFAService {
+	return &MFAService{
+		mfaRepo:  mfaRepo,
+		userRepo: userRepo,
+		auth:     auth,
+		secrets:  secrets,
+		issuer:   issuer,
+		options:  totp.DefaultOptions,
+	}
+}
+
+// BeginEnrollment generuje nowy sekret TOTP. MFA zaczyna działać dopiero po ConfirmEnrollment.
+func (s *MFAService) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*domain.MFAEnrollment, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+
+	encrypted, err := s.secrets.Seal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt totp secret: %w", err)
+	}
+
+	err = s.mfaRepo.SavePending(ctx, &domain.MFA{
+		UserID:          userID,
+		SecretEncrypted: encrypted,
+		CreatedAt:       time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.MFAEnrollment{
+		Secret:     secret,
+		OtpauthURI: s.options.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment włącza MFA po podaniu poprawnego kodu i zwraca kody awaryjne
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.IsEnabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := s.secrets.Open(mfa.SecretEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+
+	step, ok := s.options.Validate(secret, normalizeCode(code), time.Now(), totpSkew)
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+
+	plain, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.Confirm(ctx, userID, int64(step), time.Now().UTC(), codes); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+// Disable wyłącza MFA; wymaga aktualnego kodu lub kodu awaryjnego
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	mfa, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifyCode(ctx, mfa, code); err != nil {
+		return err
+	}
+
+	return s.mfaRepo.Delete(ctx, userID)
+}
+
+// RegenerateRecoveryCodes unieważnia stare kody awaryjne i zwraca nowe
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyCode(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+
+	plain, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+// CompleteLogin wymienia token MFA z pierwszego kroku logowania i kod na właściwe tokeny.
+// Błędne kody liczą się jako nieudane próby logowania.
+func (s *MFAService) CompleteLogin(ctx context.Context, req *domain.MFAVerifyRequest) (*domain.LoginResult, error) {
+	pending, err := s.auth.parseMFAToken(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, pending.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+
+	if err := s.auth.loginGuard.Check(ctx, user.Email, req.IP); err != nil {
+		return nil, err
+	}
+
+	mfa, err := s.enabledMFA(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyCode(ctx, mfa, req.Code); err != nil {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			if err := s.auth.loginGuard.Fail(ctx, user.Email, req.IP); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.auth.loginGuard.Succeed(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	// Token MFA jest jednorazowy
+	if err := s.auth.revocations.RevokeToken(ctx, pending.TokenID, user.ID, pending.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.auth.startSession(ctx, user, domain.SessionClient{IP: req.IP, UserAgent: req.UserAgent})
+	if err != nil {
+		return nil, err
+	}
+
+	user.PasswordHash = ""
+	return &domain.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+func (s *MFAService) enabledMFA(ctx context.Context, userID uuid.UUID) (*domain.MFA, error) {
+	mfa, err := s.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return nil, domain.ErrMFANotEnabled
+		}
+		return nil, err
+	}
+	if !mfa.IsEnabled() {
+		return nil, domain.ErrMFANotEnabled
+	}
+	return mfa, nil
+}
+
+// verifyCode akceptuje kod TOTP (każde okno tylko raz) albo niewykorzystany kod awaryjny
+func (s *MFAService) verifyCode(ctx context.Context, mfa *domain.MFA, code string) error {
+	code = normalizeCode(code)
+
+	if len(code) == s.options.Digits {
+		secret, err := s.secrets.Open(mfa.SecretEncrypted)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt totp secret: %w", err)
+		}
+
+		step, ok := s.options.Validate(secret, code, time.Now(), totpSkew)
+		if !ok {
+			return domain.ErrInvalidMFACode
+		}
+		return s.mfaRepo.UseStep(ctx, mfa.UserID, int64(step))
+	}
+
+	return s.mfaRepo.UseRecoveryCode(ctx, mfa.UserID, hashToken(code), time.Now().UTC())
+}
+
+// newRecoveryCodes generuje kody awaryjne w formacie XXXXX-XXXXX
+func newRecoveryCodes(userID uuid.UUID) ([]string, []domain.RecoveryCode, error) {
+	now := time.Now().UTC()
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	plain := make([]string, 0, recoveryCodeCount)
+	codes := make([]domain.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := encoding.EncodeToString(b)[:10]
+
+		plain = append(plain, code[:5]+"-"+code[5:])
+		codes = append(codes, domain.RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  hashToken(code),
+			CreatedAt: now,
+		})
+	}
+	return plain, codes, nil
+}
+
+// normalizeCode usuwa separatory i spacje, które użytkownicy często przepisują
+func normalizeCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/oidc"
+)
+
+const maxUserNameLength = 100
+
+// OIDCStateRepository interfejs definiujący dostęp do rozpoczętych logowań OIDC
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *domain.OIDCState) error
+	Consume(ctx context.Context, stateHash string, now time.Time) (*domain.OIDCState, error)
+}
+
+// IdentityRepository interfejs definiujący dostęp do powiązanych kont zewnętrznych
+type IdentityRepository interface {
+	Get(ctx context.Context, provider, subject string) (*domain.UserIdentity, error)
+	Create(ctx context.Context, identity *domain.UserIdentity) error
+}
+
+// OIDCService obsługuje logowanie przez zewnętrznych dostawców OpenID Connect
+type OIDCService struct {
+	auth        *AuthService
+	providers   map[string]*oidc.Provider
+	states      OIDCStateRepository
+	identities  IdentityRepository
+	redirectURL string
+	stateTTL    time.Duration
+}
+
+func NewOIDCService(auth *AuthService, providers []*oidc.Provider, states OIDCStateRepository, identities IdentityRepository, redirectURL string, stateTTL time.Duration) *OIDCService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	return &OIDCService{
+		auth:        auth,
+		providers:   byName,
+		states:      states,
+		identities:  identities,
+		redirectURL: redirectURL,
+		stateTTL:    stateTTL,
+	}
+}
+
+// Providers zwraca nazwy skonfigurowanych dostawców
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start rozpoczyna logowanie u dostawcy: zapamiętuje state, nonce i PKCE
+// i zwraca adres, na który frontend przekierowuje użytkownika
+func (s *OIDCService) Start(ctx context.Context, providerName string) (*domain.OIDCStart, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, domain.ErrOIDCProviderNotFound
+	}
+
+	state, err := generateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := generateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, err := generateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	authURL, err := provider.AuthCodeURL(ctx, s.redirectURL, state, nonce,
+		base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		log.Printf("OIDC %s: %v", providerName, err)
+		return nil, domain.ErrOIDCLoginFailed
+	}
+
+	now := time.Now().UTC()
+	if err := s.states.Create(ctx, &domain.OIDCState{
+		StateHash:    hashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(s.stateTTL),
+		CreatedAt:    now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &domain.OIDCStart{AuthorizationURL: authURL, State: state}, nil
+}
+
+// Callback kończy logowanie: wymienia kod na ID token, odnajduje lub zakłada
+// konto i wystawia tokeny (albo token MFA, jeśli konto ma drugi składnik)
+func (s *OIDCService) Callback(ctx context.Context, req *domain.OIDCCallbackRequest) (*domain.LoginResult, error) {
+	state, err := s.states.Consume(ctx, hashToken(req.State), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	provider, ok := s.providers[state.Provider]
+	if !ok {
+		return nil, domain.ErrOIDCProviderNotFound
+	}
+
+	claims, err := provider.Exchange(ctx, req.Code, state.CodeVerifier, s.redirectURL, state.Nonce)
+	if err != nil {
+		log.Printf("OIDC %s: %v", state.Provider, err)
+		return nil, domain.ErrOIDCLoginFailed
+	}
+
+	user, err := s.resolveUser(ctx, state.Provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.auth.loginWithIdentity(ctx, user, domain.SessionClient{IP: req.IP, UserAgent: req.UserAgent})
+}
+
+// resolveUser zwraca użytkownika powiązanego z kontem u dostawcy. Nowe konto
+// zewnętrzne łączymy z istniejącym tylko po zweryfikowanym po obu stronach
+// adresie - inaczej ktoś mógłby założyć konto na cudzy adres i przejąć
+// późniejsze logowania jego właściciela.
+func (s *OIDCService) resolveUser(ctx context.Context, providerName string, claims *oidc.Claims) (*domain.User, error) {
+	identity, err := s.identities.Get(ctx, providerName, claims.Subject)
+	if err == nil {
+		return s.auth.userRepo.GetByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, domain.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, domain.ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.auth.userRepo.GetByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		if !user.IsEmailVerified() {
+			return nil, domain.ErrIdentityConflict
+		}
+	case errors.Is(err, domain.ErrUserNotFound):
+		user, err = s.createUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if err := s.identities.Create(ctx, &domain.UserIdentity{
+		Provider:  providerName,
+		Subject:   claims.Subject,
+		UserID:    user.ID,
+		Email:     claims.Email,
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createUser zakłada konto bez hasła - użytkownik może je ustawić resetem hasła
+func (s *OIDCService) createUser(ctx context.Context, claims *oidc.Claims) (*domain.User, error) {
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	for utf8.RuneCountInString(name) > maxUserNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	now := time.Now().UTC()
+	user := &domain.User{
+		ID:              uuid.New(),
+		Name:            name,
+		Email:           claims.Email,
+		Role:            domain.RoleUser,
+		EmailVerifiedAt: &now, // adres potwierdził dostawca
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.auth.userRepo.Create(ctx, user); err != nil {
+		if errors.Is(err, domain.ErrEmailExists) {
+			// Ktoś równolegle założył konto na ten adres
+			return nil, domain.ErrIdentityConflict
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// loginWithIdentity kończy logowanie użytkownika uwierzytelnionego przez
+// zewnętrznego dostawcę. Drugi składnik obowiązuje tak samo jak przy haśle.
+func (s *AuthService) loginWithIdentity(ctx context.Context, user *domain.User, client domain.SessionClient) (*domain.LoginResult, error) {
+	mfa, err := s.mfaRepo.Get(ctx, user.ID)
+	if err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+		return nil, err
+	}
+
+	if mfa.IsEnabled() {
+		mfaToken, err := s.generateMFAToken(user, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LoginResult{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	tokens, err := s.startSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	user.PasswordHash = ""
+	return &domain.LoginResult{User: user, Tokens: tokens}, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/oidc"
+)
+
+func TestResolveUserLinksOnlyVerifiedEmails(t *testing.T) {
+	verifiedAt := time.Now()
+	verified := &domain.User{ID: uuid.New(), Email: "verified@example.com", Role: domain.RoleUser, EmailVerifiedAt: &verifiedAt}
+	unverified := &domain.User{ID: uuid.New(), Email: "unverified@example.com", Role: domain.RoleUser}
+
+	tests := []struct {
+		name     string
+		claims   oidc.Claims
+		wantUser uuid.UUID // uuid.Nil oznacza nowe konto
+		wantErr  error
+	}{
+		{"verified on both sides", oidc.Claims{Subject: "s1", Email: verified.Email, EmailVerified: true}, verified.ID, nil},
+		{"not verified by provider", oidc.Claims{Subject: "s2", Email: verified.Email}, uuid.Nil, domain.ErrOIDCEmailNotVerified},
+		{"not verified locally", oidc.Claims{Subject: "s3", Email: unverified.Email, EmailVerified: true}, uuid.Nil, domain.ErrIdentityConflict},
+		{"no email", oidc.Claims{Subject: "s4", EmailVerified: true}, uuid.Nil, domain.ErrOIDCEmailNotVerified},
+		{"new account", oidc.Claims{Subject: "s5", Email: "new@example.com", EmailVerified: true, Name: "Nowy"}, uuid.Nil, nil},
+	}
+
+	for _, tt := range tests {
+		identities := newFakeIdentityRepo()
+		svc := &OIDCService{
+			auth:       &AuthService{userRepo: newFakeUserRepo(verified, unverified)},
+			identities: identities,
+		}
+
+		user, err := svc.resolveUser(context.Background(), "test", &tt.claims)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			if len(identities.identities) != 0 {
+				t.Errorf("%s: identity linked despite error", tt.name)
+			}
+			continue
+		}
+
+		if tt.wantUser != uuid.Nil && user.ID != tt.wantUser {
+			t.Errorf("%s: resolved user %s, want %s", tt.name, user.ID, tt.wantUser)
+		}
+		if tt.wantUser == uuid.Nil && (user.ID == verified.ID || user.ID == unverified.ID || !user.IsEmailVerified()) {
+			t.Errorf("%s: got %+v, want new verified account", tt.name, user)
+		}
+		if identity, _ := identities.Get(context.Background(), "test", tt.claims.Subject); identity == nil || identity.UserID != user.ID {
+			t.Errorf("%s: identity not linked to resolved user", tt.name)
+		}
+	}
+}
+
+func TestResolveUserUsesExistingIdentity(t *testing.T) {
+	// Po powiązaniu konta adres u dostawcy nie ma już znaczenia
+	user := &domain.User{ID: uuid.New(), Email: "jan@example.com", Role: domain.RoleUser}
+	identities := newFakeIdentityRepo()
+	identities.Create(context.Background(), &domain.UserIdentity{Provider: "test", Subject: "s1", UserID: user.ID})
+
+	svc := &OIDCService{auth: &AuthService{userRepo: newFakeUserRepo(user)}, identities: identities}
+	got, err := svc.resolveUser(context.Background(), "test", &oidc.Claims{Subject: "s1", Email: "changed@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("resolved user %s, want %s", got.ID, user.ID)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// BreachedPasswords sprawdza hasła w bazie wycieków (implementuje go password.BreachedList)
+type BreachedPasswords interface {
+	Contains(password string) (bool, error)
+}
+
+// PasswordChecker pilnuje polityki haseł przy rejestracji, zmianie i resecie hasła
+type PasswordChecker struct {
+	policy   domain.PasswordPolicy
+	breached BreachedPasswords
+}
+
+// NewPasswordChecker tworzy walidator haseł; breached może być nil, jeśli
+// lokalna baza wycieków nie jest skonfigurowana
+func NewPasswordChecker(policy domain.PasswordPolicy, breached BreachedPasswords) *PasswordChecker {
+	return &PasswordChecker{
+		policy:   policy,
+		breached: breached,
+	}
+}
+
+// Check zwraca *domain.PasswordPolicyError, jeśli hasło narusza politykę.
+// personal to imię i email użytkownika.
+func (c *PasswordChecker) Check(password string, personal ...string) error {
+	if err := c.policy.Validate(password, personal...); err != nil {
+		return err
+	}
+
+	if c.breached == nil {
+		return nil
+	}
+
+	breached, err := c.breached.Contains(password)
+	if err != nil {
+		return fmt.Errorf("failed to check breached passwords: %w", err)
+	}
+	if breached {
+		return &domain.PasswordPolicyError{Rule: domain.PasswordBreached}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/mailer"
+)
+
+// OneTimeTokenRepository interfejs definiujący dostęp do tokenów jednorazowych
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, token *domain.OneTimeToken) error
+	Peek(ctx context.Context, purpose domain.TokenPurpose, tokenHash string, now time.Time) (*domain.OneTimeToken, error)
+	Consume(ctx context.Context, purpose domain.TokenPurpose, tokenHash string, now time.Time) (*domain.OneTimeToken, error)
+	InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose, now time.Time) error
+}
+
+// SessionRevoker unieważnia wszystkie sesje użytkownika (implementuje go AuthService)
+type SessionRevoker interface {
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+}
+
+type PasswordResetService struct {
+	userRepo  UserRepository
+	tokenRepo OneTimeTokenRepository
+	mailer    mailer.Mailer
+	sessions  SessionRevoker
+	passwords PasswordHasher
+	checker   *PasswordChecker
+	tokenTTL  time.Duration
+	baseURL   string
+}
+
+func NewPasswordResetService(
+	userRepo UserRepository,
+	tokenRepo OneTimeTokenRepository,
+	mail mailer.Mailer,
+	sessions SessionRevoker,
+	passwords PasswordHasher,
+	checker *PasswordChecker,
+	tokenTTL time.Duration,
+	baseURL string,
+) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mail,
+		sessions:  sessions,
+		passwords: passwords,
+		checker:   checker,
+		tokenTTL:  tokenTTL,
+		baseURL:   baseURL,
+	}
+}
+
+// RequestReset wysyła link do resetu hasła. Dla nieznanego adresu nic nie robi
+// i nie zwraca błędu, żeby nie ujawniać, czy konto istnieje.
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	// Ważny jest tylko najnowszy link
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, domain.PurposePasswordReset, now); err != nil {
+		return err
+	}
+
+	plain, err := generateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	token := &domain.OneTimeToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   domain.PurposePasswordReset,
+		TokenHash: hashToken(plain),
+		ExpiresAt: now.Add(s.tokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.baseURL, url.QueryEscape(plain))
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "BookSwap - reset hasła",
+		Body: fmt.Sprintf(
+			"Cześć %s,\n\notrzymaliśmy prośbę o zresetowanie hasła do Twojego konta.\n"+
+				"Aby ustawić nowe hasło, otwórz link:\n\n%s\n\n"+
+				"Link jest ważny przez %s i można go użyć tylko raz.\n"+
+				"Jeśli to nie Ty prosiłeś o reset, zignoruj tę wiadomość.\n",
+			user.Name, link, s.tokenTTL,
+		),
+	})
+}
+
+// ResetPassword ustawia nowe hasło na podstawie tokenu i wylogowuje wszystkie sesje.
+// Hasło niezgodne z polityką nie zużywa tokenu - użytkownik może poprawić je
+// bez zamawiania nowego linku.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error {
+	tokenHash := hashToken(req.Token)
+
+	pending, err := s.tokenRepo.Peek(ctx, domain.PurposePasswordReset, tokenHash, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, domain.ErrOneTimeTokenInvalid) {
+			return domain.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, pending.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err := s.checker.Check(req.Password, user.Name, user.Email); err != nil {
+		return err
+	}
+
+	token, err := s.tokenRepo.Consume(ctx, domain.PurposePasswordReset, tokenHash, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, domain.ErrOneTimeTokenInvalid) {
+			return domain.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	hashedPassword, err := s.passwords.Hash(req.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, token.UserID, hashedPassword); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	return s.sessions.LogoutAll(ctx, token.UserID)
+}
@@ -4,39 +4,127 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/golang-jwt/jwt/v5"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 
 	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/keys"
 )
 
 type AuthService struct {
-	userRepo  UserRepository
-	jwtSecret string
+	userRepo         UserRepository
+	refreshTokenRepo RefreshTokenRepository
+	sessions         SessionRepository
+	revocations      RevocationStore
+	verifier         EmailVerifier
+	loginGuard       *LoginGuard
+	mfaRepo          MFARepository
+	apiKeys          APIKeyRepository
+	passwords        PasswordHasher
+	passwordChecker  *PasswordChecker
+	signingKeys      SigningKeys
+	accessTTL        time.Duration
+	refreshTTL       time.Duration
+	activity         activityThrottle
 }
 
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID, at time.Time) error
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) error
+	UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) error
 }
 
-func NewAuthService(userRepo UserRepository, jwtSecret string) *AuthService {
+// RefreshTokenRepository interfejs definiujący dostęp do tokenów odświeżających
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	Rotate(ctx context.Context, oldID uuid.UUID, next *domain.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error
+	RevokeForClient(ctx context.Context, userID uuid.UUID, clientID string, at time.Time) error
+}
+
+// SessionRepository interfejs definiujący dostęp do sesji użytkowników
+type SessionRepository interface {
+	Create(ctx context.Context, session *domain.Session) error
+	ListActive(ctx context.Context, userID uuid.UUID, now time.Time) ([]domain.Session, error)
+	GetActive(ctx context.Context, userID, id uuid.UUID, now time.Time) (*domain.Session, error)
+	Touch(ctx context.Context, id uuid.UUID, client domain.SessionClient, at time.Time) error
+}
+
+// RevocationStore interfejs magazynu unieważnionych tokenów dostępowych
+type RevocationStore interface {
+	RevokeToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, before time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	RevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error)
+}
+
+// PasswordHasher haszuje i weryfikuje hasła (implementuje go password.Hasher)
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (ok, rehash bool, err error)
+}
+
+// SigningKeys dostarcza klucze do podpisywania i weryfikacji tokenów JWT
+type SigningKeys interface {
+	SigningKey() (*keys.Key, error)
+	VerificationKey(kid string) (*keys.Key, bool)
+}
+
+// EmailVerifier wysyła link weryfikacyjny do nowo zarejestrowanego użytkownika
+type EmailVerifier interface {
+	SendVerification(ctx context.Context, user *domain.User) error
+}
+
+func NewAuthService(
+	userRepo UserRepository,
+	refreshTokenRepo RefreshTokenRepository,
+	sessions SessionRepository,
+	revocations RevocationStore,
+	verifier EmailVerifier,
+	loginGuard *LoginGuard,
+	mfaRepo MFARepository,
+	apiKeys APIKeyRepository,
+	passwords PasswordHasher,
+	passwordChecker *PasswordChecker,
+	signingKeys SigningKeys,
+	accessTTL, refreshTTL time.Duration,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessions:         sessions,
+		revocations:      revocations,
+		verifier:         verifier,
+		loginGuard:       loginGuard,
+		mfaRepo:          mfaRepo,
+		apiKeys:          apiKeys,
+		passwords:        passwords,
+		passwordChecker:  passwordChecker,
+		signingKeys:      signingKeys,
+		accessTTL:        accessTTL,
+		refreshTTL:       refreshTTL,
 	}
 }
 
 func (s *AuthService) Register(ctx context.Context, req *domain.UserRegister) (*domain.User, error) {
+	if err := s.passwordChecker.Check(req.Password, req.Name, req.Email); err != nil {
+		return nil, err
+	}
+
 	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
 		return nil, domain.ErrEmailExists
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwords.Hash(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -46,8 +134,9 @@ func (s *AuthService) Register(ctx context.Context, req *domain.UserRegister) (*
 		ID:           uuid.New(),
 		Name:         req.Name,
 		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Location:     req.Location,
+		Role:         domain.RoleUser,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
@@ -56,46 +145,332 @@ func (s *AuthService) Register(ctx context.Context, req *domain.UserRegister) (*
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// Konto już istnieje, więc błąd wysyłki nie przerywa rejestracji -
+	// użytkownik może poprosić o ponowne wysłanie linku
+	if err := s.verifier.SendVerification(ctx, user); err != nil {
+		log.Printf("Nie udało się wysłać linku weryfikacyjnego do %s: %v", user.Email, err)
+	}
+
 	// Nie zwracamy hasła
 	user.PasswordHash = ""
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, req *domain.UserLogin) (string, *domain.User, error) {
+// Login weryfikuje hasło. Dla kont z włączonym MFA zamiast tokenów zwraca
+// krótkotrwały token MFA, który trzeba wymienić razem z kodem (MFAService.CompleteLogin).
+func (s *AuthService) Login(ctx context.Context, req *domain.UserLogin) (*domain.LoginResult, error) {
+	// Zablokowane próby odrzucamy przed kosztownym porównaniem hasła
+	if err := s.loginGuard.Check(ctx, req.Email, req.IP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
-			return "", nil, domain.ErrInvalidCredentials
+			return nil, s.loginFailed(ctx, req)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Konto z logowania przez dostawcę OIDC nie ma jeszcze hasła
+	if !user.HasPassword() {
+		return nil, s.loginFailed(ctx, req)
+	}
+
+	ok, rehash, err := s.passwords.Verify(req.Password, user.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify password: %w", err)
+	}
+	if !ok {
+		return nil, s.loginFailed(ctx, req)
+	}
+
+	// Znamy hasło w postaci jawnej tylko teraz - to jedyna okazja, by
+	// przeliczyć skrót z aktualnymi parametrami bez wymuszania resetu
+	if rehash {
+		s.upgradePasswordHash(ctx, user.ID, req.Password)
+	}
+
+	mfa, err := s.mfaRepo.Get(ctx, user.ID)
+	if err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+		return nil, err
+	}
+
+	if mfa.IsEnabled() {
+		// Licznik porażek zerujemy dopiero po poprawnym drugim składniku
+		mfaToken, err := s.generateMFAToken(user, time.Now().UTC())
+		if err != nil {
+			return nil, err
 		}
-		return "", nil, fmt.Errorf("failed to get user: %w", err)
+		return &domain.LoginResult{MFARequired: true, MFAToken: mfaToken}, nil
 	}
 
-	if err := bcrypt.CompareHashAndPassword(
-		[]byte(user.PasswordHash),
-		[]byte(req.Password),
-	); err != nil {
-		return "", nil, domain.ErrInvalidCredentials
+	if err := s.loginGuard.Succeed(ctx, req.Email); err != nil {
+		return nil, err
 	}
 
-	// Generowanie tokenu JWT
-	token, err := generateJWT(user, s.jwtSecret)
+	// Każde logowanie rozpoczyna nową sesję (rodzinę tokenów odświeżających)
+	tokens, err := s.startSession(ctx, user, domain.SessionClient{IP: req.IP, UserAgent: req.UserAgent})
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
 	// Nie zwracamy hasła
 	user.PasswordHash = ""
-	return token, user, nil
+	return &domain.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// upgradePasswordHash zapisuje skrót wyliczony z aktualnymi parametrami.
+// Błąd nie przerywa logowania - spróbujemy ponownie przy następnym.
+func (s *AuthService) upgradePasswordHash(ctx context.Context, userID uuid.UUID, password string) {
+	hash, err := s.passwords.Hash(password)
+	if err == nil {
+		err = s.userRepo.UpdatePassword(ctx, userID, hash)
+	}
+	if err != nil {
+		log.Printf("Nie udało się zaktualizować skrótu hasła użytkownika %s: %v", userID, err)
+	}
+}
+
+// loginFailed rejestruje nieudaną próbę i zwraca błąd, który zobaczy klient
+func (s *AuthService) loginFailed(ctx context.Context, req *domain.UserLogin) error {
+	if err := s.loginGuard.Fail(ctx, req.Email, req.IP); err != nil {
+		return err
+	}
+	return domain.ErrInvalidCredentials
+}
+
+// Refresh wymienia token odświeżający na nową parę tokenów (rotacja).
+// Użycie tokenu, który został już zrotowany, oznacza jego wyciek -
+// unieważniamy wtedy całą rodzinę, wylogowując zarówno atakującego, jak i ofiarę.
+func (s *AuthService) Refresh(ctx context.Context, req *domain.RefreshRequest) (*domain.TokenPair, error) {
+	granted, err := s.rotate(ctx, req.RefreshToken, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ostatnia aktywność sesji służy tylko do wyświetlenia - błąd nie przerywa odświeżenia
+	client := domain.SessionClient{IP: req.IP, UserAgent: req.UserAgent}
+	if err := s.sessions.Touch(ctx, granted.FamilyID, client, time.Now().UTC()); err != nil {
+		log.Printf("Nie udało się zapisać aktywności sesji %s: %v", granted.FamilyID, err)
+	}
+	return granted.TokenPair, nil
+}
+
+// rotate wymienia token odświeżający należący do podanej aplikacji (pusty
+// clientID oznacza sesję z logowania). Niepusta lista scopes zawęża uprawnienia
+// - nie może wykraczać poza zakresy nadane pierwotnie.
+func (s *AuthService) rotate(ctx context.Context, refreshToken, clientID string, scopes []string) (*domain.GrantedTokens, error) {
+	current, err := s.refreshTokenRepo.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	// Token aplikacji nie działa jako token sesji i odwrotnie
+	if current.ClientID != clientID {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	now := time.Now().UTC()
+	if current.RevokedAt != nil {
+		// Kończymy całą sesję - razem z tokenami odświeżającymi przestają
+		// działać wystawione w niej tokeny dostępowe (claim sid)
+		if err := s.endSession(ctx, current.UserID, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrRefreshTokenReused
+	}
+
+	if current.IsExpired(now) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	grantedScopes := current.Scopes
+	if len(scopes) > 0 {
+		if !isSubset(scopes, current.Scopes) {
+			return nil, domain.ErrInvalidScope
+		}
+		grantedScopes = scopes
+	}
+
+	user, err := s.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	plain, next, err := s.newRefreshToken(user.ID, current.FamilyID, clientID, grantedScopes, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.Rotate(ctx, current.ID, next); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			// Ktoś zdążył użyć tego samego tokenu równolegle
+			if err := s.endSession(ctx, current.UserID, current.FamilyID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	accessToken, accessExpiresAt, err := s.generateAccessToken(user, current.FamilyID, clientID, effectiveScopes(grantedScopes), now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.GrantedTokens{
+		TokenPair: &domain.TokenPair{
+			AccessToken:      accessToken,
+			AccessExpiresAt:  accessExpiresAt,
+			RefreshToken:     plain,
+			RefreshExpiresAt: next.ExpiresAt,
+		},
+		FamilyID: current.FamilyID,
+		Scopes:   effectiveScopes(grantedScopes),
+	}, nil
+}
+
+// Logout unieważnia bieżący token dostępowy oraz - jeśli podano - rodzinę tokenu odświeżającego
+func (s *AuthService) Logout(ctx context.Context, claims *domain.AccessClaims, refreshToken string) error {
+	if err := s.revocations.RevokeToken(ctx, claims.TokenID, claims.UserID, claims.ExpiresAt); err != nil {
+		return err
+	}
+
+	// Token z identyfikatorem sesji pozwala zakończyć ją bez podawania tokenu odświeżającego
+	if claims.SessionID != nil {
+		if err := s.endSession(ctx, claims.UserID, *claims.SessionID); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	token, err := s.refreshTokenRepo.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			return nil
+		}
+		return err
+	}
+
+	// Nie pozwalamy unieważniać cudzych sesji
+	if token.UserID != claims.UserID {
+		return nil
+	}
+
+	return s.refreshTokenRepo.RevokeFamily(ctx, token.FamilyID, time.Now().UTC())
+}
+
+// LogoutAll unieważnia wszystkie tokeny dostępowe i odświeżające użytkownika
+func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now().UTC()
+
+	if err := s.revocations.RevokeAllForUser(ctx, userID, now.Truncate(time.Second)); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeAllForUser(ctx, userID, now)
 }
 
-func generateJWT(user *domain.User, secret string) (string, error) {
-	claims := jwt.MapClaims{
-		"sub":   user.ID.String(),
-		"exp":   time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 dni
-		"name":  user.Name,
-		"email": user.Email,
+// ChangeRole zmienia rolę użytkownika. Dotychczasowe tokeny dostępowe są
+// unieważniane, więc nowa rola obowiązuje od najbliższego odświeżenia tokenu.
+func (s *AuthService) ChangeRole(ctx context.Context, actorID, userID uuid.UUID, role domain.Role) error {
+	if !role.IsValid() {
+		return domain.ErrInvalidRole
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	// Chroni przed przypadkowym odebraniem sobie uprawnień administratora
+	if actorID == userID {
+		return domain.ErrCannotChangeOwnRole
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, userID, role); err != nil {
+		return err
+	}
+
+	return s.revocations.RevokeAllForUser(ctx, userID, time.Now().UTC().Truncate(time.Second))
+}
+
+// issueTokens wystawia token dostępowy i zapisuje nowy token odświeżający w podanej rodzinie
+func (s *AuthService) issueTokens(ctx context.Context, user *domain.User, familyID uuid.UUID) (*domain.TokenPair, error) {
+	return s.issueGrantTokens(ctx, user, familyID, "", nil)
+}
+
+// issueGrantTokens wystawia tokeny dla aplikacji OAuth z ograniczonymi zakresami.
+// Dla sesji z logowania clientID jest pusty, a scopes nil (pełne uprawnienia).
+func (s *AuthService) issueGrantTokens(ctx context.Context, user *domain.User, familyID uuid.UUID, clientID string, scopes []string) (*domain.TokenPair, error) {
+	now := time.Now().UTC()
+
+	accessToken, accessExpiresAt, err := s.generateAccessToken(user, familyID, clientID, effectiveScopes(scopes), now)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, refresh, err := s.newRefreshToken(user.ID, familyID, clientID, scopes, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.Create(ctx, refresh); err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     plain,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
+
+func (s *AuthService) newRefreshToken(userID, familyID uuid.UUID, clientID string, scopes []string, now time.Time) (string, *domain.RefreshToken, error) {
+	plain, err := generateOpaqueToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return plain, &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(plain),
+		ClientID:  clientID,
+		Scopes:    scopes,
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	}, nil
+}
+
+// effectiveScopes zamienia brak ograniczeń (nil) na pełną listę zakresów
+func effectiveScopes(scopes []string) []string {
+	if scopes == nil {
+		return domain.AllScopes()
+	}
+	return scopes
+}
+
+// isSubset informuje, czy wszystkie zakresy z requested mieszczą się w granted
+// (nil w granted oznacza pełne uprawnienia)
+func isSubset(requested, granted []string) bool {
+	if granted == nil {
+		return true
+	}
+	for _, scope := range requested {
+		found := false
+		for _, g := range granted {
+			if g == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
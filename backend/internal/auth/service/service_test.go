@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// tokenFixture to serwis z repozytoriami w pamięci i zalogowanym użytkownikiem
+type tokenFixture struct {
+	svc         *AuthService
+	tokens      *fakeRefreshTokenRepo
+	sessions    *fakeSessionRepo
+	revocations *fakeRevocationStore
+	user        *domain.User
+}
+
+func newTokenFixture(t *testing.T) *tokenFixture {
+	t.Helper()
+	user := &domain.User{ID: uuid.New(), Name: "Ala", Email: "ala@example.com", Role: domain.RoleUser}
+	f := &tokenFixture{
+		tokens:      newFakeRefreshTokenRepo(),
+		sessions:    newFakeSessionRepo(),
+		revocations: newFakeRevocationStore(),
+		user:        user,
+	}
+	f.svc = &AuthService{
+		userRepo:         newFakeUserRepo(user),
+		refreshTokenRepo: f.tokens,
+		sessions:         f.sessions,
+		revocations:      f.revocations,
+		signingKeys:      newFakeSigningKeys(t),
+		accessTTL:        15 * time.Minute,
+		refreshTTL:       24 * time.Hour,
+	}
+	return f
+}
+
+// login rozpoczyna sesję i zwraca jej tokeny oraz identyfikator
+func (f *tokenFixture) login(t *testing.T) (*domain.TokenPair, uuid.UUID) {
+	t.Helper()
+	pair, err := f.svc.startSession(context.Background(), f.user, domain.SessionClient{IP: "192.0.2.1", UserAgent: "Firefox/125.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := f.svc.ValidateAccessToken(context.Background(), pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair, *claims.SessionID
+}
+
+func (f *tokenFixture) refresh(refreshToken string) (*domain.TokenPair, error) {
+	return f.svc.Refresh(context.Background(), &domain.RefreshRequest{RefreshToken: refreshToken, IP: "198.51.100.7", UserAgent: "curl/8.5.0"})
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	f := newTokenFixture(t)
+	pair, sessionID := f.login(t)
+
+	next, err := f.refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.RefreshToken == pair.RefreshToken || next.AccessToken == pair.AccessToken {
+		t.Error("refresh returned the same tokens")
+	}
+	if active := f.tokens.active(sessionID); active != 1 {
+		t.Errorf("session has %d active refresh tokens, want 1", active)
+	}
+
+	claims, err := f.svc.ValidateAccessToken(context.Background(), next.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.SessionID == nil || *claims.SessionID != sessionID {
+		t.Errorf("rotated access token belongs to session %v, want %s", claims.SessionID, sessionID)
+	}
+
+	// Odświeżenie zapisuje urządzenie, z którego przyszło
+	if session := f.sessions.sessions[sessionID]; session.IP != "198.51.100.7" || session.UserAgent != "curl/8.5.0" {
+		t.Errorf("session client = %s %s, want the refreshing client", session.IP, session.UserAgent)
+	}
+
+	// Nowy token odświeżający działa dalej
+	if _, err := f.refresh(next.RefreshToken); err != nil {
+		t.Errorf("second rotation: %v", err)
+	}
+}
+
+func TestRefreshReuseEndsSession(t *testing.T) {
+	f := newTokenFixture(t)
+	pair, sessionID := f.login(t)
+
+	next, err := f.refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ponowne użycie zrotowanego tokenu oznacza wyciek
+	if _, err := f.refresh(pair.RefreshToken); !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("replay: err = %v, want ErrRefreshTokenReused", err)
+	}
+	if active := f.tokens.active(sessionID); active != 0 {
+		t.Errorf("session has %d active refresh tokens after reuse, want 0", active)
+	}
+	if _, err := f.refresh(next.RefreshToken); err == nil {
+		t.Error("refresh token issued before reuse still works")
+	}
+
+	// Tokeny dostępowe sesji też przestają działać
+	for name, token := range map[string]string{"original": pair.AccessToken, "rotated": next.AccessToken} {
+		if _, err := f.svc.ValidateAccessToken(context.Background(), token); !errors.Is(err, domain.ErrTokenRevoked) {
+			t.Errorf("%s access token: err = %v, want ErrTokenRevoked", name, err)
+		}
+	}
+}
+
+func TestRefreshConcurrentRotationEndsSession(t *testing.T) {
+	f := newTokenFixture(t)
+	pair, sessionID := f.login(t)
+
+	// Inne żądanie obraca ten sam token między odczytem a zapisem
+	f.tokens.beforeRotate = func(oldID uuid.UUID) {
+		f.tokens.beforeRotate = nil
+		now := time.Now().UTC()
+		f.tokens.tokens[oldID].RevokedAt = &now
+	}
+
+	if _, err := f.refresh(pair.RefreshToken); !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("err = %v, want ErrRefreshTokenReused", err)
+	}
+	if active := f.tokens.active(sessionID); active != 0 {
+		t.Errorf("session has %d active refresh tokens, want 0", active)
+	}
+	if _, err := f.svc.ValidateAccessToken(context.Background(), pair.AccessToken); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("access token: err = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestRefreshRejectsExpiredToken(t *testing.T) {
+	f := newTokenFixture(t)
+	pair, sessionID := f.login(t)
+
+	for _, token := range f.tokens.tokens {
+		token.ExpiresAt = time.Now().Add(-time.Second)
+	}
+
+	if _, err := f.refresh(pair.RefreshToken); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+		t.Fatalf("err = %v, want ErrInvalidRefreshToken", err)
+	}
+	// Wygaśnięcie to nie wyciek - sesji nie kończymy
+	if active := f.tokens.active(sessionID); active != 1 {
+		t.Errorf("session has %d active refresh tokens, want 1", active)
+	}
+	if _, err := f.svc.ValidateAccessToken(context.Background(), pair.AccessToken); err != nil {
+		t.Errorf("access token: %v", err)
+	}
+}
+
+func TestRefreshRejectsUnknownAndClientTokens(t *testing.T) {
+	f := newTokenFixture(t)
+
+	if _, err := f.refresh("not-a-token"); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+		t.Errorf("unknown token: err = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	client, err := f.svc.issueGrantTokens(context.Background(), f.user, uuid.New(), "bsc_app", []string{domain.ScopeBooksRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.refresh(client.RefreshToken); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+		t.Errorf("application token: err = %v, want ErrInvalidRefreshToken", err)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+// startSession zapisuje nową sesję i wystawia dla niej pierwszą parę tokenów
+func (s *AuthService) startSession(ctx context.Context, user *domain.User, client domain.SessionClient) (*domain.TokenPair, error) {
+	now := time.Now().UTC()
+	session := &domain.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, session.ID)
+}
+
+// ListSessions zwraca aktywne sesje użytkownika, oznaczając tę, z której wysłano żądanie
+func (s *AuthService) ListSessions(ctx context.Context, claims *domain.AccessClaims) ([]domain.Session, error) {
+	sessions, err := s.sessions.ListActive(ctx, claims.UserID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Device = describeDevice(sessions[i].UserAgent)
+		sessions[i].Current = claims.SessionID != nil && *claims.SessionID == sessions[i].ID
+	}
+	return sessions, nil
+}
+
+// RevokeSession kończy wskazaną sesję użytkownika - jej tokeny odświeżające
+// przestają działać, a tokeny dostępowe są odrzucane przez middleware
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if _, err := s.sessions.GetActive(ctx, userID, sessionID, time.Now().UTC()); err != nil {
+		return err
+	}
+	return s.endSession(ctx, userID, sessionID)
+}
+
+func (s *AuthService) endSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	now := time.Now().UTC()
+
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, sessionID, now); err != nil {
+		return err
+	}
+
+	// Wystarczy pamiętać unieważnienie do wygaśnięcia ostatniego tokenu dostępowego
+	return s.revocations.RevokeToken(ctx, sessionRevocationID(sessionID), userID, now.Add(s.accessTTL))
+}
+
+// RecordSessionActivity zapisuje ostatnią aktywność sesji, z której pochodzi
+// token. Middleware wywołuje ją przy każdym żądaniu, ale do bazy trafia
+// najwyżej jeden zapis na sessionActivityInterval. Błąd tylko logujemy, bo
+// aktywność służy wyłącznie do wyświetlenia na liście sesji.
+func (s *AuthService) RecordSessionActivity(ctx context.Context, claims *domain.AccessClaims, client domain.SessionClient) {
+	if claims.SessionID == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	if !s.activity.due(*claims.SessionID, now) {
+		return
+	}
+	if err := s.sessions.Touch(ctx, *claims.SessionID, client, now); err != nil {
+		log.Printf("Nie udało się zapisać aktywności sesji %s: %v", *claims.SessionID, err)
+	}
+}
+
+// Najkrótszy odstęp między zapisami aktywności jednej sesji
+const sessionActivityInterval = time.Minute
+
+// activityThrottle pamięta, kiedy ta instancja ostatnio zapisała aktywność
+// każdej sesji. Wpisy starsze niż sessionActivityInterval są usuwane.
+type activityThrottle struct {
+	mu        sync.Mutex
+	written   map[uuid.UUID]time.Time
+	lastSweep time.Time
+}
+
+// due informuje, czy aktywność sesji należy zapisać, i od razu odnotowuje zapis
+func (t *activityThrottle) due(sessionID uuid.UUID, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.written == nil {
+		t.written = make(map[uuid.UUID]time.Time)
+	}
+	if now.Sub(t.lastSweep) >= sessionActivityInterval {
+		for id, at := range t.written {
+			if now.Sub(at) >= sessionActivityInterval {
+				delete(t.written, id)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	if last, ok := t.written[sessionID]; ok && now.Sub(last) < sessionActivityInterval {
+		return false
+	}
+	t.written[sessionID] = now
+	return true
+}
+
+// sessionRevocationID to klucz unieważnienia całej sesji w magazynie unieważnień
+// (obok identyfikatorów pojedynczych tokenów)
+func sessionRevocationID(sessionID uuid.UUID) string {
+	return "session:" + sessionID.String()
+}
+
+// describeDevice zwraca czytelny opis przeglądarki i systemu, np. "Firefox, Windows".
+// Wystarcza do rozpoznania własnych urządzeń - nie jest pełnym parserem User-Agent.
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Nieznane urządzenie"
+	}
+
+	var browser string
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	var system string
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		system = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		system = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		system = "Windows"
+	case strings.Contains(userAgent, "Mac OS X"):
+		system = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		system = "Linux"
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + ", " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		// Aplikacje i skrypty - pokazujemy pierwszy człon, np. "curl/8.5.0"
+		product, _, _ := strings.Cut(userAgent, " ")
+		return product
+	}
+}
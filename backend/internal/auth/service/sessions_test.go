@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+)
+
+func TestRecordSessionActivityIsThrottled(t *testing.T) {
+	f := newTokenFixture(t)
+	pair, sessionID := f.login(t)
+
+	claims, err := f.svc.ValidateAccessToken(context.Background(), pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := domain.SessionClient{IP: "203.0.113.9", UserAgent: "Safari/605.1.15"}
+
+	for i := 0; i < 3; i++ {
+		f.svc.RecordSessionActivity(context.Background(), claims, client)
+	}
+	if f.sessions.touches != 1 {
+		t.Errorf("%d activity writes for 3 requests, want 1", f.sessions.touches)
+	}
+	if session := f.sessions.sessions[sessionID]; session.IP != client.IP || session.UserAgent != client.UserAgent {
+		t.Errorf("session client = %s %s, want the requesting client", session.IP, session.UserAgent)
+	}
+
+	// Klucze API i tokeny aplikacji nie należą do sesji
+	f.svc.RecordSessionActivity(context.Background(), &domain.AccessClaims{UserID: f.user.ID, ClientID: "bsc_app"}, client)
+	if f.sessions.touches != 1 {
+		t.Errorf("activity recorded for a token without session")
+	}
+}
+
+func TestActivityThrottle(t *testing.T) {
+	var throttle activityThrottle
+	first, second := uuid.New(), uuid.New()
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		session uuid.UUID
+		at      time.Time
+		want    bool
+	}{
+		{"first request", first, start, true},
+		{"within interval", first, start.Add(sessionActivityInterval - time.Second), false},
+		{"other session", second, start.Add(time.Second), true},
+		{"after interval", first, start.Add(sessionActivityInterval), true},
+		{"other session within its interval", second, start.Add(sessionActivityInterval), false},
+	}
+	for _, tt := range tests {
+		if got := throttle.due(tt.session, tt.at); got != tt.want {
+			t.Errorf("%s: due = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Stare wpisy nie zostają w pamięci
+	throttle.due(first, start.Add(10*sessionActivityInterval))
+	if len(throttle.written) != 1 {
+		t.Errorf("throttle remembers %d sessions, want 1", len(throttle.written))
+	}
+}
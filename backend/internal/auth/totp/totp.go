@@ -0,0 +1,147 @@
+// Package totp implementuje jednorazowe kody czasowe zgodne z RFC 6238
+// (oraz leżącym u ich podstaw HOTP z RFC 4226).
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSecret = errors.New("invalid totp secret")
+
+// Algorithm to funkcja skrótu używana przez HMAC
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+func (a Algorithm) hash() func() hash.Hash {
+	switch a {
+	case SHA256:
+		return sha256.New
+	case SHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
+
+// Options opisuje parametry generowania kodów
+type Options struct {
+	Algorithm Algorithm
+	Digits    int
+	Period    time.Duration
+}
+
+// DefaultOptions to parametry obsługiwane przez wszystkie popularne aplikacje uwierzytelniające
+var DefaultOptions = Options{
+	Algorithm: SHA1,
+	Digits:    6,
+	Period:    30 * time.Second,
+}
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret zwraca losowy sekret (160 bitów) zakodowany w base32
+func GenerateSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Step zwraca numer okna czasowego, do którego należy chwila t
+func (o Options) Step(t time.Time) uint64 {
+	return uint64(t.Unix()) / uint64(o.Period/time.Second)
+}
+
+// Code zwraca kod dla chwili t
+func (o Options) Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return o.hotp(key, o.Step(t)), nil
+}
+
+// Validate sprawdza kod w oknie t oraz w skew sąsiednich oknach (tolerancja
+// rozjechanego zegara). Zwraca numer dopasowanego okna, żeby wywołujący mógł
+// odrzucić ponowne użycie tego samego kodu.
+func (o Options) Validate(secret, code string, t time.Time, skew int) (uint64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(code) != o.Digits {
+		return 0, false
+	}
+
+	current := o.Step(t)
+	for i := -skew; i <= skew; i++ {
+		if i < 0 && current < uint64(-i) {
+			continue
+		}
+		step := uint64(int64(current) + int64(i))
+		if subtle.ConstantTimeCompare([]byte(o.hotp(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URI zwraca adres otpauth:// do zakodowania w kodzie QR
+func (o Options) URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", string(o.Algorithm))
+	q.Set("digits", fmt.Sprint(o.Digits))
+	q.Set("period", fmt.Sprint(int(o.Period/time.Second)))
+
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// hotp implementuje algorytm z RFC 4226 (rozdział 5.3)
+func (o Options) hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(o.Algorithm.hash(), key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < o.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", o.Digits, value%mod)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	normalized = strings.TrimRight(normalized, "=")
+
+	key, err := encoding.DecodeString(normalized)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
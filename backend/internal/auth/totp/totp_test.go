@@ -0,0 +1,127 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Sekrety z RFC 6238, dodatek B (dla każdego algorytmu innej długości)
+var rfcSecrets = map[Algorithm]string{
+	SHA1:   encoding.EncodeToString([]byte("12345678901234567890")),
+	SHA256: encoding.EncodeToString([]byte("12345678901234567890123456789012")),
+	SHA512: encoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234")),
+}
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix      int64
+		algorithm Algorithm
+		want      string
+	}{
+		{59, SHA1, "94287082"},
+		{59, SHA256, "46119246"},
+		{59, SHA512, "90693936"},
+		{1111111109, SHA1, "07081804"},
+		{1111111109, SHA256, "68084774"},
+		{1111111109, SHA512, "25091201"},
+		{1111111111, SHA1, "14050471"},
+		{1111111111, SHA256, "67062674"},
+		{1111111111, SHA512, "99943326"},
+		{1234567890, SHA1, "89005924"},
+		{1234567890, SHA256, "91819424"},
+		{1234567890, SHA512, "93441116"},
+		{2000000000, SHA1, "69279037"},
+		{2000000000, SHA256, "90698825"},
+		{2000000000, SHA512, "38618901"},
+		{20000000000, SHA1, "65353130"},
+		{20000000000, SHA256, "77737706"},
+		{20000000000, SHA512, "47863826"},
+	}
+
+	for _, tt := range tests {
+		options := Options{Algorithm: tt.algorithm, Digits: 8, Period: 30 * time.Second}
+		got, err := options.Code(rfcSecrets[tt.algorithm], time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(%s, %d): %v", tt.algorithm, tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%s, %d) = %s, want %s", tt.algorithm, tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	options := Options{Algorithm: SHA1, Digits: 8, Period: 30 * time.Second}
+	secret := rfcSecrets[SHA1]
+	now := time.Unix(1111111111, 0)
+	current := options.Step(now)
+
+	tests := []struct {
+		name     string
+		codeAt   time.Time
+		skew     int
+		wantOK   bool
+		wantStep uint64
+	}{
+		{"current step", now, 0, true, current},
+		{"previous step within skew", now.Add(-30 * time.Second), 1, true, current - 1},
+		{"next step within skew", now.Add(30 * time.Second), 1, true, current + 1},
+		{"previous step without skew", now.Add(-30 * time.Second), 0, false, 0},
+		{"two steps back with skew 1", now.Add(-60 * time.Second), 1, false, 0},
+	}
+
+	for _, tt := range tests {
+		code, err := options.Code(secret, tt.codeAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, ok := options.Validate(secret, code, now, tt.skew)
+		if ok != tt.wantOK || step != tt.wantStep {
+			t.Errorf("%s: Validate = (%d, %v), want (%d, %v)", tt.name, step, ok, tt.wantStep, tt.wantOK)
+		}
+	}
+}
+
+// Ponowne użycie kodu wywołujący rozpoznaje po numerze okna: ten sam kod
+// zwraca to samo okno, a kod z wcześniejszego okna - mniejszy numer
+func TestValidateReturnsStepForReplayCheck(t *testing.T) {
+	options := DefaultOptions
+	secret := rfcSecrets[SHA1]
+	now := time.Unix(1234567890, 0)
+
+	code, _ := options.Code(secret, now)
+	first, ok := options.Validate(secret, code, now, 1)
+	if !ok {
+		t.Fatal("valid code rejected")
+	}
+	again, ok := options.Validate(secret, code, now.Add(10*time.Second), 1)
+	if !ok || again != first {
+		t.Errorf("same code validated to step %d (ok=%v), want %d", again, ok, first)
+	}
+
+	older, _ := options.Code(secret, now.Add(-30*time.Second))
+	step, ok := options.Validate(secret, older, now, 1)
+	if !ok || step >= first {
+		t.Errorf("older code validated to step %d (ok=%v), want less than %d", step, ok, first)
+	}
+}
+
+func TestValidateRejectsMalformedInput(t *testing.T) {
+	options := DefaultOptions
+	secret := rfcSecrets[SHA1]
+	now := time.Unix(59, 0)
+	code, _ := options.Code(secret, now)
+
+	if _, ok := options.Validate(secret, code[:5], now, 1); ok {
+		t.Error("short code accepted")
+	}
+	if _, ok := options.Validate("not base32!", code, now, 1); ok {
+		t.Error("invalid secret accepted")
+	}
+	// Sekret wpisany ręcznie: małe litery, spacje, dopełnienie
+	spaced := strings.ToLower(secret[:4] + " " + secret[4:] + "====")
+	if _, ok := options.Validate(spaced, code, now, 0); !ok {
+		t.Error("secret with spaces and lower case rejected")
+	}
+}
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// @Summary Utworzenie osobistego klucza API
+// @Description Jawny klucz jest zwracany tylko w tej odpowiedzi
+// @Accept json
+// @Produce json
+// @Param input body domain.APIKeyCreate true "Nazwa, zakresy i data wygaśnięcia"
+// @Success 201 {object} domain.CreatedAPIKey
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /me/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req domain.APIKeyCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+	key, err := h.apiKeyService.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// @Summary Lista kluczy API zalogowanego użytkownika
+// @Produce json
+// @Success 200 {array} domain.APIKey
+// @Router /me/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+	keys, err := h.apiKeyService.List(c.Request.Context(), userID)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// @Summary Unieważnienie klucza API
+// @Param id path string true "ID klucza"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /me/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-id",
+			Message: "Nieprawidłowy identyfikator",
+		})
+		return
+	}
+
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+	if err := h.apiKeyService.Revoke(c.Request.Context(), userID, keyID); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
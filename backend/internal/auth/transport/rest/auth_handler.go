@@ -2,11 +2,15 @@ package rest
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	_ "time"
 
 	"github.com/gin-gonic/gin"
-	_ "github.com/google/uuid"
+	"github.com/google/uuid"
 
 	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
 	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
@@ -40,6 +44,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	user, err := h.authService.Register(c.Request.Context(), &req)
 	if err != nil {
+		if handlePasswordPolicyError(c, err, "password") {
+			return
+		}
 		handleAuthError(c, err)
 		return
 	}
@@ -54,6 +61,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req domain.UserLogin
@@ -65,20 +73,259 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := h.authService.Login(c.Request.Context(), &req)
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	result, err := h.authService.Login(c.Request.Context(), &req)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(result))
+}
+
+// @Summary Odświeżenie tokenu dostępowego (rotacja tokenu odświeżającego)
+// @Accept json
+// @Produce json
+// @Param input body domain.RefreshRequest true "Token odświeżający"
+// @Success 200 {object} domain.TokenPair
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req domain.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), &req)
 	if err != nil {
 		handleAuthError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, LoginResponse{
-		User:  user,
-		Token: token,
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Wylogowanie z bieżącego urządzenia
+// @Accept json
+// @Param input body domain.LogoutRequest false "Token odświeżający do unieważnienia"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req domain.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	claims, ok := GetClaimsFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "missing-token",
+			Message: "Brak tokenu autoryzacyjnego",
+		})
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), claims, req.RefreshToken); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Wylogowanie ze wszystkich urządzeń
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "missing-token",
+			Message: "Brak tokenu autoryzacyjnego",
+		})
+		return
+	}
+
+	if err := h.authService.LogoutAll(c.Request.Context(), userID); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Aktywne sesje zalogowanego użytkownika
+// @Description Urządzenie, adres IP i przeglądarka z ostatniego odświeżenia tokenów; bieżąca sesja ma current=true
+// @Produce json
+// @Success 200 {array} domain.Session
+// @Failure 401 {object} ErrorResponse
+// @Router /me/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	claims, _ := GetClaimsFromContext(c.Request.Context())
+	sessions, err := h.authService.ListSessions(c.Request.Context(), claims)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// @Summary Zakończenie wybranej sesji (wylogowanie urządzenia)
+// @Param id path string true "ID sesji"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /me/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-id",
+			Message: "Nieprawidłowy identyfikator",
+		})
+		return
+	}
+
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+	if err := h.authService.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Zmiana hasła zalogowanego użytkownika
+// @Description Wylogowuje pozostałe sesje i zwraca nową parę tokenów dla bieżącego klienta
+// @Accept json
+// @Produce json
+// @Param input body domain.ChangePasswordRequest true "Obecne i nowe hasło"
+// @Success 200 {object} domain.TokenPair
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /me/password [post]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req domain.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+	tokens, err := h.authService.ChangePassword(c.Request.Context(), userID, &req)
+	if err != nil {
+		if handlePasswordPolicyError(c, err, "newPassword") {
+			return
+		}
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Zmiana roli użytkownika (tylko administrator)
+// @Accept json
+// @Param id path string true "ID użytkownika"
+// @Param input body domain.RoleUpdate true "Nowa rola"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/role [put]
+func (h *AuthHandler) ChangeRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-user-id",
+			Message: "Nieprawidłowy identyfikator użytkownika",
+		})
+		return
+	}
+
+	var req domain.RoleUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	actorID, _ := GetUserIDFromContext(c.Request.Context())
+	if err := h.authService.ChangeRole(c.Request.Context(), actorID, userID, req.Role); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handlePasswordPolicyError odpowiada kodem naruszonej reguły polityki haseł
+// i nazwą pola z hasłem. Dla innych błędów zwraca false.
+func handlePasswordPolicyError(c *gin.Context, err error, field string) bool {
+	var policyErr *domain.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	var message string
+	switch policyErr.Rule {
+	case domain.PasswordTooShort:
+		message = fmt.Sprintf("Hasło musi mieć co najmniej %d znaków", policyErr.Limit)
+	case domain.PasswordTooLong:
+		message = fmt.Sprintf("Hasło może mieć najwyżej %d znaków", policyErr.Limit)
+	case domain.PasswordTooWeak:
+		message = fmt.Sprintf("Hasło musi zawierać znaki z co najmniej %d grup: małe litery, wielkie litery, cyfry, znaki specjalne", policyErr.Limit)
+	case domain.PasswordPersonalInfo:
+		message = "Hasło nie może zawierać imienia ani adresu email"
+	case domain.PasswordBreached:
+		message = "To hasło pojawiło się w znanym wycieku danych - wybierz inne"
+	default:
+		message = "Hasło nie spełnia wymagań"
+	}
+
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Code:    policyErr.Rule,
+		Message: message,
+		Field:   field,
 	})
+	return true
 }
 
 func handleAuthError(c *gin.Context, err error) {
+	var tooManyAttempts *domain.TooManyAttemptsError
+
 	switch {
+	case errors.As(err, &tooManyAttempts):
+		retryAfter := int(math.Ceil(tooManyAttempts.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(http.StatusTooManyRequests, ErrorResponse{
+			Code:    "too-many-attempts",
+			Message: "Zbyt wiele nieudanych prób logowania, spróbuj ponownie później",
+		})
 	case errors.Is(err, domain.ErrEmailExists):
 		c.JSON(http.StatusConflict, ErrorResponse{
 			Code:    "email-exists",
@@ -89,6 +336,133 @@ func handleAuthError(c *gin.Context, err error) {
 			Code:    "invalid-credentials",
 			Message: "Nieprawidłowy email lub hasło",
 		})
+	case errors.Is(err, domain.ErrInvalidCurrentPassword):
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Code:    "invalid-current-password",
+			Message: "Nieprawidłowe obecne hasło",
+		})
+	case errors.Is(err, domain.ErrPasswordNotSet):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Code:    "password-not-set",
+			Message: "Konto nie ma hasła - ustaw je przez resetowanie hasła",
+		})
+	case errors.Is(err, domain.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "session-not-found",
+			Message: "Nie znaleziono sesji",
+		})
+	case errors.Is(err, domain.ErrOIDCProviderNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "oidc-provider-not-found",
+			Message: "Nieznany dostawca logowania",
+		})
+	case errors.Is(err, domain.ErrInvalidOIDCState):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-oidc-state",
+			Message: "Logowanie wygasło lub zostało już dokończone - spróbuj ponownie",
+		})
+	case errors.Is(err, domain.ErrOIDCEmailNotVerified):
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Code:    "oidc-email-not-verified",
+			Message: "Dostawca logowania nie potwierdził adresu email",
+		})
+	case errors.Is(err, domain.ErrIdentityConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Code:    "identity-conflict",
+			Message: "Konto z tym adresem email już istnieje - zaloguj się hasłem i potwierdź adres",
+		})
+	case errors.Is(err, domain.ErrOIDCLoginFailed):
+		c.JSON(http.StatusBadGateway, ErrorResponse{
+			Code:    "oidc-login-failed",
+			Message: "Nie udało się zalogować przez zewnętrznego dostawcę",
+		})
+	case errors.Is(err, domain.ErrInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "invalid-refresh-token",
+			Message: "Nieprawidłowy lub wygasły token odświeżający",
+		})
+	case errors.Is(err, domain.ErrRefreshTokenReused):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "refresh-token-reused",
+			Message: "Token odświeżający został już użyty - zaloguj się ponownie",
+		})
+	case errors.Is(err, domain.ErrInvalidResetToken):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-reset-token",
+			Message: "Link do resetu hasła jest nieprawidłowy, wygasł lub został już użyty",
+		})
+	case errors.Is(err, domain.ErrInvalidVerificationToken):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-verification-token",
+			Message: "Link weryfikacyjny jest nieprawidłowy, wygasł lub został już użyty",
+		})
+	case errors.Is(err, domain.ErrInvalidEmailChangeToken):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-email-change-token",
+			Message: "Link potwierdzający zmianę adresu jest nieprawidłowy, wygasł lub został już użyty",
+		})
+	case errors.Is(err, domain.ErrInvalidScope):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-scope",
+			Message: "Nieznany zakres uprawnień",
+			Field:   "scopes",
+		})
+	case errors.Is(err, domain.ErrInvalidAPIKeyExpiry):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-expiry",
+			Message: "Data wygaśnięcia klucza musi być w przyszłości",
+			Field:   "expiresAt",
+		})
+	case errors.Is(err, domain.ErrAPIKeyLimitReached):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Code:    "api-key-limit-reached",
+			Message: "Osiągnięto limit aktywnych kluczy API",
+		})
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "api-key-not-found",
+			Message: "Nie znaleziono klucza API",
+		})
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "user-not-found",
+			Message: "Nie znaleziono użytkownika",
+		})
+	case errors.Is(err, domain.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-role",
+			Message: "Nieznana rola",
+		})
+	case errors.Is(err, domain.ErrCannotChangeOwnRole):
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Code:    "cannot-change-own-role",
+			Message: "Nie możesz zmienić własnej roli",
+		})
+	case errors.Is(err, domain.ErrInvalidMFAToken):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "invalid-mfa-token",
+			Message: "Sesja logowania wygasła - zaloguj się ponownie",
+		})
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "invalid-mfa-code",
+			Message: "Nieprawidłowy kod weryfikacyjny",
+		})
+	case errors.Is(err, domain.ErrMFANotEnabled):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Code:    "mfa-not-enabled",
+			Message: "Weryfikacja dwuetapowa nie jest włączona",
+		})
+	case errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Code:    "mfa-already-enabled",
+			Message: "Weryfikacja dwuetapowa jest już włączona",
+		})
+	case errors.Is(err, domain.ErrMFANotEnrolled):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Code:    "mfa-not-enrolled",
+			Message: "Najpierw rozpocznij konfigurację weryfikacji dwuetapowej",
+		})
 	default:
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "internal-error",
@@ -100,9 +474,33 @@ func handleAuthError(c *gin.Context, err error) {
 type ErrorResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	Field   string `json:"field,omitempty"` // pole, którego dotyczy błąd walidacji
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
 }
 
+// LoginResponse zawiera albo tokeny, albo (przy włączonym MFA) token do drugiego kroku logowania
 type LoginResponse struct {
-	User  *domain.User `json:"user"`
-	Token string       `json:"token"`
+	User  *domain.User `json:"user,omitempty"`
+	Token string       `json:"token,omitempty"` // token dostępowy, zachowany dla zgodności ze starszymi klientami
+	*domain.TokenPair
+	MFARequired bool   `json:"mfaRequired,omitempty"`
+	MFAToken    string `json:"mfaToken,omitempty"`
+}
+
+func newLoginResponse(result *domain.LoginResult) LoginResponse {
+	if result.MFARequired {
+		return LoginResponse{
+			MFARequired: true,
+			MFAToken:    result.MFAToken,
+		}
+	}
+
+	return LoginResponse{
+		User:      result.User,
+		Token:     result.Tokens.AccessToken,
+		TokenPair: result.Tokens,
+	}
 }
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
+)
+
+type EmailChangeHandler struct {
+	emailChangeService *service.EmailChangeService
+}
+
+func NewEmailChangeHandler(emailChangeService *service.EmailChangeService) *EmailChangeHandler {
+	return &EmailChangeHandler{emailChangeService: emailChangeService}
+}
+
+// @Summary Prośba o zmianę adresu email (link trafia na nowy adres)
+// @Accept json
+// @Produce json
+// @Param input body domain.ChangeEmailRequest true "Nowy adres i obecne hasło"
+// @Success 202 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /me/email [post]
+func (h *EmailChangeHandler) RequestChange(c *gin.Context) {
+	var req domain.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+	req.IP = c.ClientIP()
+
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+	if err := h.emailChangeService.RequestChange(c.Request.Context(), userID, &req); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, MessageResponse{
+		Message: "Wysłaliśmy link potwierdzający na nowy adres email",
+	})
+}
+
+// @Summary Potwierdzenie nowego adresu email tokenem z maila
+// @Accept json
+// @Param input body domain.ConfirmEmailChangeRequest true "Token z maila"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/email/confirm [post]
+func (h *EmailChangeHandler) Confirm(c *gin.Context) {
+	var req domain.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	if err := h.emailChangeService.ConfirmChange(c.Request.Context(), req.Token); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
+)
+
+// Największa liczba wpisów dziennika audytu zwracana w jednym żądaniu
+const maxAuditLimit = 200
+
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+}
+
+func NewImpersonationHandler(impersonationService *service.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{impersonationService: impersonationService}
+}
+
+// @Summary Token do działania w imieniu użytkownika
+// @Description Krótkotrwały token z claimem act wskazującym administratora. Domyślnie tylko do odczytu; każde żądanie trafia do dziennika audytu.
+// @Accept json
+// @Produce json
+// @Param id path string true "ID użytkownika"
+// @Param input body domain.ImpersonationRequest true "Uzasadnienie i opcjonalna zgoda na zapis"
+// @Success 201 {object} domain.ImpersonationToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/impersonate [post]
+func (h *ImpersonationHandler) Start(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-user-id",
+			Message: "Nieprawidłowy identyfikator użytkownika",
+		})
+		return
+	}
+
+	var req domain.ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Podaj uzasadnienie (od 10 do 500 znaków)",
+			Field:   "reason",
+		})
+		return
+	}
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	actor, _ := GetClaimsFromContext(c.Request.Context())
+	token, err := h.impersonationService.Start(c.Request.Context(), actor, userID, &req)
+	if err != nil {
+		handleImpersonationError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, token)
+}
+
+// @Summary Dziennik audytu działań w imieniu użytkowników
+// @Produce json
+// @Param actorId query string false "ID administratora"
+// @Param userId query string false "ID użytkownika"
+// @Param tokenId query string false "jti tokenu"
+// @Param limit query int false "Liczba wpisów (domyślnie 50, maksymalnie 200)"
+// @Success 200 {array} domain.AuditEntry
+// @Failure 400 {object} ErrorResponse
+// @Router /admin/audit-log [get]
+func (h *ImpersonationHandler) ListAudit(c *gin.Context) {
+	filter := domain.AuditFilter{TokenID: c.Query("tokenId")}
+
+	var ok bool
+	if filter.ActorID, ok = queryUUID(c, "actorId"); !ok {
+		return
+	}
+	if filter.UserID, ok = queryUUID(c, "userId"); !ok {
+		return
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "invalid-limit",
+				Message: "Limit musi być liczbą od 1 do 200",
+				Field:   "limit",
+			})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.impersonationService.ListAudit(c.Request.Context(), filter)
+	if err != nil {
+		handleImpersonationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// queryUUID odczytuje opcjonalny identyfikator z parametru zapytania.
+// Przy błędnej wartości wysyła odpowiedź 400 i zwraca ok=false.
+func queryUUID(c *gin.Context, param string) (*uuid.UUID, bool) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-user-id",
+			Message: "Nieprawidłowy identyfikator użytkownika",
+			Field:   param,
+		})
+		return nil, false
+	}
+	return &id, true
+}
+
+func handleImpersonationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "user-not-found",
+			Message: "Nie znaleziono użytkownika",
+		})
+	case errors.Is(err, domain.ErrCannotImpersonateSelf):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "cannot-impersonate-self",
+			Message: "Nie można działać w imieniu własnego konta",
+		})
+	case errors.Is(err, domain.ErrCannotImpersonateAdmin):
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Code:    "cannot-impersonate-admin",
+			Message: "Nie można działać w imieniu innego administratora",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "internal-error",
+			Message: "Wystąpił błąd wewnętrzny",
+		})
+	}
+}
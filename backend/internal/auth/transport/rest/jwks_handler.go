@@ -0,0 +1,21 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/keys"
+)
+
+// @Summary Publiczne klucze do weryfikacji tokenów JWT (RFC 7517)
+// @Produce json
+// @Success 200 {object} keys.JWKSet
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(manager *keys.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Krótki cache - po rotacji nowy klucz musi szybko dotrzeć do weryfikujących
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, manager.JWKS())
+	}
+}
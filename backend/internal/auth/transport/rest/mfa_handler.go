@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
+)
+
+type MFAHandler struct {
+	mfaService *service.MFAService
+}
+
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// @Summary Drugi krok logowania - wymiana tokenu MFA i kodu na tokeny
+// @Accept json
+// @Produce json
+// @Param input body domain.MFAVerifyRequest true "Token MFA i kod TOTP lub kod awaryjny"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /auth/mfa/verify [post]
+func (h *MFAHandler) Verify(c *gin.Context) {
+	var req domain.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	result, err := h.mfaService.CompleteLogin(c.Request.Context(), &req)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(result))
+}
+
+// @Summary Rozpoczęcie konfiguracji weryfikacji dwuetapowej
+// @Produce json
+// @Success 200 {object} domain.MFAEnrollment
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/enroll [post]
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+
+	enrollment, err := h.mfaService.BeginEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// @Summary Potwierdzenie konfiguracji kodem z aplikacji
+// @Accept json
+// @Produce json
+// @Param input body domain.MFACodeRequest true "Kod TOTP"
+// @Success 200 {object} domain.RecoveryCodesResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/confirm [post]
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	var req domain.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+
+	codes, err := h.mfaService.ConfirmEnrollment(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Wygenerowanie nowych kodów awaryjnych
+// @Accept json
+// @Produce json
+// @Param input body domain.MFACodeRequest true "Kod TOTP lub kod awaryjny"
+// @Success 200 {object} domain.RecoveryCodesResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req domain.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Wyłączenie weryfikacji dwuetapowej
+// @Accept json
+// @Param input body domain.MFACodeRequest true "Kod TOTP lub kod awaryjny"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req domain.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	userID, _ := GetUserIDFromContext(c.Request.Context())
+
+	if err := h.mfaService.Disable(c.Request.Context(), userID, req.Code); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
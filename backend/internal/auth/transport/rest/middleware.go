@@ -2,21 +2,118 @@ package rest
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/google/uuid"
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
+)
+
+const (
+	userKey   = "user"
+	claimsKey = "claims"
+
+	apiKeyHeader = "X-API-Key"
 )
 
-const userKey = "user"
+// AuthMiddleware uwierzytelnia żądanie tokenem JWT (nagłówek Authorization: Bearer)
+// albo osobistym kluczem API (nagłówek X-API-Key)
+func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var claims *domain.AccessClaims
+		var err error
+
+		if apiKey := c.GetHeader(apiKeyHeader); apiKey != "" {
+			claims, err = authService.ValidateAPIKey(c.Request.Context(), apiKey)
+		} else {
+			authHeader := c.GetHeader("Authorization")
+			if authHeader == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+					Code:    "missing-token",
+					Message: "Brak tokenu autoryzacyjnego",
+				})
+				return
+			}
+
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			claims, err = authService.ValidateAccessToken(c.Request.Context(), tokenString)
+		}
+
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidToken):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+					Code:    "invalid-token",
+					Message: "Nieprawidłowy lub wygasły token",
+				})
+			case errors.Is(err, domain.ErrInvalidAPIKey):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+					Code:    "invalid-api-key",
+					Message: "Nieprawidłowy, wygasły lub unieważniony klucz API",
+				})
+			case errors.Is(err, domain.ErrTokenRevoked):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+					Code:    "token-revoked",
+					Message: "Token został unieważniony",
+				})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{
+					Code:    "internal-error",
+					Message: "Wystąpił błąd wewnętrzny",
+				})
+			}
+			return
+		}
+
+		authService.RecordSessionActivity(c.Request.Context(), claims, domain.SessionClient{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+
+		ctx := context.WithValue(c.Request.Context(), userKey, claims.UserID)
+		ctx = context.WithValue(ctx, claimsKey, claims)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// OptionalAuth uwierzytelnia żądanie tylko wtedy, gdy niesie token lub klucz API,
+// a anonimowe przepuszcza dalej. Dla publicznych endpointów, które zalogowanemu
+// pokazują więcej (np. właścicielowi jego usunięte ogłoszenie). Nieprawidłowy
+// token jest odrzucany jak w AuthMiddleware, a nie traktowany jak brak tokenu.
+func OptionalAuth(authService *service.AuthService) gin.HandlerFunc {
+	authenticate := AuthMiddleware(authService)
+	return func(c *gin.Context) {
+		if c.GetHeader(apiKeyHeader) == "" && c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
+func GetUserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Value(userKey).(uuid.UUID)
+	return userID, ok
+}
+
+func GetClaimsFromContext(ctx context.Context) (*domain.AccessClaims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*domain.AccessClaims)
+	return claims, ok
+}
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// RequireVerifiedEmail przepuszcza tylko użytkowników z potwierdzonym adresem email.
+// Musi działać po AuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		claims, ok := GetClaimsFromContext(c.Request.Context())
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
 				Code:    "missing-token",
 				Message: "Brak tokenu autoryzacyjnego",
@@ -24,47 +121,144 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("nieoczekiwana metoda podpisu: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
+		if !claims.EmailVerified {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+				Code:    "email-not-verified",
+				Message: "Potwierdź adres email, aby korzystać z tej funkcji",
+			})
+			return
+		}
 
-		if err != nil || !token.Valid {
+		c.Next()
+	}
+}
+
+// RequireRole przepuszcza tylko użytkowników z jedną z podanych ról.
+// Musi działać po AuthMiddleware.
+func RequireRole(roles ...domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaimsFromContext(c.Request.Context())
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
-				Code:    "invalid-token",
-				Message: "Nieprawidłowy lub wygasły token",
+				Code:    "missing-token",
+				Message: "Brak tokenu autoryzacyjnego",
 			})
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
+		for _, role := range roles {
+			if claims.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+			Code:    "insufficient-role",
+			Message: "Brak uprawnień do wykonania tej operacji",
+		})
+	}
+}
+
+// RequireSession odrzuca żądania uwierzytelnione kluczem API, tokenem
+// aplikacji OAuth lub tokenem administratora działającego w imieniu
+// użytkownika. Chroni operacje na danych logowania, żeby wyciek klucza albo
+// tokenu nie pozwalał przejąć konta.
+// Musi działać po AuthMiddleware.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaimsFromContext(c.Request.Context())
 		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
-				Code:    "invalid-token-claims",
-				Message: "Nieprawidłowy format tokenu",
+				Code:    "missing-token",
+				Message: "Brak tokenu autoryzacyjnego",
 			})
 			return
 		}
 
-		userID, err := uuid.Parse(claims["sub"].(string))
-		if err != nil {
+		if claims.APIKeyID != nil || claims.ClientID != "" || claims.IsImpersonated() {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+				Code:    "session-required",
+				Message: "Ta operacja wymaga zalogowania - klucz API, token aplikacji ani token administratora nie wystarczy",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScope przepuszcza tylko żądania, których token lub klucz API obejmuje
+// wszystkie podane zakresy. Musi działać po AuthMiddleware.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaimsFromContext(c.Request.Context())
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
-				Code:    "invalid-user-id",
-				Message: "Nieprawidłowy identyfikator użytkownika w tokenie",
+				Code:    "missing-token",
+				Message: "Brak tokenu autoryzacyjnego",
 			})
 			return
 		}
 
-		ctx := context.WithValue(c.Request.Context(), userKey, userID)
-		c.Request = c.Request.WithContext(ctx)
+		for _, scope := range scopes {
+			if !claims.HasScope(scope) {
+				c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, domain.FormatScope(scopes)))
+				c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+					Code:    "insufficient-scope",
+					Message: "Token nie obejmuje wymaganego zakresu uprawnień: " + scope,
+				})
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
 
-func GetUserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
-	userID, ok := ctx.Value(userKey).(uuid.UUID)
-	return userID, ok
+// ImpersonationGuard obsługuje tokeny administratora działającego w imieniu
+// użytkownika: zapisuje każde żądanie w dzienniku audytu i odrzuca operacje
+// zapisu, jeśli token jest tylko do odczytu. Pozostałe żądania przepuszcza bez zmian.
+// Musi działać po AuthMiddleware.
+func ImpersonationGuard(impersonationService *service.ImpersonationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaimsFromContext(c.Request.Context())
+		if !ok || !claims.IsImpersonated() {
+			c.Next()
+			return
+		}
+
+		c.Header("X-Impersonated-By", claims.ActorID.String())
+
+		if claims.ReadOnly && !isSafeMethod(c.Request.Method) {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+				Code:    "impersonation-read-only",
+				Message: "Token administratora pozwala tylko na odczyt",
+			})
+		} else {
+			c.Next()
+		}
+
+		// Wpis powstaje także dla odrzuconych żądań. Odpowiedź jest już
+		// wysłana, więc błąd zapisu możemy tylko zalogować.
+		err := impersonationService.RecordRequest(context.WithoutCancel(c.Request.Context()), claims, &domain.AuditEntry{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.RequestURI(),
+			Status:    c.Writer.Status(),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		if err != nil {
+			log.Printf("Nie udało się zapisać żądania w dzienniku audytu: %v", err)
+		}
+	}
+}
+
+// isSafeMethod informuje, czy metoda HTTP nie zmienia danych (RFC 9110 9.2.1)
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
+)
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// OIDCProvidersResponse zawiera nazwy dostawców do wyświetlenia przycisków "Zaloguj przez..."
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// @Summary Lista skonfigurowanych dostawców logowania OpenID Connect
+// @Produce json
+// @Success 200 {object} OIDCProvidersResponse
+// @Router /auth/oidc/providers [get]
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, OIDCProvidersResponse{Providers: h.oidcService.Providers()})
+}
+
+// @Summary Rozpoczęcie logowania przez dostawcę OpenID Connect
+// @Description Zwraca adres logowania u dostawcy; frontend zapamiętuje state i przekierowuje użytkownika
+// @Produce json
+// @Param provider path string true "Nazwa dostawcy"
+// @Success 200 {object} domain.OIDCStart
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /auth/oidc/{provider}/start [post]
+func (h *OIDCHandler) Start(c *gin.Context) {
+	start, err := h.oidcService.Start(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, start)
+}
+
+// @Summary Dokończenie logowania przez dostawcę OpenID Connect
+// @Description Przyjmuje code i state z przekierowania; zakłada konto przy pierwszym logowaniu
+// @Accept json
+// @Produce json
+// @Param input body domain.OIDCCallbackRequest true "Parametry z przekierowania"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /auth/oidc/callback [post]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req domain.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	result, err := h.oidcService.Callback(c.Request.Context(), &req)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(result))
+}
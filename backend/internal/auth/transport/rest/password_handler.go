@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
+)
+
+type PasswordResetHandler struct {
+	resetService *service.PasswordResetService
+}
+
+func NewPasswordResetHandler(resetService *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{resetService: resetService}
+}
+
+// @Summary Prośba o link do resetu hasła
+// @Accept json
+// @Produce json
+// @Param input body domain.ForgotPasswordRequest true "Adres email konta"
+// @Success 202 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/password/forgot [post]
+func (h *PasswordResetHandler) Forgot(c *gin.Context) {
+	var req domain.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	// Odpowiedź jest zawsze taka sama - nie zdradzamy, czy konto istnieje
+	if err := h.resetService.RequestReset(c.Request.Context(), req.Email); err != nil {
+		log.Printf("Błąd wysyłki linku do resetu hasła: %v", err)
+	}
+
+	c.JSON(http.StatusAccepted, MessageResponse{
+		Message: "Jeśli konto z tym adresem istnieje, wysłaliśmy na nie link do resetu hasła",
+	})
+}
+
+// @Summary Ustawienie nowego hasła za pomocą tokenu z maila
+// @Accept json
+// @Param input body domain.ResetPasswordRequest true "Token i nowe hasło"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/password/reset [post]
+func (h *PasswordResetHandler) Reset(c *gin.Context) {
+	var req domain.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	if err := h.resetService.ResetPassword(c.Request.Context(), &req); err != nil {
+		if handlePasswordPolicyError(c, err, "password") {
+			return
+		}
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
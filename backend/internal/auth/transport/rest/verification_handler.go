@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/auth/service"
+)
+
+type EmailVerificationHandler struct {
+	verificationService *service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(verificationService *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: verificationService}
+}
+
+// @Summary Potwierdzenie adresu email tokenem z maila
+// @Accept json
+// @Param input body domain.VerifyEmailRequest true "Token weryfikacyjny"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *EmailVerificationHandler) Verify(c *gin.Context) {
+	var req domain.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	if err := h.verificationService.Verify(c.Request.Context(), req.Token); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Ponowne wysłanie linku weryfikacyjnego
+// @Success 202 {object} MessageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /auth/verify-email/resend [post]
+func (h *EmailVerificationHandler) Resend(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "missing-token",
+			Message: "Brak tokenu autoryzacyjnego",
+		})
+		return
+	}
+
+	if err := h.verificationService.Resend(c.Request.Context(), userID); err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, MessageResponse{
+		Message: "Jeśli adres nie był jeszcze potwierdzony, wysłaliśmy nowy link weryfikacyjny",
+	})
+}
@@ -1,9 +1,52 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/pkg/geo"
+	"github.com/Ex6linz/BookSwap/backend/pkg/isbn"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+var (
+	ErrBookNotFound     = errors.New("book not found")
+	ErrNotBookOwner     = errors.New("not the book owner")
+	ErrCategoryNotFound = errors.New("category not found")
+
+	ErrImageNotFound    = errors.New("image not found")
+	ErrTooManyImages    = errors.New("too many images")
+	ErrImageTooLarge    = errors.New("image too large")
+	ErrUnsupportedImage = errors.New("unsupported image")
+)
+
+// Statusy ogłoszenia
+const (
+	StatusAvailable = "available"
+	StatusReserved  = "reserved"
+	StatusBorrowed  = "borrowed"
+	// StatusRemoved ukrywa ogłoszenie usunięte przez właściciela lub moderację,
+	// do którego odwołują się transakcje
+	StatusRemoved = "removed"
+)
+
+// Maksymalne długości pól ogłoszenia (w znakach, zgodnie z kolumnami tabeli books)
+const (
+	MaxTitleLength       = 255
+	MaxAuthorLength      = 255
+	MaxDescriptionLength = 5000
+	MaxConditionLength   = 50
+)
+
+// Promień wyszukiwania w pobliżu (parametr near)
+const (
+	DefaultRadiusKm = 25
+	MaxRadiusKm     = 500
 )
 
 // Book reprezentuje książkę w systemie
@@ -12,7 +55,7 @@ type Book struct {
 	Title       string    `json:"title"`
 	Author      string    `json:"author"`
 	Description string    `json:"description,omitempty"`
-	ISBN        string    `json:"isbn,omitempty"`
+	ISBN        string    `json:"isbn,omitempty"` // ISBN-13 bez myślników
 	CategoryID  uuid.UUID `json:"categoryId"`
 	Category    *Category `json:"category,omitempty"`
 	Condition   string    `json:"condition"`
@@ -20,8 +63,29 @@ type Book struct {
 	Owner       *User     `json:"owner,omitempty"`
 	Status      string    `json:"status"`
 	ImageURLs   []string  `json:"imageUrls,omitempty"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	// ThumbnailURLs to miniatury zdjęć w tej samej kolejności co ImageURLs
+	ThumbnailURLs []string  `json:"thumbnailUrls,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+
+	// Location to zgrubne miejsce odbioru: punkt z ogłoszenia albo lokalizacja
+	// właściciela (zob. geo.Point.Public). Distance jest uzupełniane przy
+	// wyszukiwaniu w pobliżu (parametr near).
+	Location    *geo.Point `json:"location,omitempty"`
+	Distance    *float64   `json:"distanceKm,omitempty"`
+	PickupPoint *geo.Point `json:"-"` // dokładny punkt odbioru, tylko przy zapisie
+
+	// Relevance i Highlights są uzupełniane tylko w wynikach wyszukiwania (parametr q)
+	Relevance  float64         `json:"relevance,omitempty"`
+	Highlights *BookHighlights `json:"highlights,omitempty"`
+}
+
+// BookHighlights zawiera fragmenty ogłoszenia pasujące do wyszukiwania.
+// Tekst jest zakodowany jak HTML, a dopasowania otacza znacznik <mark>.
+type BookHighlights struct {
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Description string `json:"description,omitempty"`
 }
 
 // User w kontekście książki (uproszczony)
@@ -39,41 +103,215 @@ type Category struct {
 	Description string    `json:"description,omitempty"`
 }
 
+// Rozmiary zdjęć (dłuższy bok w pikselach). ImageURL wskazuje największy.
+const (
+	ImageSizeLarge     = 1600
+	ImageSizeMedium    = 800
+	ImageSizeThumbnail = 320
+)
+
 // BookImage reprezentuje zdjęcie książki
 type BookImage struct {
-	ID        uuid.UUID `json:"id"`
-	BookID    uuid.UUID `json:"bookId"`
-	ImageURL  string    `json:"imageUrl"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID           uuid.UUID `json:"id"`
+	BookID       uuid.UUID `json:"bookId"`
+	ImageURL     string    `json:"imageUrl"`
+	MediumURL    string    `json:"mediumUrl,omitempty"`
+	ThumbnailURL string    `json:"thumbnailUrl,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	StorageKey   string    `json:"-"` // prefiks kluczy wariantów w magazynie plików
+	CreatedAt    time.Time `json:"createdAt"`
 }
 
 // BookCreate reprezentuje dane do utworzenia nowej książki
 type BookCreate struct {
-	Title       string    `json:"title" binding:"required"`
-	Author      string    `json:"author" binding:"required"`
-	Description string    `json:"description"`
-	ISBN        string    `json:"isbn"`
-	CategoryID  uuid.UUID `json:"categoryId" binding:"required"`
-	Condition   string    `json:"condition" binding:"required"`
-	ImageBase64 []string  `json:"imageBase64,omitempty"`
+	Title       string     `json:"title" binding:"required"`
+	Author      string     `json:"author" binding:"required"`
+	Description string     `json:"description"`
+	ISBN        string     `json:"isbn"` // ISBN-10 lub ISBN-13, zapisywany jako ISBN-13
+	CategoryID  uuid.UUID  `json:"categoryId" binding:"required"`
+	Condition   string     `json:"condition" binding:"required"`
+	PickupPoint *geo.Point `json:"pickupPoint"` // domyślnie lokalizacja właściciela
 }
 
-// BookUpdate reprezentuje dane do aktualizacji książki
+// BookUpdate reprezentuje dane do aktualizacji książki. Pominięte pola (nil)
+// zostają bez zmian, pusty tekst czyści pole opcjonalne.
 type BookUpdate struct {
-	Title       string    `json:"title"`
-	Author      string    `json:"author"`
-	Description string    `json:"description"`
-	ISBN        string    `json:"isbn"`
-	CategoryID  uuid.UUID `json:"categoryId"`
-	Condition   string    `json:"condition"`
-	Status      string    `json:"status"`
+	Title       *string    `json:"title"`
+	Author      *string    `json:"author"`
+	Description *string    `json:"description"`
+	ISBN        *string    `json:"isbn"`
+	CategoryID  *uuid.UUID `json:"categoryId"`
+	Condition   *string    `json:"condition"`
+	Status      *string    `json:"status"` // available, reserved lub borrowed
+	PickupPoint *geo.Point `json:"pickupPoint"`
+
+	// RemovePickupPoint przywraca lokalizację właściciela jako miejsce odbioru
+	RemovePickupPoint bool `json:"removePickupPoint"`
 }
 
 // BookFilter reprezentuje parametry filtrowania książek
 type BookFilter struct {
-	Title      string    `form:"title"`
-	Author     string    `form:"author"`
-	CategoryID uuid.UUID `form:"categoryId"`
-	OwnerID    uuid.UUID `form:"ownerId"`
-	Status     string    `form:"status"`
+	Query          string     `form:"q" binding:"omitempty,max=200"` // wyszukiwanie pełnotekstowe
+	Title          string     `form:"title"`
+	Author         string     `form:"author"`
+	ISBN           string     `form:"isbn"` // ISBN-10 lub ISBN-13, także z myślnikami
+	CategoryID     *uuid.UUID `form:"-"`    // uzupełniany przez handler
+	OwnerID        *uuid.UUID `form:"-"`    // uzupełniany przez handler
+	Status         string     `form:"status"`
+	Near           string     `form:"near"`     // "szerokość,długość"
+	RadiusKm       float64    `form:"radiusKm"` // domyślnie DefaultRadiusKm
+	NearPoint      *geo.Point `form:"-"`        // uzupełniany przez ParseNear
+	IncludeRemoved bool       `form:"-"`        // tylko na liście własnych ogłoszeń
+
+	pagination.Request
+}
+
+// ValidationError opisuje nieprawidłową wartość konkretnego pola
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+// Normalize przycina białe znaki i sprawdza pola nowego ogłoszenia
+func (b *BookCreate) Normalize() error {
+	for _, field := range []*string{&b.Title, &b.Author, &b.Description, &b.ISBN, &b.Condition} {
+		*field = strings.TrimSpace(*field)
+	}
+
+	if b.CategoryID == uuid.Nil {
+		return &ValidationError{Field: "categoryId", Reason: "required"}
+	}
+	if b.PickupPoint != nil && b.PickupPoint.Validate() != nil {
+		return &ValidationError{Field: "pickupPoint", Reason: "out of range"}
+	}
+	if err := normalizeISBN(&b.ISBN); err != nil {
+		return err
+	}
+	return validateFields(&b.Title, &b.Author, &b.Description, &b.Condition)
+}
+
+// Normalize przycina białe znaki i sprawdza zmieniane pola
+func (u *BookUpdate) Normalize() error {
+	for _, field := range []*string{u.Title, u.Author, u.Description, u.ISBN, u.Condition, u.Status} {
+		if field != nil {
+			*field = strings.TrimSpace(*field)
+		}
+	}
+
+	if u.CategoryID != nil && *u.CategoryID == uuid.Nil {
+		return &ValidationError{Field: "categoryId", Reason: "required"}
+	}
+	if u.Status != nil && !IsOwnerStatus(*u.Status) {
+		return &ValidationError{Field: "status", Reason: "unknown status"}
+	}
+	if u.PickupPoint != nil && u.RemovePickupPoint {
+		return &ValidationError{Field: "pickupPoint", Reason: "cannot be set and removed at once"}
+	}
+	if u.PickupPoint != nil && u.PickupPoint.Validate() != nil {
+		return &ValidationError{Field: "pickupPoint", Reason: "out of range"}
+	}
+	if u.ISBN != nil {
+		if err := normalizeISBN(u.ISBN); err != nil {
+			return err
+		}
+	}
+	return validateFields(u.Title, u.Author, u.Description, u.Condition)
+}
+
+// IsEmpty informuje, że żądanie niczego nie zmienia
+func (u *BookUpdate) IsEmpty() bool {
+	return u.Title == nil && u.Author == nil && u.Description == nil && u.ISBN == nil &&
+		u.CategoryID == nil && u.Condition == nil && u.Status == nil &&
+		u.PickupPoint == nil && !u.RemovePickupPoint
+}
+
+// ParseNear odczytuje punkt wyszukiwania w pobliżu i sprawdza promień
+func (f *BookFilter) ParseNear() error {
+	if f.Near == "" {
+		if f.RadiusKm != 0 {
+			return &ValidationError{Field: "radiusKm", Reason: "requires near"}
+		}
+		return nil
+	}
+
+	point, err := geo.ParsePoint(f.Near)
+	if err != nil {
+		return &ValidationError{Field: "near", Reason: "expected latitude,longitude"}
+	}
+	f.NearPoint = &point
+
+	if f.RadiusKm == 0 {
+		f.RadiusKm = DefaultRadiusKm
+	}
+	if f.RadiusKm < 0 || f.RadiusKm > MaxRadiusKm {
+		return &ValidationError{Field: "radiusKm", Reason: fmt.Sprintf("must be between 0 and %d", MaxRadiusKm)}
+	}
+	return nil
+}
+
+// ParseISBN sprowadza filtr ISBN do postaci zapisanej w bazie
+func (f *BookFilter) ParseISBN() error {
+	f.ISBN = strings.TrimSpace(f.ISBN)
+	return normalizeISBN(&f.ISBN)
+}
+
+// IsOwnerStatus informuje, czy właściciel może sam ustawić ten status
+func IsOwnerStatus(status string) bool {
+	switch status {
+	case StatusAvailable, StatusReserved, StatusBorrowed:
+		return true
+	}
+	return false
+}
+
+// normalizeISBN zamienia niepusty ISBN na ISBN-13; pusty oznacza brak numeru
+func normalizeISBN(value *string) error {
+	if *value == "" {
+		return nil
+	}
+
+	code, err := isbn.Normalize(*value)
+	if err != nil {
+		return &ValidationError{Field: "isbn", Reason: err.Error()}
+	}
+	*value = code
+	return nil
+}
+
+func validateFields(title, author, description, condition *string) error {
+	required := []struct {
+		field string
+		value *string
+	}{
+		{"title", title},
+		{"author", author},
+		{"condition", condition},
+	}
+	for _, r := range required {
+		if r.value != nil && *r.value == "" {
+			return &ValidationError{Field: r.field, Reason: "required"}
+		}
+	}
+
+	limits := []struct {
+		field string
+		value *string
+		max   int
+	}{
+		{"title", title, MaxTitleLength},
+		{"author", author, MaxAuthorLength},
+		{"description", description, MaxDescriptionLength},
+		{"condition", condition, MaxConditionLength},
+	}
+	for _, l := range limits {
+		if l.value != nil && utf8.RuneCountInString(*l.value) > l.max {
+			return &ValidationError{Field: l.field, Reason: fmt.Sprintf("longer than %d characters", l.max)}
+		}
+	}
+	return nil
 }
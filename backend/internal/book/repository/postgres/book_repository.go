@@ -0,0 +1,416 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/book/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/geo"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+type BookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBookRepository(db *pgxpool.Pool) *BookRepository {
+	return &BookRepository{db: db}
+}
+
+func (r *BookRepository) Create(ctx context.Context, book *domain.Book) error {
+	query := `INSERT INTO books
+		(id, title, author, description, isbn, category_id, condition, owner_id, status,
+		pickup_latitude, pickup_longitude, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	latitude, longitude := pointColumns(book.PickupPoint)
+	_, err := r.db.Exec(ctx, query,
+		book.ID,
+		book.Title,
+		book.Author,
+		book.Description,
+		book.ISBN,
+		book.CategoryID,
+		book.Condition,
+		book.OwnerID,
+		book.Status,
+		latitude,
+		longitude,
+		book.CreatedAt,
+		book.UpdatedAt,
+	)
+	if isForeignKeyError(err) {
+		return domain.ErrCategoryNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create book: %w", err)
+	}
+	return nil
+}
+
+func (r *BookRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Book, error) {
+	query := `SELECT ` + bookColumns + ` FROM ` + bookTables + ` WHERE b.id = $1`
+
+	book, err := scanBook(r.db.QueryRow(ctx, query, id))
+	if err != nil && !errors.Is(err, domain.ErrBookNotFound) {
+		return nil, fmt.Errorf("failed to get book by id: %w", err)
+	}
+	return book, err
+}
+
+// List zwraca stronę ogłoszeń pasujących do filtra. Przy wyszukiwaniu
+// pełnotekstowym wyniki mają ocenę trafności i wyróżnione fragmenty. Polskie
+// słowa nie są sprowadzane do formy podstawowej (migracja 016) - odmiany
+// łapie tylko dopasowanie początków słów.
+func (r *BookRepository) List(ctx context.Context, filter domain.BookFilter, page pagination.Params) (*pagination.Page[domain.Book], error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	columns := bookColumns
+	tables := bookTables
+
+	search := filter.Query != ""
+	if search {
+		tsquery := searchQuery(filter.Query)
+		if tsquery == "" {
+			return pagination.NewPage[domain.Book](nil, page, bookSortKey(page.Sort))
+		}
+
+		args = append(args, tsquery, titleHeadlineOptions, descriptionHeadlineOptions)
+		n := len(args) - 2
+		tables += fmt.Sprintf(`
+	CROSS JOIN (SELECT to_tsquery('simple', $%d) || to_tsquery('english', $%d) AS query) q`, n, n)
+		columns += fmt.Sprintf(`,
+	`+relevanceExpr+`,
+	ts_headline('simple', b.title, q.query, $%d),
+	ts_headline('simple', b.author, q.query, $%d),
+	ts_headline('simple', COALESCE(b.description, ''), q.query, $%d)`, n+1, n+1, n+2)
+		conditions = append(conditions, `b.search_vector @@ q.query`)
+	}
+
+	near := filter.NearPoint != nil
+	if near {
+		args = append(args, filter.NearPoint.Latitude, filter.NearPoint.Longitude)
+		n := len(args) - 1
+		tables += `
+	CROSS JOIN LATERAL (SELECT ` + geo.DistanceSQL("loc.lat", "loc.lng", n, n+1) + ` AS km) d`
+		columns += `,
+	d.km`
+		addCondition("d.km <= $%d", filter.RadiusKm)
+
+		// Prostokąt wokół punktu odsiewa większość wierszy przed liczeniem odległości
+		box := geo.BoundingBox(*filter.NearPoint, filter.RadiusKm)
+		addCondition("loc.lat >= $%d", box.MinLatitude)
+		addCondition("loc.lat <= $%d", box.MaxLatitude)
+		if box.BoundedLongitude {
+			addCondition("loc.lng >= $%d", box.MinLongitude)
+			addCondition("loc.lng <= $%d", box.MaxLongitude)
+		}
+	}
+
+	if !filter.IncludeRemoved {
+		addCondition("b.status <> $%d", domain.StatusRemoved)
+	}
+	if filter.Title != "" {
+		addCondition("b.title ILIKE $%d", containsPattern(filter.Title))
+	}
+	if filter.Author != "" {
+		addCondition("b.author ILIKE $%d", containsPattern(filter.Author))
+	}
+	if filter.ISBN != "" {
+		addCondition("b.isbn = $%d", filter.ISBN)
+	}
+	if filter.CategoryID != nil {
+		addCondition("b.category_id = $%d", *filter.CategoryID)
+	}
+	if filter.OwnerID != nil {
+		addCondition("b.owner_id = $%d", *filter.OwnerID)
+	}
+	if filter.Status != "" {
+		addCondition("b.status = $%d", filter.Status)
+	}
+
+	keyset := bookKeysets[page.Sort]
+	if page.After != nil {
+		key, err := decodeBookSortKey(page.After, page.Sort)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, key, page.After.ID)
+		conditions = append(conditions, keyset.After(len(args)-1))
+	}
+
+	query := `SELECT ` + columns + ` FROM ` + tables
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, page.Limit+1)
+	query += fmt.Sprintf(` ORDER BY %s LIMIT $%d`, keyset.OrderBy(), len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list books: %w", err)
+	}
+
+	books, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Book, error) {
+		// Dodatkowe kolumny w kolejności, w jakiej zostały dopisane do zapytania
+		var relevance, distance float64
+		var h domain.BookHighlights
+		var extra []any
+		if search {
+			extra = append(extra, &relevance, &h.Title, &h.Author, &h.Description)
+		}
+		if near {
+			extra = append(extra, &distance)
+		}
+
+		book, err := scanBook(row, extra...)
+		if err != nil {
+			return domain.Book{}, err
+		}
+
+		if search {
+			h.Title, h.Author, h.Description = markHighlights(h.Title), markHighlights(h.Author), markHighlights(h.Description)
+			book.Relevance = relevance
+			book.Highlights = &h
+		}
+		if near {
+			book.Distance = &distance
+		}
+		return *book, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list books: %w", err)
+	}
+	return pagination.NewPage(books, page, bookSortKey(page.Sort))
+}
+
+// Update zmienia tylko przekazane pola ogłoszenia należącego do ownerID
+func (r *BookRepository) Update(ctx context.Context, id, ownerID uuid.UUID, update *domain.BookUpdate, now time.Time) error {
+	query := `UPDATE books SET
+			title = COALESCE($3, title),
+			author = COALESCE($4, author),
+			description = CASE WHEN $5::text IS NULL THEN description ELSE NULLIF($5, '') END,
+			isbn = CASE WHEN $6::text IS NULL THEN isbn ELSE NULLIF($6, '') END,
+			category_id = COALESCE($7, category_id),
+			condition = COALESCE($8, condition),
+			status = COALESCE($9, status),
+			pickup_latitude = CASE WHEN $10 THEN NULL ELSE COALESCE($11, pickup_latitude) END,
+			pickup_longitude = CASE WHEN $10 THEN NULL ELSE COALESCE($12, pickup_longitude) END,
+			updated_at = $13
+		WHERE id = $1 AND owner_id = $2 AND status <> $14`
+
+	latitude, longitude := pointColumns(update.PickupPoint)
+	tag, err := r.db.Exec(ctx, query,
+		id,
+		ownerID,
+		update.Title,
+		update.Author,
+		update.Description,
+		update.ISBN,
+		update.CategoryID,
+		update.Condition,
+		update.Status,
+		update.RemovePickupPoint,
+		latitude,
+		longitude,
+		now,
+		domain.StatusRemoved,
+	)
+	if isForeignKeyError(err) {
+		return domain.ErrCategoryNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update book: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBookNotFound
+	}
+	return nil
+}
+
+// Delete kasuje ogłoszenie razem ze zdjęciami, a jeśli odwołują się do niego
+// transakcje - tylko je ukrywa. Zwraca true, gdy ogłoszenie zostało skasowane.
+func (r *BookRepository) Delete(ctx context.Context, id, ownerID uuid.UUID, now time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM books b WHERE b.id = $1 AND b.owner_id = $2
+		AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.book_id = b.id)`, id, ownerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete book: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return true, nil
+	}
+
+	tag, err = r.db.Exec(ctx, `UPDATE books SET status = $3, updated_at = $4
+		WHERE id = $1 AND owner_id = $2 AND status <> $3`, id, ownerID, domain.StatusRemoved, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove book: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, domain.ErrBookNotFound
+	}
+	return false, nil
+}
+
+// Zgrubne miejsce odbioru (loc) to punkt z ogłoszenia albo lokalizacja właściciela.
+// Zdjęcia bez miniatury (sprzed wariantów rozmiarów) mają ją równą oryginałowi.
+var bookTables = `books b
+	JOIN users u ON u.id = b.owner_id
+	LEFT JOIN categories c ON c.id = b.category_id
+	CROSS JOIN LATERAL (SELECT
+		` + geo.PublicSQL("COALESCE(b.pickup_latitude, u.latitude)") + ` AS lat,
+		` + geo.PublicSQL("COALESCE(b.pickup_longitude, u.longitude)") + ` AS lng) loc
+	CROSS JOIN LATERAL (SELECT
+		COALESCE(array_agg(i.image_url ORDER BY i.created_at, i.id), '{}') AS urls,
+		COALESCE(array_agg(COALESCE(i.thumbnail_url, i.image_url) ORDER BY i.created_at, i.id), '{}') AS thumbnails
+		FROM book_images i WHERE i.book_id = b.id) img`
+
+const bookColumns = `b.id, b.title, b.author, COALESCE(b.description, ''), COALESCE(b.isbn, ''),
+	b.condition, b.status, b.created_at, b.updated_at,
+	u.id, u.name, COALESCE(u.rating, 0)::float8, COALESCE(u.location, ''),
+	c.id, c.name, c.description, loc.lat, loc.lng, img.urls, img.thumbnails`
+
+// Trafność wyniku wyszukiwania; rzutowanie na float8 pozwala porównać ją
+// z wartością zapisaną w kursorze bez utraty precyzji
+const relevanceExpr = `ts_rank_cd(b.search_vector, q.query)::float8`
+
+// bookKeysets opisuje kolumny sortowania list ogłoszeń
+var bookKeysets = map[string]pagination.Keyset{
+	pagination.SortNewest:    {Key: "b.created_at", ID: "b.id", Desc: true},
+	pagination.SortTitle:     {Key: "b.title", ID: "b.id"},
+	pagination.SortRating:    {Key: "COALESCE(u.rating, 0)::float8", ID: "b.id", Desc: true},
+	pagination.SortRelevance: {Key: relevanceExpr, ID: "b.id", Desc: true},
+	pagination.SortDistance:  {Key: "d.km", ID: "b.id"},
+}
+
+// bookSortKey zwraca funkcję odczytującą z ogłoszenia klucz sortowania dla kursora
+func bookSortKey(sort string) func(domain.Book) (any, uuid.UUID) {
+	return func(book domain.Book) (any, uuid.UUID) {
+		switch sort {
+		case pagination.SortTitle:
+			return book.Title, book.ID
+		case pagination.SortRating:
+			return book.Owner.Rating, book.ID
+		case pagination.SortRelevance:
+			return book.Relevance, book.ID
+		case pagination.SortDistance:
+			return *book.Distance, book.ID
+		default:
+			return book.CreatedAt, book.ID
+		}
+	}
+}
+
+// decodeBookSortKey odczytuje z kursora klucz sortowania w typie kolumny
+func decodeBookSortKey(cursor *pagination.Cursor, sort string) (any, error) {
+	switch sort {
+	case pagination.SortTitle:
+		var title string
+		err := cursor.ScanKey(&title)
+		return title, err
+	case pagination.SortRating, pagination.SortRelevance, pagination.SortDistance:
+		var value float64
+		err := cursor.ScanKey(&value)
+		return value, err
+	default:
+		var createdAt time.Time
+		err := cursor.ScanKey(&createdAt)
+		return createdAt, err
+	}
+}
+
+// scanBook odczytuje kolumny bookColumns; extra przyjmuje kolumny dodane za nimi
+func scanBook(row pgx.Row, extra ...any) (*domain.Book, error) {
+	var book domain.Book
+	var owner domain.User
+	var categoryID *uuid.UUID
+	var categoryName, categoryDescription *string
+	var latitude, longitude *float64
+
+	dest := []any{
+		&book.ID,
+		&book.Title,
+		&book.Author,
+		&book.Description,
+		&book.ISBN,
+		&book.Condition,
+		&book.Status,
+		&book.CreatedAt,
+		&book.UpdatedAt,
+		&owner.ID,
+		&owner.Name,
+		&owner.Rating,
+		&owner.Location,
+		&categoryID,
+		&categoryName,
+		&categoryDescription,
+		&latitude,
+		&longitude,
+		&book.ImageURLs,
+		&book.ThumbnailURLs,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrBookNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	book.OwnerID = owner.ID
+	book.Owner = &owner
+	if latitude != nil && longitude != nil {
+		book.Location = &geo.Point{Latitude: *latitude, Longitude: *longitude}
+	}
+	if categoryID != nil {
+		book.CategoryID = *categoryID
+		book.Category = &domain.Category{ID: *categoryID}
+		if categoryName != nil {
+			book.Category.Name = *categoryName
+		}
+		if categoryDescription != nil {
+			book.Category.Description = *categoryDescription
+		}
+	}
+	return &book, nil
+}
+
+// containsPattern zamienia tekst na wzorzec ILIKE dopasowujący go w dowolnym
+// miejscu, traktując znaki % i _ dosłownie
+func containsPattern(text string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+	return "%" + escaped + "%"
+}
+
+// pointColumns rozkłada opcjonalny punkt na kolumny szerokości i długości
+func pointColumns(point *geo.Point) (*float64, *float64) {
+	if point == nil {
+		return nil, nil
+	}
+	return &point.Latitude, &point.Longitude
+}
+
+func isForeignKeyError(err error) bool {
+	const foreignKeyViolationCode = "23503"
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == foreignKeyViolationCode
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/book/domain"
+)
+
+type ImageRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewImageRepository(db *pgxpool.Pool) *ImageRepository {
+	return &ImageRepository{db: db}
+}
+
+// Create dodaje zdjęcie do ogłoszenia właściciela, o ile ogłoszenie ma mniej
+// niż maxPerBook zdjęć. Blokada wiersza ogłoszenia sprawia, że równoległe
+// przesyłania nie przekroczą limitu.
+func (r *ImageRepository) Create(ctx context.Context, image *domain.BookImage, ownerID uuid.UUID, maxPerBook int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var count int
+	err = tx.QueryRow(ctx, `SELECT (SELECT count(*) FROM book_images i WHERE i.book_id = b.id)
+		FROM books b WHERE b.id = $1 AND b.owner_id = $2 AND b.status <> $3
+		FOR UPDATE OF b`, image.BookID, ownerID, domain.StatusRemoved).Scan(&count)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrBookNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock book: %w", err)
+	}
+	if count >= maxPerBook {
+		return domain.ErrTooManyImages
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO book_images
+		(id, book_id, image_url, medium_url, thumbnail_url, width, height, storage_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		image.ID,
+		image.BookID,
+		image.ImageURL,
+		image.MediumURL,
+		image.ThumbnailURL,
+		image.Width,
+		image.Height,
+		image.StorageKey,
+		image.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create book image: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE books SET updated_at = $2 WHERE id = $1`, image.BookID, image.CreatedAt); err != nil {
+		return fmt.Errorf("failed to update book: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete usuwa zdjęcie z ogłoszenia właściciela i zwraca prefiks jego plików
+// w magazynie (pusty dla zdjęć dodanych przed wariantami rozmiarów)
+func (r *ImageRepository) Delete(ctx context.Context, bookID, imageID, ownerID uuid.UUID) (string, error) {
+	query := `DELETE FROM book_images i USING books b
+		WHERE i.id = $1 AND i.book_id = $2 AND b.id = i.book_id AND b.owner_id = $3 AND b.status <> $4
+		RETURNING COALESCE(i.storage_key, '')`
+
+	var storageKey string
+	err := r.db.QueryRow(ctx, query, imageID, bookID, ownerID, domain.StatusRemoved).Scan(&storageKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrImageNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to delete book image: %w", err)
+	}
+	return storageKey, nil
+}
+
+// ListStorageKeys zwraca prefiksy plików wszystkich zdjęć ogłoszenia
+func (r *ImageRepository) ListStorageKeys(ctx context.Context, bookID uuid.UUID) ([]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT storage_key FROM book_images
+		WHERE book_id = $1 AND storage_key IS NOT NULL`, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list book images: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list book images: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/Ex6linz/BookSwap/backend/pkg/isbn"
+)
+
+// Maksymalna liczba słów branych pod uwagę w wyszukiwaniu
+const maxSearchTerms = 10
+
+// Znaczniki dopasowań z ts_headline. Znaki z obszaru prywatnego Unicode nie
+// występują w zwykłym tekście, więc po zakodowaniu fragmentu jako HTML można
+// je bezpiecznie zamienić na <mark>.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+
+	titleHeadlineOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", HighlightAll=true"
+
+	descriptionHeadlineOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop +
+		`, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+)
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// searchQuery zamienia tekst wpisany przez użytkownika na zapytanie to_tsquery.
+// Muszą wystąpić wszystkie słowa, a każde może być początkiem dłuższego słowa,
+// co pozwala podpowiadać wyniki w trakcie pisania. Pusty wynik oznacza, że
+// w tekście nie ma czego szukać.
+func searchQuery(text string) string {
+	// ISBN w dowolnej postaci jest w indeksie zapisany jako ISBN-13
+	if code, err := isbn.Normalize(text); err == nil {
+		return code
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// markHighlights koduje fragment z ts_headline jako HTML i oznacza dopasowania
+func markHighlights(headline string) string {
+	return highlightReplacer.Replace(html.EscapeString(headline))
+}
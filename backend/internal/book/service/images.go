@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/book/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/imaging"
+)
+
+// ImageRepository interfejs dostępu do zdjęć ogłoszeń
+type ImageRepository interface {
+	Create(ctx context.Context, image *domain.BookImage, ownerID uuid.UUID, maxPerBook int) error
+	Delete(ctx context.Context, bookID, imageID, ownerID uuid.UUID) (string, error)
+	ListStorageKeys(ctx context.Context, bookID uuid.UUID) ([]string, error)
+}
+
+// ImageConfig opisuje limity przesyłanych zdjęć
+type ImageConfig struct {
+	MaxSize    int64 // maksymalny rozmiar pliku w bajtach
+	MaxPerBook int
+	Quality    int // jakość JPEG wariantów (1-100)
+}
+
+// Warianty zapisywane dla każdego zdjęcia, od największego
+const (
+	variantLarge     = "large"
+	variantMedium    = "medium"
+	variantThumbnail = "thumbnail"
+)
+
+var imageVariants = []imaging.Variant{
+	{Name: variantLarge, MaxSize: domain.ImageSizeLarge},
+	{Name: variantMedium, MaxSize: domain.ImageSizeMedium},
+	{Name: variantThumbnail, MaxSize: domain.ImageSizeThumbnail},
+}
+
+// ImageUploadLimit zwraca maksymalny rozmiar przesyłanego pliku w bajtach
+func (s *BookService) ImageUploadLimit() int64 {
+	return s.imageConfig.MaxSize
+}
+
+// UploadImage dodaje zdjęcie do ogłoszenia właściciela. Zdjęcie jest
+// zapisywane w kilku rozmiarach jako JPEG bez metadanych (m.in. bez
+// lokalizacji GPS z aparatu).
+func (s *BookService) UploadImage(ctx context.Context, userID, bookID uuid.UUID, data []byte) (*domain.BookImage, error) {
+	if int64(len(data)) > s.imageConfig.MaxSize {
+		return nil, domain.ErrImageTooLarge
+	}
+
+	book, err := s.ownedBook(ctx, userID, bookID)
+	if err != nil {
+		return nil, err
+	}
+	// Wstępne sprawdzenie limitu oszczędza przetwarzania; wiąże dopiero zapis
+	if len(book.ImageURLs) >= s.imageConfig.MaxPerBook {
+		return nil, domain.ErrTooManyImages
+	}
+
+	variants, err := imaging.Process(data, imageVariants, s.imageConfig.Quality)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return nil, domain.ErrUnsupportedImage
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return nil, domain.ErrImageTooLarge
+	case err != nil:
+		return nil, fmt.Errorf("failed to process image: %w", err)
+	}
+
+	image := &domain.BookImage{
+		ID:        uuid.New(),
+		BookID:    bookID,
+		CreatedAt: time.Now().UTC(),
+	}
+	image.StorageKey = fmt.Sprintf("books/%s/%s", bookID, image.ID)
+
+	for _, variant := range variants {
+		key := variantKey(image.StorageKey, variant.Name)
+		if err := s.store.Put(ctx, key, variant.Data, imaging.ContentType); err != nil {
+			s.deleteImageFiles(ctx, image.StorageKey)
+			return nil, fmt.Errorf("failed to store image: %w", err)
+		}
+
+		switch variant.Name {
+		case variantLarge:
+			image.ImageURL = s.store.URL(key)
+			image.Width, image.Height = variant.Width, variant.Height
+		case variantMedium:
+			image.MediumURL = s.store.URL(key)
+		case variantThumbnail:
+			image.ThumbnailURL = s.store.URL(key)
+		}
+	}
+
+	if err := s.images.Create(ctx, image, userID, s.imageConfig.MaxPerBook); err != nil {
+		s.deleteImageFiles(ctx, image.StorageKey)
+		return nil, err
+	}
+	return image, nil
+}
+
+// DeleteImage usuwa zdjęcie z ogłoszenia właściciela razem z plikami
+func (s *BookService) DeleteImage(ctx context.Context, userID, bookID, imageID uuid.UUID) error {
+	if _, err := s.ownedBook(ctx, userID, bookID); err != nil {
+		return err
+	}
+
+	storageKey, err := s.images.Delete(ctx, bookID, imageID, userID)
+	if err != nil {
+		return err
+	}
+	if storageKey != "" {
+		s.deleteImageFiles(ctx, storageKey)
+	}
+	return nil
+}
+
+// DeleteImageFiles usuwa pliki zdjęć, których wiersze skasowano poza tym
+// serwisem (np. przy anonimizacji konta)
+func (s *BookService) DeleteImageFiles(ctx context.Context, storageKeys []string) {
+	for _, key := range storageKeys {
+		s.deleteImageFiles(ctx, key)
+	}
+}
+
+// deleteImageFiles usuwa pliki wszystkich wariantów zdjęcia. Błędy są tylko
+// logowane - zdjęcie zniknęło już z ogłoszenia, a osierocony plik nie szkodzi.
+func (s *BookService) deleteImageFiles(ctx context.Context, storageKey string) {
+	// Sprzątanie ma się dokończyć także po zerwaniu połączenia przez klienta
+	ctx = context.WithoutCancel(ctx)
+	for _, variant := range imageVariants {
+		if err := s.store.Delete(ctx, variantKey(storageKey, variant.Name)); err != nil {
+			log.Printf("Nie udało się usunąć pliku zdjęcia %s: %v", storageKey, err)
+		}
+	}
+}
+
+func variantKey(storageKey, variant string) string {
+	return storageKey + "/" + variant + ".jpg"
+}
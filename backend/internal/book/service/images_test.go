@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeStorage zapamiętuje zapisane i usunięte klucze
+type fakeStorage struct {
+	files     map[string][]byte
+	deleted   []string
+	deleteErr error
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{files: make(map[string][]byte)}
+}
+
+func (s *fakeStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	s.files[key] = data
+	return nil
+}
+
+func (s *fakeStorage) Delete(ctx context.Context, key string) error {
+	s.deleted = append(s.deleted, key)
+	delete(s.files, key)
+	return s.deleteErr
+}
+
+func (s *fakeStorage) URL(key string) string {
+	return "/uploads/" + key
+}
+
+func TestDeleteImageFilesRemovesAllVariants(t *testing.T) {
+	store := newFakeStorage()
+	svc := &BookService{store: store}
+
+	svc.DeleteImageFiles(context.Background(), []string{"books/b1/i1", "books/b2/i2"})
+
+	want := []string{
+		"books/b1/i1/large.jpg", "books/b1/i1/medium.jpg", "books/b1/i1/thumbnail.jpg",
+		"books/b2/i2/large.jpg", "books/b2/i2/medium.jpg", "books/b2/i2/thumbnail.jpg",
+	}
+	if len(store.deleted) != len(want) {
+		t.Fatalf("deleted %v, want %v", store.deleted, want)
+	}
+	for i := range want {
+		if store.deleted[i] != want[i] {
+			t.Errorf("deleted[%d] = %s, want %s", i, store.deleted[i], want[i])
+		}
+	}
+}
+
+func TestDeleteImageFilesContinuesAfterError(t *testing.T) {
+	store := newFakeStorage()
+	store.deleteErr = errors.New("storage unavailable")
+	svc := &BookService{store: store}
+
+	svc.DeleteImageFiles(context.Background(), []string{"books/b1/i1", "books/b2/i2"})
+
+	if len(store.deleted) != 2*len(imageVariants) {
+		t.Errorf("attempted %d deletions, want %d", len(store.deleted), 2*len(imageVariants))
+	}
+}
@@ -2,25 +2,177 @@ package service
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
+
 	"github.com/Ex6linz/BookSwap/backend/internal/book/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+	"github.com/Ex6linz/BookSwap/backend/pkg/storage"
 )
 
 // BookRepository interfejs definiujący metody dostępu do danych
 type BookRepository interface {
-	GetAll(ctx context.Context) ([]domain.Book, error)
-	// Inne metody
+	Create(ctx context.Context, book *domain.Book) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Book, error)
+	List(ctx context.Context, filter domain.BookFilter, page pagination.Params) (*pagination.Page[domain.Book], error)
+	Update(ctx context.Context, id, ownerID uuid.UUID, update *domain.BookUpdate, now time.Time) error
+	Delete(ctx context.Context, id, ownerID uuid.UUID, now time.Time) (bool, error)
 }
 
 type BookService struct {
-	repo BookRepository
+	repo        BookRepository
+	images      ImageRepository
+	store       storage.Storage
+	imageConfig ImageConfig
 }
 
-func NewBookService(repo BookRepository) *BookService {
+func NewBookService(repo BookRepository, images ImageRepository, store storage.Storage, imageConfig ImageConfig) *BookService {
 	return &BookService{
-		repo: repo,
+		repo:        repo,
+		images:      images,
+		store:       store,
+		imageConfig: imageConfig,
+	}
+}
+
+// CreateBook wystawia nowe ogłoszenie zalogowanego użytkownika
+func (s *BookService) CreateBook(ctx context.Context, ownerID uuid.UUID, req *domain.BookCreate) (*domain.Book, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	book := &domain.Book{
+		ID:          uuid.New(),
+		Title:       req.Title,
+		Author:      req.Author,
+		Description: req.Description,
+		ISBN:        req.ISBN,
+		CategoryID:  req.CategoryID,
+		Condition:   req.Condition,
+		PickupPoint: req.PickupPoint,
+		OwnerID:     ownerID,
+		Status:      domain.StatusAvailable,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repo.Create(ctx, book); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, book.ID)
+}
+
+// GetBook zwraca ogłoszenie. Ogłoszenia usunięte są widoczne tylko dla właściciela.
+func (s *BookService) GetBook(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*domain.Book, error) {
+	book, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if book.Status == domain.StatusRemoved && (viewerID == nil || *viewerID != book.OwnerID) {
+		return nil, domain.ErrBookNotFound
+	}
+	return book, nil
+}
+
+// ListBooks zwraca stronę ogłoszeń pasujących do filtra. Domyślnie są to
+// najnowsze, przy wyszukiwaniu w pobliżu najbliższe, a przy wyszukiwaniu
+// pełnotekstowym najtrafniejsze.
+func (s *BookService) ListBooks(ctx context.Context, filter domain.BookFilter) (*pagination.Page[domain.Book], error) {
+	if filter.Status != "" && !domain.IsOwnerStatus(filter.Status) {
+		return nil, &domain.ValidationError{Field: "status", Reason: "unknown status"}
+	}
+	if err := filter.ParseNear(); err != nil {
+		return nil, err
+	}
+	if err := filter.ParseISBN(); err != nil {
+		return nil, err
+	}
+
+	sorts := []string{pagination.SortNewest, pagination.SortTitle, pagination.SortRating}
+	if filter.Query != "" {
+		sorts = append([]string{pagination.SortRelevance}, sorts...)
+	}
+	if filter.NearPoint != nil {
+		sorts = append([]string{pagination.SortDistance}, sorts...)
+	}
+
+	page, err := filter.Parse(sorts...)
+	if err != nil {
+		return nil, err
 	}
+	return s.repo.List(ctx, filter, page)
 }
 
-func (s *BookService) GetAllBooks(ctx context.Context) ([]domain.Book, error) {
-	return s.repo.GetAll(ctx)
+// ListOwnBooks zwraca stronę ogłoszeń użytkownika, także usuniętych
+func (s *BookService) ListOwnBooks(ctx context.Context, ownerID uuid.UUID, filter domain.BookFilter) (*pagination.Page[domain.Book], error) {
+	filter.OwnerID = &ownerID
+	filter.IncludeRemoved = true
+	return s.ListBooks(ctx, filter)
+}
+
+// UpdateBook zmienia ogłoszenie. Może to zrobić tylko jego właściciel.
+func (s *BookService) UpdateBook(ctx context.Context, userID, id uuid.UUID, update *domain.BookUpdate) (*domain.Book, error) {
+	if err := update.Normalize(); err != nil {
+		return nil, err
+	}
+
+	book, err := s.ownedBook(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.IsEmpty() {
+		return book, nil
+	}
+
+	if err := s.repo.Update(ctx, id, userID, update, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// DeleteBook usuwa ogłoszenie właściciela razem z plikami zdjęć. Ogłoszenie
+// z historią transakcji jest tylko ukrywane, żeby transakcje nie straciły
+// powiązanej książki.
+func (s *BookService) DeleteBook(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.ownedBook(ctx, userID, id); err != nil {
+		return err
+	}
+
+	// Klucze trzeba odczytać przed skasowaniem, bo zdjęcia znikną razem z ogłoszeniem
+	storageKeys, err := s.images.ListStorageKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, id, userID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if deleted {
+		for _, key := range storageKeys {
+			s.deleteImageFiles(ctx, key)
+		}
+	}
+	return nil
+}
+
+// ownedBook zwraca ogłoszenie, które użytkownik może zmieniać. Ogłoszenia
+// usuniętego nie da się przywrócić zmianą statusu.
+func (s *BookService) ownedBook(ctx context.Context, userID, id uuid.UUID) (*domain.Book, error) {
+	book, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if book.Status == domain.StatusRemoved {
+		return nil, domain.ErrBookNotFound
+	}
+	if book.OwnerID != userID {
+		return nil, domain.ErrNotBookOwner
+	}
+	return book, nil
 }
@@ -0,0 +1,428 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	"github.com/Ex6linz/BookSwap/backend/internal/book/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/book/service"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+type BookHandler struct {
+	bookService *service.BookService
+}
+
+func NewBookHandler(bookService *service.BookService) *BookHandler {
+	return &BookHandler{bookService: bookService}
+}
+
+// @Summary Wystawienie nowego ogłoszenia
+// @Accept json
+// @Produce json
+// @Param input body domain.BookCreate true "Dane książki"
+// @Success 201 {object} domain.Book
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 401 {object} authRest.ErrorResponse
+// @Router /books [post]
+func (h *BookHandler) Create(c *gin.Context) {
+	var req domain.BookCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	book, err := h.bookService.CreateBook(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleBookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, book)
+}
+
+// @Summary Szczegóły ogłoszenia
+// @Description Token jest opcjonalny - właściciel z tokenem widzi także swoje usunięte ogłoszenie.
+// @Produce json
+// @Param id path string true "ID książki"
+// @Success 200 {object} domain.Book
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /books/{id} [get]
+func (h *BookHandler) Get(c *gin.Context) {
+	bookID, ok := pathBookID(c)
+	if !ok {
+		return
+	}
+
+	var viewerID *uuid.UUID
+	if userID, ok := authRest.GetUserIDFromContext(c.Request.Context()); ok {
+		viewerID = &userID
+	}
+
+	book, err := h.bookService.GetBook(c.Request.Context(), viewerID, bookID)
+	if err != nil {
+		handleBookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// @Summary Lista ogłoszeń
+// @Description Z parametrem q wyniki są domyślnie sortowane według trafności i zawierają pole highlights,
+// @Description a z parametrem near - według odległości i zawierają pole distanceKm. Lokalizacje są zgrubne (ok. 1 km).
+// @Produce json
+// @Param q query string false "Wyszukiwanie w tytule, autorze, opisie i ISBN"
+// @Param title query string false "Fragment tytułu"
+// @Param author query string false "Fragment nazwiska autora"
+// @Param isbn query string false "ISBN-10 lub ISBN-13 (z myślnikami lub bez)"
+// @Param categoryId query string false "ID kategorii"
+// @Param ownerId query string false "ID właściciela"
+// @Param status query string false "Status ogłoszenia"
+// @Param near query string false "Punkt wyszukiwania w pobliżu: szerokość,długość"
+// @Param radiusKm query number false "Promień wyszukiwania w km (domyślnie 25, maks. 500)"
+// @Param sort query string false "newest, title, rating, relevance (przy q) lub distance (przy near)"
+// @Param limit query int false "Liczba wyników (domyślnie 20, maks. 100)"
+// @Param cursor query string false "Kursor nextCursor z poprzedniej strony"
+// @Success 200 {object} pagination.Page[domain.Book]
+// @Failure 400 {object} authRest.ErrorResponse
+// @Router /books [get]
+func (h *BookHandler) List(c *gin.Context) {
+	filter, ok := bindBookFilter(c)
+	if !ok {
+		return
+	}
+
+	ownerID, ok := queryUUID(c, "ownerId")
+	if !ok {
+		return
+	}
+	filter.OwnerID = ownerID
+
+	books, err := h.bookService.ListBooks(c.Request.Context(), filter)
+	if err != nil {
+		handleBookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, books)
+}
+
+// @Summary Ogłoszenia zalogowanego użytkownika (także usunięte)
+// @Produce json
+// @Param q query string false "Wyszukiwanie w tytule, autorze, opisie i ISBN"
+// @Param title query string false "Fragment tytułu"
+// @Param author query string false "Fragment nazwiska autora"
+// @Param isbn query string false "ISBN-10 lub ISBN-13 (z myślnikami lub bez)"
+// @Param categoryId query string false "ID kategorii"
+// @Param status query string false "Status ogłoszenia"
+// @Param near query string false "Punkt wyszukiwania w pobliżu: szerokość,długość"
+// @Param radiusKm query number false "Promień wyszukiwania w km (domyślnie 25, maks. 500)"
+// @Param sort query string false "newest, title, rating, relevance (przy q) lub distance (przy near)"
+// @Param limit query int false "Liczba wyników (domyślnie 20, maks. 100)"
+// @Param cursor query string false "Kursor nextCursor z poprzedniej strony"
+// @Success 200 {object} pagination.Page[domain.Book]
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 401 {object} authRest.ErrorResponse
+// @Router /me/books [get]
+func (h *BookHandler) ListMine(c *gin.Context) {
+	filter, ok := bindBookFilter(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	books, err := h.bookService.ListOwnBooks(c.Request.Context(), userID, filter)
+	if err != nil {
+		handleBookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, books)
+}
+
+// @Summary Zmiana ogłoszenia (tylko właściciel)
+// @Accept json
+// @Produce json
+// @Param id path string true "ID książki"
+// @Param input body domain.BookUpdate true "Zmieniane pola"
+// @Success 200 {object} domain.Book
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 403 {object} authRest.ErrorResponse
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /books/{id} [patch]
+func (h *BookHandler) Update(c *gin.Context) {
+	bookID, ok := pathBookID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.BookUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	book, err := h.bookService.UpdateBook(c.Request.Context(), userID, bookID, &req)
+	if err != nil {
+		handleBookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// @Summary Usunięcie ogłoszenia (tylko właściciel)
+// @Param id path string true "ID książki"
+// @Success 204
+// @Failure 403 {object} authRest.ErrorResponse
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /books/{id} [delete]
+func (h *BookHandler) Delete(c *gin.Context) {
+	bookID, ok := pathBookID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	if err := h.bookService.DeleteBook(c.Request.Context(), userID, bookID); err != nil {
+		handleBookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Dodanie zdjęcia do ogłoszenia (tylko właściciel)
+// @Description Jedno zdjęcie JPEG lub PNG na żądanie. Serwer usuwa metadane (EXIF)
+// @Description i zapisuje warianty: imageUrl (1600 px), mediumUrl (800 px) i thumbnailUrl (320 px).
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "ID książki"
+// @Param image formData file true "Zdjęcie"
+// @Success 201 {object} domain.BookImage
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 403 {object} authRest.ErrorResponse
+// @Failure 404 {object} authRest.ErrorResponse
+// @Failure 409 {object} authRest.ErrorResponse
+// @Failure 413 {object} authRest.ErrorResponse
+// @Failure 415 {object} authRest.ErrorResponse
+// @Router /books/{id}/images [post]
+func (h *BookHandler) UploadImage(c *gin.Context) {
+	bookID, ok := pathBookID(c)
+	if !ok {
+		return
+	}
+
+	// Zapas ponad limit pliku na nagłówki części formularza
+	limit := h.bookService.ImageUploadLimit()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+64<<10)
+
+	data, err := readImageFile(c, limit)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			handleBookError(c, domain.ErrImageTooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Prześlij jedno zdjęcie w polu image formularza multipart",
+			Field:   "image",
+		})
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	image, err := h.bookService.UploadImage(c.Request.Context(), userID, bookID, data)
+	if err != nil {
+		handleBookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, image)
+}
+
+// @Summary Usunięcie zdjęcia ogłoszenia (tylko właściciel)
+// @Param id path string true "ID książki"
+// @Param imageId path string true "ID zdjęcia"
+// @Success 204
+// @Failure 403 {object} authRest.ErrorResponse
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /books/{id}/images/{imageId} [delete]
+func (h *BookHandler) DeleteImage(c *gin.Context) {
+	bookID, ok := pathBookID(c)
+	if !ok {
+		return
+	}
+
+	imageID, err := uuid.Parse(c.Param("imageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-id",
+			Message: "Nieprawidłowy identyfikator",
+		})
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	if err := h.bookService.DeleteImage(c.Request.Context(), userID, bookID, imageID); err != nil {
+		handleBookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// readImageFile odczytuje jedyny plik z pola image formularza multipart.
+// Plik większy niż limit jest przycinany o bajt, żeby serwis go odrzucił.
+func readImageFile(c *gin.Context, limit int64) ([]byte, error) {
+	if _, err := c.MultipartForm(); err != nil {
+		return nil, err
+	}
+	files := c.Request.MultipartForm.File["image"]
+	if len(files) != 1 {
+		return nil, errors.New("expected exactly one image file")
+	}
+
+	file, err := files[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, limit+1))
+}
+
+func pathBookID(c *gin.Context) (uuid.UUID, bool) {
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-id",
+			Message: "Nieprawidłowy identyfikator",
+		})
+		return uuid.Nil, false
+	}
+	return bookID, true
+}
+
+// bindBookFilter odczytuje wspólne parametry list ogłoszeń
+func bindBookFilter(c *gin.Context) (domain.BookFilter, bool) {
+	var filter domain.BookFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowe parametry zapytania",
+		})
+		return filter, false
+	}
+
+	categoryID, ok := queryUUID(c, "categoryId")
+	if !ok {
+		return filter, false
+	}
+	filter.CategoryID = categoryID
+	return filter, true
+}
+
+// queryUUID odczytuje opcjonalny identyfikator z parametru zapytania
+func queryUUID(c *gin.Context, param string) (*uuid.UUID, bool) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-id",
+			Message: "Nieprawidłowy identyfikator",
+			Field:   param,
+		})
+		return nil, false
+	}
+	return &id, true
+}
+
+func handleBookError(c *gin.Context, err error) {
+	var validationErr *domain.ValidationError
+	var pageErr *pagination.Error
+
+	switch {
+	case errors.As(err, &pageErr):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-pagination",
+			Message: "Nieprawidłowa wartość parametru " + pageErr.Field,
+			Field:   pageErr.Field,
+		})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-field",
+			Message: "Nieprawidłowa wartość pola " + validationErr.Field,
+			Field:   validationErr.Field,
+		})
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "category-not-found",
+			Message: "Nie znaleziono kategorii",
+			Field:   "categoryId",
+		})
+	case errors.Is(err, domain.ErrBookNotFound):
+		c.JSON(http.StatusNotFound, authRest.ErrorResponse{
+			Code:    "book-not-found",
+			Message: "Nie znaleziono książki",
+		})
+	case errors.Is(err, domain.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, authRest.ErrorResponse{
+			Code:    "image-not-found",
+			Message: "Nie znaleziono zdjęcia",
+		})
+	case errors.Is(err, domain.ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, authRest.ErrorResponse{
+			Code:    "image-too-large",
+			Message: "Zdjęcie jest za duże",
+			Field:   "image",
+		})
+	case errors.Is(err, domain.ErrUnsupportedImage):
+		c.JSON(http.StatusUnsupportedMediaType, authRest.ErrorResponse{
+			Code:    "unsupported-image",
+			Message: "Obsługiwane są tylko zdjęcia JPEG i PNG",
+			Field:   "image",
+		})
+	case errors.Is(err, domain.ErrTooManyImages):
+		c.JSON(http.StatusConflict, authRest.ErrorResponse{
+			Code:    "too-many-images",
+			Message: "Ogłoszenie ma już maksymalną liczbę zdjęć",
+		})
+	case errors.Is(err, domain.ErrNotBookOwner):
+		c.JSON(http.StatusForbidden, authRest.ErrorResponse{
+			Code:    "not-book-owner",
+			Message: "Tylko właściciel może zmieniać to ogłoszenie",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, authRest.ErrorResponse{
+			Code:    "internal-error",
+			Message: "Wystąpił błąd wewnętrzny",
+		})
+	}
+}
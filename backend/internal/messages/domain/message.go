@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
 )
 
 // Message reprezentuje wiadomość między użytkownikami
@@ -42,3 +44,10 @@ type ConversationPreview struct {
 	LastMessageAt time.Time `json:"lastMessageAt"`
 	UnreadCount   int       `json:"unreadCount"`
 }
+
+// MessageFilter reprezentuje parametry listy wiadomości użytkownika
+type MessageFilter struct {
+	With *uuid.UUID `form:"-"` // rozmówca; uzupełniany przez handler
+
+	pagination.Request
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/messages/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+type MessageRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMessageRepository(db *pgxpool.Pool) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+// messageKeyset sortuje wiadomości od najnowszych
+var messageKeyset = pagination.Keyset{Key: "m.created_at", ID: "m.id", Desc: true}
+
+// ListForUser zwraca stronę wiadomości wysłanych i odebranych przez użytkownika,
+// opcjonalnie tylko z jednym rozmówcą
+func (r *MessageRepository) ListForUser(ctx context.Context, userID uuid.UUID, filter domain.MessageFilter, page pagination.Params) (*pagination.Page[domain.Message], error) {
+	args := []any{userID}
+	condition := "(m.sender_id = $1 OR m.receiver_id = $1)"
+
+	if filter.With != nil {
+		args = append(args, *filter.With)
+		condition = fmt.Sprintf("((m.sender_id = $1 AND m.receiver_id = $%d) OR (m.sender_id = $%d AND m.receiver_id = $1))",
+			len(args), len(args))
+	}
+
+	if page.After != nil {
+		var createdAt time.Time
+		if err := page.After.ScanKey(&createdAt); err != nil {
+			return nil, err
+		}
+		args = append(args, createdAt, page.After.ID)
+		condition += " AND " + messageKeyset.After(len(args)-1)
+	}
+
+	args = append(args, page.Limit+1)
+	query := `SELECT ` + messageColumns + `
+		FROM messages m
+		JOIN users s ON s.id = m.sender_id
+		JOIN users rc ON rc.id = m.receiver_id
+		WHERE ` + condition + `
+		ORDER BY ` + messageKeyset.OrderBy() + fmt.Sprintf(` LIMIT $%d`, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Message, error) {
+		return scanMessage(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	return pagination.NewPage(messages, page, func(m domain.Message) (any, uuid.UUID) {
+		return m.CreatedAt, m.ID
+	})
+}
+
+const messageColumns = `m.id, m.transaction_id, m.content, m.read, m.created_at,
+	s.id, s.name, COALESCE(s.avatar_url, ''),
+	rc.id, rc.name, COALESCE(rc.avatar_url, '')`
+
+func scanMessage(row pgx.Row) (domain.Message, error) {
+	var m domain.Message
+	var sender, receiver domain.User
+
+	err := row.Scan(
+		&m.ID,
+		&m.TransactionID,
+		&m.Content,
+		&m.Read,
+		&m.CreatedAt,
+		&sender.ID,
+		&sender.Name,
+		&sender.AvatarURL,
+		&receiver.ID,
+		&receiver.Name,
+		&receiver.AvatarURL,
+	)
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	m.SenderID, m.Sender = sender.ID, &sender
+	m.ReceiverID, m.Receiver = receiver.ID, &receiver
+	return m, nil
+}
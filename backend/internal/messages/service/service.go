@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/messages/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+// MessageRepository interfejs definiujący metody dostępu do danych
+type MessageRepository interface {
+	ListForUser(ctx context.Context, userID uuid.UUID, filter domain.MessageFilter, page pagination.Params) (*pagination.Page[domain.Message], error)
+}
+
+type MessageService struct {
+	repo MessageRepository
+}
+
+func NewMessageService(repo MessageRepository) *MessageService {
+	return &MessageService{
+		repo: repo,
+	}
+}
+
+// ListMessages zwraca stronę wiadomości użytkownika, od najnowszych
+func (s *MessageService) ListMessages(ctx context.Context, userID uuid.UUID, filter domain.MessageFilter) (*pagination.Page[domain.Message], error) {
+	page, err := filter.Parse(pagination.SortNewest)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListForUser(ctx, userID, filter, page)
+}
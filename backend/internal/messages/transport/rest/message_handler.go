@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	"github.com/Ex6linz/BookSwap/backend/internal/messages/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/messages/service"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+type MessageHandler struct {
+	messageService *service.MessageService
+}
+
+func NewMessageHandler(messageService *service.MessageService) *MessageHandler {
+	return &MessageHandler{messageService: messageService}
+}
+
+// @Summary Wiadomości zalogowanego użytkownika
+// @Produce json
+// @Param with query string false "ID rozmówcy - tylko jedna konwersacja"
+// @Param limit query int false "Liczba wyników (domyślnie 20, maks. 100)"
+// @Param cursor query string false "Kursor nextCursor z poprzedniej strony"
+// @Success 200 {object} pagination.Page[domain.Message]
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 401 {object} authRest.ErrorResponse
+// @Router /me/messages [get]
+func (h *MessageHandler) ListMine(c *gin.Context) {
+	var filter domain.MessageFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowe parametry zapytania",
+		})
+		return
+	}
+
+	if value := c.Query("with"); value != "" {
+		with, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+				Code:    "invalid-id",
+				Message: "Nieprawidłowy identyfikator",
+				Field:   "with",
+			})
+			return
+		}
+		filter.With = &with
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	page, err := h.messageService.ListMessages(c.Request.Context(), userID, filter)
+	if err != nil {
+		handleMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func handleMessageError(c *gin.Context, err error) {
+	var pageErr *pagination.Error
+
+	switch {
+	case errors.As(err, &pageErr):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-pagination",
+			Message: "Nieprawidłowa wartość parametru " + pageErr.Field,
+			Field:   pageErr.Field,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, authRest.ErrorResponse{
+			Code:    "internal-error",
+			Message: "Wystąpił błąd wewnętrzny",
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBookNotFound   = errors.New("book not found")
+	ErrReviewNotFound = errors.New("review not found")
+)
+
+// TargetType określa rodzaj moderowanego obiektu
+type TargetType string
+
+const (
+	TargetBook   TargetType = "book"
+	TargetReview TargetType = "review"
+)
+
+// ActionRemove oznacza usunięcie treści przez moderatora
+const ActionRemove = "remove"
+
+// BookStatusRemoved to status ogłoszenia usuniętego przez moderację.
+// Ogłoszenia nie kasujemy, bo mogą się do niego odwoływać transakcje.
+const BookStatusRemoved = "removed"
+
+// Action reprezentuje wpis w dzienniku działań moderacyjnych
+type Action struct {
+	ID          uuid.UUID  `json:"id"`
+	ModeratorID uuid.UUID  `json:"moderatorId"`
+	TargetType  TargetType `json:"targetType"`
+	TargetID    uuid.UUID  `json:"targetId"`
+	Action      string     `json:"action"`
+	Reason      string     `json:"reason,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// RemovalRequest reprezentuje opcjonalne uzasadnienie usunięcia treści
+type RemovalRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
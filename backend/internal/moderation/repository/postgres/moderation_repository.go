@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/moderation/domain"
+)
+
+type ModerationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewModerationRepository(db *pgxpool.Pool) *ModerationRepository {
+	return &ModerationRepository{db: db}
+}
+
+// RemoveBook ukrywa ogłoszenie i zapisuje działanie w dzienniku w jednej transakcji
+func (r *ModerationRepository) RemoveBook(ctx context.Context, action *domain.Action) error {
+	return r.withAction(ctx, action, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE books SET status = $2, updated_at = $3 WHERE id = $1`,
+			action.TargetID,
+			domain.BookStatusRemoved,
+			action.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to remove book: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrBookNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteReview usuwa opinię i zapisuje działanie w dzienniku w jednej transakcji
+func (r *ModerationRepository) DeleteReview(ctx context.Context, action *domain.Action) error {
+	return r.withAction(ctx, action, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM reviews WHERE id = $1`, action.TargetID)
+		if err != nil {
+			return fmt.Errorf("failed to delete review: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrReviewNotFound
+		}
+		return nil
+	})
+}
+
+func (r *ModerationRepository) withAction(ctx context.Context, action *domain.Action, apply func(tx pgx.Tx) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := apply(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO moderation_actions 
+		(id, moderator_id, target_type, target_id, action, reason, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		action.ID,
+		action.ModeratorID,
+		action.TargetType,
+		action.TargetID,
+		action.Action,
+		action.Reason,
+		action.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record moderation action: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
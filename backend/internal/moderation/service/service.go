@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/moderation/domain"
+)
+
+// ModerationRepository interfejs definiujący operacje moderacyjne na danych
+type ModerationRepository interface {
+	RemoveBook(ctx context.Context, action *domain.Action) error
+	DeleteReview(ctx context.Context, action *domain.Action) error
+}
+
+type ModerationService struct {
+	repo ModerationRepository
+}
+
+func NewModerationService(repo ModerationRepository) *ModerationService {
+	return &ModerationService{
+		repo: repo,
+	}
+}
+
+func (s *ModerationService) RemoveBook(ctx context.Context, moderatorID, bookID uuid.UUID, reason string) (*domain.Action, error) {
+	action := newRemoval(moderatorID, domain.TargetBook, bookID, reason)
+	if err := s.repo.RemoveBook(ctx, action); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+func (s *ModerationService) RemoveReview(ctx context.Context, moderatorID, reviewID uuid.UUID, reason string) (*domain.Action, error) {
+	action := newRemoval(moderatorID, domain.TargetReview, reviewID, reason)
+	if err := s.repo.DeleteReview(ctx, action); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+func newRemoval(moderatorID uuid.UUID, targetType domain.TargetType, targetID uuid.UUID, reason string) *domain.Action {
+	return &domain.Action{
+		ID:          uuid.New(),
+		ModeratorID: moderatorID,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Action:      domain.ActionRemove,
+		Reason:      reason,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	"github.com/Ex6linz/BookSwap/backend/internal/moderation/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/moderation/service"
+)
+
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+// @Summary Usunięcie ogłoszenia książki przez moderatora
+// @Accept json
+// @Produce json
+// @Param id path string true "ID książki"
+// @Param input body domain.RemovalRequest false "Uzasadnienie"
+// @Success 200 {object} domain.Action
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /admin/books/{id} [delete]
+func (h *ModerationHandler) RemoveBook(c *gin.Context) {
+	targetID, req, ok := bindRemoval(c)
+	if !ok {
+		return
+	}
+
+	moderatorID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	action, err := h.moderationService.RemoveBook(c.Request.Context(), moderatorID, targetID, req.Reason)
+	if err != nil {
+		handleModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, action)
+}
+
+// @Summary Usunięcie opinii przez moderatora
+// @Accept json
+// @Produce json
+// @Param id path string true "ID opinii"
+// @Param input body domain.RemovalRequest false "Uzasadnienie"
+// @Success 200 {object} domain.Action
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /admin/reviews/{id} [delete]
+func (h *ModerationHandler) RemoveReview(c *gin.Context) {
+	targetID, req, ok := bindRemoval(c)
+	if !ok {
+		return
+	}
+
+	moderatorID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	action, err := h.moderationService.RemoveReview(c.Request.Context(), moderatorID, targetID, req.Reason)
+	if err != nil {
+		handleModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, action)
+}
+
+func bindRemoval(c *gin.Context) (uuid.UUID, domain.RemovalRequest, bool) {
+	var req domain.RemovalRequest
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-id",
+			Message: "Nieprawidłowy identyfikator",
+		})
+		return uuid.Nil, req, false
+	}
+
+	// Uzasadnienie jest opcjonalne, więc puste body jest w porządku
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return uuid.Nil, req, false
+	}
+
+	return targetID, req, true
+}
+
+func handleModerationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrBookNotFound):
+		c.JSON(http.StatusNotFound, authRest.ErrorResponse{
+			Code:    "book-not-found",
+			Message: "Nie znaleziono książki",
+		})
+	case errors.Is(err, domain.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, authRest.ErrorResponse{
+			Code:    "review-not-found",
+			Message: "Nie znaleziono opinii",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, authRest.ErrorResponse{
+			Code:    "internal-error",
+			Message: "Wystąpił błąd wewnętrzny",
+		})
+	}
+}
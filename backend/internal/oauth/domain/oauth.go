@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrClientNotFound       = errors.New("oauth client not found")
+	ErrCodeNotFound         = errors.New("authorization code not found")
+	ErrCodeUsed             = errors.New("authorization code already used")
+	ErrConsentNotFound      = errors.New("oauth consent not found")
+	ErrInvalidRedirectURI   = errors.New("invalid redirect uri")
+	ErrClientLimitReached   = errors.New("oauth client limit reached")
+	ErrRedirectURIsRequired = errors.New("at least one redirect uri is required")
+)
+
+// Kody błędów z RFC 6749 (rozdziały 4.1.2.1 i 5.2)
+const (
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorUnauthorizedClient      = "unauthorized_client"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+	ErrorInvalidScope            = "invalid_scope"
+	ErrorAccessDenied            = "access_denied"
+)
+
+// Error to błąd protokołu OAuth. Gdy RedirectURI jest ustawiony, frontend
+// powinien przekierować na niego przeglądarkę - błąd trafi wtedy do aplikacji.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	RedirectURI string `json:"redirectUri,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return "oauth: " + e.Code
+	}
+	return "oauth: " + e.Code + ": " + e.Description
+}
+
+// NewError tworzy błąd protokołu zwracany bezpośrednio klientowi
+func NewError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+// Client reprezentuje zarejestrowaną aplikację zewnętrzną
+type Client struct {
+	ID               uuid.UUID  `json:"id"`
+	ClientID         string     `json:"clientId"`
+	ClientSecretHash string     `json:"-"` // SHA-256 sekretu; pusty dla klientów publicznych
+	Name             string     `json:"name"`
+	OwnerID          uuid.UUID  `json:"ownerId"`
+	RedirectURIs     []string   `json:"redirectUris"`
+	Scopes           []string   `json:"scopes"` // zakresy, o które aplikacja może prosić
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// IsPublic informuje, czy klient nie ma sekretu (SPA, aplikacja mobilna)
+func (c *Client) IsPublic() bool {
+	return c.ClientSecretHash == ""
+}
+
+// IsActive informuje, czy aplikacja może jeszcze uzyskiwać tokeny
+func (c *Client) IsActive() bool {
+	return c.RevokedAt == nil
+}
+
+// HasRedirectURI sprawdza dokładne dopasowanie adresu zwrotnego
+func (c *Client) HasRedirectURI(uri string) bool {
+	for _, registered := range c.RedirectURIs {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientCreate reprezentuje dane do rejestracji aplikacji
+type ClientCreate struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirectUris" binding:"required"`
+	Scopes       []string `json:"scopes"` // pusta lista = wszystkie znane zakresy
+	Public       bool     `json:"public"` // klient bez sekretu, wymaga PKCE
+}
+
+// CreatedClient zwraca jawny sekret - jedyny raz, kiedy jest widoczny
+type CreatedClient struct {
+	*Client
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
+// AuthorizationRequest to parametry żądania autoryzacji (RFC 6749 4.1.1, RFC 7636 4.3)
+type AuthorizationRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+// ConsentDecision to odpowiedź użytkownika na ekran zgody
+type ConsentDecision struct {
+	AuthorizationRequest
+	Approve bool `form:"approve" json:"approve"`
+}
+
+// ConsentPrompt opisuje, o co prosi aplikacja - frontend wyświetla na tej podstawie ekran zgody
+type ConsentPrompt struct {
+	ClientID       string               `json:"clientId"`
+	ClientName     string               `json:"clientName"`
+	RedirectURI    string               `json:"redirectUri"`
+	Scopes         []string             `json:"scopes"`
+	AlreadyGranted bool                 `json:"alreadyGranted"` // użytkownik zgodził się wcześniej na te zakresy
+	Request        AuthorizationRequest `json:"request"`
+}
+
+// AuthorizationResult wskazuje, dokąd przekierować przeglądarkę użytkownika
+type AuthorizationResult struct {
+	RedirectURI string `json:"redirectUri"`
+}
+
+// AuthorizationCode to jednorazowy kod wymieniany na tokeny
+type AuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	UserID        uuid.UUID
+	RedirectURI   string
+	Scopes        []string
+	CodeChallenge string
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+// Consent to zgoda użytkownika na dostęp aplikacji do konta
+type Consent struct {
+	ClientID   string    `json:"clientId"`
+	ClientName string    `json:"clientName"`
+	Scopes     []string  `json:"scopes"`
+	GrantedAt  time.Time `json:"grantedAt"`
+}
+
+// Rodzaje grantów obsługiwane przez endpoint tokenów
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+// TokenRequest to parametry endpointu tokenów (RFC 6749 4.1.3 i 6)
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// IntrospectionRequest reprezentuje zapytanie serwera zasobów o stan tokenu
+// (RFC 7662 2.1). Aplikacja uwierzytelnia się tak jak na endpoincie tokenów.
+type IntrospectionRequest struct {
+	Token         string `form:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// TokenResponse to odpowiedź endpointu tokenów (RFC 6749 5.1)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/domain"
+)
+
+type ClientRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewClientRepository(db *pgxpool.Pool) *ClientRepository {
+	return &ClientRepository{db: db}
+}
+
+func (r *ClientRepository) Create(ctx context.Context, client *domain.Client) error {
+	query := `INSERT INTO oauth_clients 
+		(id, client_id, client_secret_hash, name, owner_id, redirect_uris, scopes, created_at) 
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8)`
+
+	_, err := r.db.Exec(ctx, query,
+		client.ID,
+		client.ClientID,
+		client.ClientSecretHash,
+		client.Name,
+		client.OwnerID,
+		client.RedirectURIs,
+		client.Scopes,
+		client.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create oauth client: %w", err)
+	}
+	return nil
+}
+
+func (r *ClientRepository) GetByClientID(ctx context.Context, clientID string) (*domain.Client, error) {
+	query := `SELECT ` + clientColumns + ` FROM oauth_clients WHERE client_id = $1`
+
+	client, err := scanClient(r.db.QueryRow(ctx, query, clientID))
+	if err != nil && !errors.Is(err, domain.ErrClientNotFound) {
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+	return client, err
+}
+
+// ListByOwner zwraca aktywne aplikacje zarejestrowane przez użytkownika
+func (r *ClientRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]domain.Client, error) {
+	query := `SELECT ` + clientColumns + ` FROM oauth_clients 
+		WHERE owner_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list oauth clients: %w", err)
+	}
+
+	clients, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Client, error) {
+		client, err := scanClient(row)
+		if err != nil {
+			return domain.Client{}, err
+		}
+		return *client, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list oauth clients: %w", err)
+	}
+	return clients, nil
+}
+
+func (r *ClientRepository) CountActive(ctx context.Context, ownerID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM oauth_clients WHERE owner_id = $1 AND revoked_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, ownerID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count oauth clients: %w", err)
+	}
+	return count, nil
+}
+
+// Revoke wyłącza aplikację i unieważnia wszystkie wystawione jej tokeny odświeżające
+func (r *ClientRepository) Revoke(ctx context.Context, ownerID uuid.UUID, clientID string, at time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE oauth_clients SET revoked_at = $3 
+		WHERE client_id = $1 AND owner_id = $2 AND revoked_at IS NULL`, clientID, ownerID, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke oauth client: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrClientNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = $2 
+		WHERE client_id = $1 AND revoked_at IS NULL`, clientID, at); err != nil {
+		return fmt.Errorf("failed to revoke oauth client tokens: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM oauth_consents WHERE client_id = $1`, clientID); err != nil {
+		return fmt.Errorf("failed to delete oauth client consents: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+const clientColumns = `id, client_id, COALESCE(client_secret_hash, ''), name, owner_id, 
+	redirect_uris, scopes, revoked_at, created_at`
+
+func scanClient(row pgx.Row) (*domain.Client, error) {
+	var client domain.Client
+	err := row.Scan(
+		&client.ID,
+		&client.ClientID,
+		&client.ClientSecretHash,
+		&client.Name,
+		&client.OwnerID,
+		&client.RedirectURIs,
+		&client.Scopes,
+		&client.RevokedAt,
+		&client.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrClientNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
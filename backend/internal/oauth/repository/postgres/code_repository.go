@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/domain"
+)
+
+type CodeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCodeRepository(db *pgxpool.Pool) *CodeRepository {
+	return &CodeRepository{db: db}
+}
+
+func (r *CodeRepository) Create(ctx context.Context, code *domain.AuthorizationCode) error {
+	query := `INSERT INTO oauth_authorization_codes 
+		(code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.db.Exec(ctx, query,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		code.Scopes,
+		code.CodeChallenge,
+		code.ExpiresAt,
+		code.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create authorization code: %w", err)
+	}
+	return nil
+}
+
+// Consume oznacza kod jako użyty i zwraca go. Dla kodu użytego wcześniej zwraca
+// go razem z ErrCodeUsed, żeby serwis mógł unieważnić wydane na niego tokeny.
+func (r *CodeRepository) Consume(ctx context.Context, codeHash string, at time.Time) (*domain.AuthorizationCode, error) {
+	query := `UPDATE oauth_authorization_codes SET used_at = $2 
+		WHERE code_hash = $1 AND used_at IS NULL 
+		RETURNING ` + codeColumns
+
+	code, err := scanCode(r.db.QueryRow(ctx, query, codeHash, at))
+	if err == nil {
+		return code, nil
+	}
+	if !errors.Is(err, domain.ErrCodeNotFound) {
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	code, err = scanCode(r.db.QueryRow(ctx,
+		`SELECT `+codeColumns+` FROM oauth_authorization_codes WHERE code_hash = $1`, codeHash))
+	if err != nil {
+		if errors.Is(err, domain.ErrCodeNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+	return code, domain.ErrCodeUsed
+}
+
+// DeleteExpired usuwa kody po terminie ważności
+func (r *CodeRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM oauth_authorization_codes WHERE expires_at < $1`, before); err != nil {
+		return fmt.Errorf("failed to delete expired authorization codes: %w", err)
+	}
+	return nil
+}
+
+const codeColumns = `code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, used_at, created_at`
+
+func scanCode(row pgx.Row) (*domain.AuthorizationCode, error) {
+	var code domain.AuthorizationCode
+	err := row.Scan(
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.Scopes,
+		&code.CodeChallenge,
+		&code.ExpiresAt,
+		&code.UsedAt,
+		&code.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCodeNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/domain"
+)
+
+type ConsentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewConsentRepository(db *pgxpool.Pool) *ConsentRepository {
+	return &ConsentRepository{db: db}
+}
+
+// Save zapisuje zgodę; ponowna zgoda zastępuje listę zakresów
+func (r *ConsentRepository) Save(ctx context.Context, userID uuid.UUID, consent *domain.Consent) error {
+	query := `INSERT INTO oauth_consents (user_id, client_id, scopes, granted_at) 
+		VALUES ($1, $2, $3, $4) 
+		ON CONFLICT (user_id, client_id) DO UPDATE SET scopes = EXCLUDED.scopes, granted_at = EXCLUDED.granted_at`
+
+	if _, err := r.db.Exec(ctx, query, userID, consent.ClientID, consent.Scopes, consent.GrantedAt); err != nil {
+		return fmt.Errorf("failed to save oauth consent: %w", err)
+	}
+	return nil
+}
+
+func (r *ConsentRepository) Get(ctx context.Context, userID uuid.UUID, clientID string) (*domain.Consent, error) {
+	query := `SELECT ` + consentColumns + ` 
+		FROM oauth_consents c JOIN oauth_clients cl ON cl.client_id = c.client_id 
+		WHERE c.user_id = $1 AND c.client_id = $2`
+
+	consent, err := scanConsent(r.db.QueryRow(ctx, query, userID, clientID))
+	if err != nil && !errors.Is(err, domain.ErrConsentNotFound) {
+		return nil, fmt.Errorf("failed to get oauth consent: %w", err)
+	}
+	return consent, err
+}
+
+// ListByUser zwraca aplikacje, którym użytkownik udzielił dostępu
+func (r *ConsentRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.Consent, error) {
+	query := `SELECT ` + consentColumns + ` 
+		FROM oauth_consents c JOIN oauth_clients cl ON cl.client_id = c.client_id 
+		WHERE c.user_id = $1 AND cl.revoked_at IS NULL 
+		ORDER BY c.granted_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list oauth consents: %w", err)
+	}
+
+	consents, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Consent, error) {
+		consent, err := scanConsent(row)
+		if err != nil {
+			return domain.Consent{}, err
+		}
+		return *consent, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list oauth consents: %w", err)
+	}
+	return consents, nil
+}
+
+func (r *ConsentRepository) Delete(ctx context.Context, userID uuid.UUID, clientID string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM oauth_consents WHERE user_id = $1 AND client_id = $2`, userID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to delete oauth consent: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrConsentNotFound
+	}
+	return nil
+}
+
+const consentColumns = `c.client_id, cl.name, c.scopes, c.granted_at`
+
+func scanConsent(row pgx.Row) (*domain.Consent, error) {
+	var consent domain.Consent
+	err := row.Scan(
+		&consent.ClientID,
+		&consent.ClientName,
+		&consent.Scopes,
+		&consent.GrantedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrConsentNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	authDomain "github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/domain"
+)
+
+// Repozytoria w pamięci dla testów serwisu OAuth
+
+type fakeClientRepo struct {
+	clients map[string]*domain.Client
+}
+
+func newFakeClientRepo(clients ...*domain.Client) *fakeClientRepo {
+	r := &fakeClientRepo{clients: make(map[string]*domain.Client)}
+	for _, client := range clients {
+		r.clients[client.ClientID] = client
+	}
+	return r
+}
+
+func (r *fakeClientRepo) Create(ctx context.Context, client *domain.Client) error {
+	r.clients[client.ClientID] = client
+	return nil
+}
+
+func (r *fakeClientRepo) GetByClientID(ctx context.Context, clientID string) (*domain.Client, error) {
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, domain.ErrClientNotFound
+	}
+	return client, nil
+}
+
+func (r *fakeClientRepo) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]domain.Client, error) {
+	var clients []domain.Client
+	for _, client := range r.clients {
+		if client.OwnerID == ownerID {
+			clients = append(clients, *client)
+		}
+	}
+	return clients, nil
+}
+
+func (r *fakeClientRepo) CountActive(ctx context.Context, ownerID uuid.UUID) (int, error) {
+	count := 0
+	for _, client := range r.clients {
+		if client.OwnerID == ownerID && client.IsActive() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeClientRepo) Revoke(ctx context.Context, ownerID uuid.UUID, clientID string, at time.Time) error {
+	client, ok := r.clients[clientID]
+	if !ok || client.OwnerID != ownerID {
+		return domain.ErrClientNotFound
+	}
+	client.RevokedAt = &at
+	return nil
+}
+
+// fakeCodeRepo zachowuje się jak CodeRepository z Postgresa: drugie użycie
+// kodu zwraca go razem z ErrCodeUsed
+type fakeCodeRepo struct {
+	codes map[string]*domain.AuthorizationCode
+}
+
+func newFakeCodeRepo() *fakeCodeRepo {
+	return &fakeCodeRepo{codes: make(map[string]*domain.AuthorizationCode)}
+}
+
+func (r *fakeCodeRepo) Create(ctx context.Context, code *domain.AuthorizationCode) error {
+	r.codes[code.CodeHash] = code
+	return nil
+}
+
+func (r *fakeCodeRepo) Consume(ctx context.Context, codeHash string, at time.Time) (*domain.AuthorizationCode, error) {
+	code, ok := r.codes[codeHash]
+	if !ok {
+		return nil, domain.ErrCodeNotFound
+	}
+	if code.UsedAt != nil {
+		return code, domain.ErrCodeUsed
+	}
+	code.UsedAt = &at
+	return code, nil
+}
+
+type fakeConsentRepo struct {
+	consents map[string]*domain.Consent
+}
+
+func newFakeConsentRepo() *fakeConsentRepo {
+	return &fakeConsentRepo{consents: make(map[string]*domain.Consent)}
+}
+
+func (r *fakeConsentRepo) Save(ctx context.Context, userID uuid.UUID, consent *domain.Consent) error {
+	r.consents[userID.String()+"/"+consent.ClientID] = consent
+	return nil
+}
+
+func (r *fakeConsentRepo) Get(ctx context.Context, userID uuid.UUID, clientID string) (*domain.Consent, error) {
+	consent, ok := r.consents[userID.String()+"/"+clientID]
+	if !ok {
+		return nil, domain.ErrConsentNotFound
+	}
+	return consent, nil
+}
+
+func (r *fakeConsentRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.Consent, error) {
+	var consents []domain.Consent
+	for key, consent := range r.consents {
+		if strings.HasPrefix(key, userID.String()+"/") {
+			consents = append(consents, *consent)
+		}
+	}
+	return consents, nil
+}
+
+func (r *fakeConsentRepo) Delete(ctx context.Context, userID uuid.UUID, clientID string) error {
+	delete(r.consents, userID.String()+"/"+clientID)
+	return nil
+}
+
+// fakeTokenIssuer zapisuje wywołania zamiast wystawiać prawdziwe JWT
+type fakeTokenIssuer struct {
+	issued        []string // client_id, dla których wystawiono tokeny
+	revoked       []string // client_id, których tokeny unieważniono
+	introspection map[string]*authDomain.Introspection
+}
+
+func newFakeTokenIssuer() *fakeTokenIssuer {
+	return &fakeTokenIssuer{introspection: make(map[string]*authDomain.Introspection)}
+}
+
+func (f *fakeTokenIssuer) IssueClientTokens(ctx context.Context, userID uuid.UUID, clientID string, scopes []string) (*authDomain.TokenPair, error) {
+	f.issued = append(f.issued, clientID)
+	return &authDomain.TokenPair{
+		AccessToken:     "access-" + clientID,
+		AccessExpiresAt: time.Now().Add(15 * time.Minute),
+		RefreshToken:    "refresh-" + clientID,
+	}, nil
+}
+
+func (f *fakeTokenIssuer) RefreshClientTokens(ctx context.Context, refreshToken, clientID string, scopes []string) (*authDomain.GrantedTokens, error) {
+	return nil, authDomain.ErrInvalidRefreshToken
+}
+
+func (f *fakeTokenIssuer) RevokeClientTokens(ctx context.Context, userID uuid.UUID, clientID string) error {
+	f.revoked = append(f.revoked, clientID)
+	return nil
+}
+
+func (f *fakeTokenIssuer) Introspect(ctx context.Context, token string) (*authDomain.Introspection, error) {
+	if result, ok := f.introspection[token]; ok {
+		return result, nil
+	}
+	return &authDomain.Introspection{Active: false}, nil
+}
+
+// newConfidentialClient zwraca aplikację poufną i jej sekret
+func newConfidentialClient(clientID string) (*domain.Client, string) {
+	secret := "secret-" + clientID
+	return &domain.Client{
+		ID:               uuid.New(),
+		ClientID:         clientID,
+		ClientSecretHash: hashSecret(secret),
+		Name:             clientID,
+		OwnerID:          uuid.New(),
+		RedirectURIs:     []string{"https://app.example.com/callback"},
+		Scopes:           []string{authDomain.ScopeBooksRead},
+		CreatedAt:        time.Now().UTC(),
+	}, secret
+}
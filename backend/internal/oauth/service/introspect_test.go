@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	authDomain "github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/domain"
+)
+
+func TestIntrospectRequiresConfidentialClient(t *testing.T) {
+	client, secret := newConfidentialClient("bsc_app")
+	public := &domain.Client{ClientID: "bsc_spa", RedirectURIs: []string{"https://spa.example.com/cb"}}
+	svc := NewOAuthService(newFakeClientRepo(client, public), newFakeCodeRepo(), newFakeConsentRepo(), newFakeTokenIssuer(), time.Minute, nil)
+
+	tests := []struct {
+		name             string
+		clientID, secret string
+	}{
+		{"no client", "", ""},
+		{"unknown client", "bsc_unknown", "x"},
+		{"wrong secret", client.ClientID, secret + "x"},
+		{"public client", public.ClientID, ""},
+	}
+	for _, tt := range tests {
+		_, err := svc.Introspect(context.Background(), tt.clientID, tt.secret, "token")
+		var oauthErr *domain.Error
+		if !errors.As(err, &oauthErr) || oauthErr.Code != domain.ErrorInvalidClient {
+			t.Errorf("%s: err = %v, want invalid_client", tt.name, err)
+		}
+	}
+}
+
+func TestIntrospectHidesForeignTokens(t *testing.T) {
+	app, appSecret := newConfidentialClient("bsc_app")
+	resourceServer, rsSecret := newConfidentialClient("bsc_rs")
+
+	tokens := newFakeTokenIssuer()
+	tokens.introspection["app-token"] = &authDomain.Introspection{Active: true, ClientID: app.ClientID, Subject: "u1"}
+	tokens.introspection["other-token"] = &authDomain.Introspection{Active: true, ClientID: "bsc_other", Subject: "u2"}
+	tokens.introspection["api-key"] = &authDomain.Introspection{Active: true, TokenType: "api_key", Subject: "u3"}
+
+	svc := NewOAuthService(newFakeClientRepo(app, resourceServer), newFakeCodeRepo(), newFakeConsentRepo(), tokens, time.Minute,
+		[]string{resourceServer.ClientID})
+
+	tests := []struct {
+		clientID, secret, token string
+		wantActive              bool
+	}{
+		{app.ClientID, appSecret, "app-token", true},
+		{app.ClientID, appSecret, "other-token", false},
+		{app.ClientID, appSecret, "api-key", false},
+		{resourceServer.ClientID, rsSecret, "app-token", true},
+		{resourceServer.ClientID, rsSecret, "other-token", true},
+		{resourceServer.ClientID, rsSecret, "api-key", true},
+		{resourceServer.ClientID, rsSecret, "unknown", false},
+	}
+	for _, tt := range tests {
+		result, err := svc.Introspect(context.Background(), tt.clientID, tt.secret, tt.token)
+		if err != nil {
+			t.Fatalf("%s/%s: %v", tt.clientID, tt.token, err)
+		}
+		if result.Active != tt.wantActive {
+			t.Errorf("%s/%s: active = %v, want %v", tt.clientID, tt.token, result.Active, tt.wantActive)
+		}
+		if !result.Active && result.Subject != "" {
+			t.Errorf("%s/%s: inactive result leaks subject %q", tt.clientID, tt.token, result.Subject)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// PKCE (RFC 7636): akceptujemy wyłącznie metodę S256 - "plain" nie chroni
+// przed przechwyceniem kodu, jeśli atakujący widzi żądanie autoryzacji.
+const (
+	codeChallengeMethodS256 = "S256"
+	minVerifierLength       = 43
+	maxVerifierLength       = 128
+)
+
+// validCodeChallenge sprawdza, czy wyzwanie wygląda jak base64url(SHA-256)
+func validCodeChallenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// verifyCodeVerifier porównuje base64url(SHA-256(verifier)) z zapisanym wyzwaniem
+func verifyCodeVerifier(verifier, challenge string) bool {
+	if len(verifier) < minVerifierLength || len(verifier) > maxVerifierLength {
+		return false
+	}
+	for _, c := range verifier {
+		if !isUnreserved(c) {
+			return false
+		}
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// isUnreserved odpowiada znakom dozwolonym w code_verifier (RFC 7636 4.1)
+func isUnreserved(c rune) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
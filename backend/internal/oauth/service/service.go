@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	authDomain "github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/domain"
+)
+
+const (
+	maxClientsPerUser = 20
+	maxRedirectURIs   = 10
+	clientIDPrefix    = "bsc_"
+	tokenTypeBearer   = "Bearer"
+)
+
+// ClientRepository interfejs definiujący dostęp do zarejestrowanych aplikacji
+type ClientRepository interface {
+	Create(ctx context.Context, client *domain.Client) error
+	GetByClientID(ctx context.Context, clientID string) (*domain.Client, error)
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]domain.Client, error)
+	CountActive(ctx context.Context, ownerID uuid.UUID) (int, error)
+	Revoke(ctx context.Context, ownerID uuid.UUID, clientID string, at time.Time) error
+}
+
+// CodeRepository interfejs definiujący dostęp do kodów autoryzacyjnych
+type CodeRepository interface {
+	Create(ctx context.Context, code *domain.AuthorizationCode) error
+	Consume(ctx context.Context, codeHash string, at time.Time) (*domain.AuthorizationCode, error)
+}
+
+// ConsentRepository interfejs definiujący dostęp do zgód użytkowników
+type ConsentRepository interface {
+	Save(ctx context.Context, userID uuid.UUID, consent *domain.Consent) error
+	Get(ctx context.Context, userID uuid.UUID, clientID string) (*domain.Consent, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.Consent, error)
+	Delete(ctx context.Context, userID uuid.UUID, clientID string) error
+}
+
+// TokenIssuer wystawia i sprawdza tokeny aplikacji - implementuje go serwis
+// auth, dzięki czemu tokeny OAuth są zwykłymi JWT z ograniczonymi zakresami
+type TokenIssuer interface {
+	IssueClientTokens(ctx context.Context, userID uuid.UUID, clientID string, scopes []string) (*authDomain.TokenPair, error)
+	RefreshClientTokens(ctx context.Context, refreshToken, clientID string, scopes []string) (*authDomain.GrantedTokens, error)
+	RevokeClientTokens(ctx context.Context, userID uuid.UUID, clientID string) error
+	Introspect(ctx context.Context, token string) (*authDomain.Introspection, error)
+}
+
+type OAuthService struct {
+	clients  ClientRepository
+	codes    CodeRepository
+	consents ConsentRepository
+	tokens   TokenIssuer
+	codeTTL  time.Duration
+	// resourceServers to aplikacje poufne, które mogą sprawdzać dowolne tokeny
+	resourceServers map[string]bool
+}
+
+func NewOAuthService(clients ClientRepository, codes CodeRepository, consents ConsentRepository, tokens TokenIssuer, codeTTL time.Duration, resourceServers []string) *OAuthService {
+	trusted := make(map[string]bool, len(resourceServers))
+	for _, clientID := range resourceServers {
+		trusted[clientID] = true
+	}
+
+	return &OAuthService{
+		clients:         clients,
+		codes:           codes,
+		consents:        consents,
+		tokens:          tokens,
+		codeTTL:         codeTTL,
+		resourceServers: trusted,
+	}
+}
+
+// RegisterClient rejestruje aplikację. Sekret klienta poufnego jest zwracany tylko tutaj.
+func (s *OAuthService) RegisterClient(ctx context.Context, ownerID uuid.UUID, req *domain.ClientCreate) (*domain.CreatedClient, error) {
+	if len(req.RedirectURIs) == 0 || len(req.RedirectURIs) > maxRedirectURIs {
+		return nil, domain.ErrRedirectURIsRequired
+	}
+	for _, uri := range req.RedirectURIs {
+		if !validRedirectURI(uri) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidRedirectURI, uri)
+		}
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = authDomain.AllScopes()
+	}
+	for _, scope := range scopes {
+		if !authDomain.IsKnownScope(scope) {
+			return nil, fmt.Errorf("%w: %s", authDomain.ErrInvalidScope, scope)
+		}
+	}
+
+	count, err := s.clients.CountActive(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxClientsPerUser {
+		return nil, domain.ErrClientLimitReached
+	}
+
+	clientID, err := randomToken(12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client id: %w", err)
+	}
+
+	client := &domain.Client{
+		ID:           uuid.New(),
+		ClientID:     clientIDPrefix + clientID,
+		Name:         req.Name,
+		OwnerID:      ownerID,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       scopes,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	var secret string
+	if !req.Public {
+		secret, err = randomToken(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate client secret: %w", err)
+		}
+		client.ClientSecretHash = hashSecret(secret)
+	}
+
+	if err := s.clients.Create(ctx, client); err != nil {
+		return nil, err
+	}
+
+	return &domain.CreatedClient{Client: client, ClientSecret: secret}, nil
+}
+
+func (s *OAuthService) ListClients(ctx context.Context, ownerID uuid.UUID) ([]domain.Client, error) {
+	return s.clients.ListByOwner(ctx, ownerID)
+}
+
+// DeleteClient wyłącza aplikację; wszystkie wystawione jej tokeny odświeżające przestają działać
+func (s *OAuthService) DeleteClient(ctx context.Context, ownerID uuid.UUID, clientID string) error {
+	return s.clients.Revoke(ctx, ownerID, clientID, time.Now().UTC())
+}
+
+// PrepareAuthorization sprawdza żądanie autoryzacji i zwraca dane do ekranu zgody
+func (s *OAuthService) PrepareAuthorization(ctx context.Context, userID uuid.UUID, req *domain.AuthorizationRequest) (*domain.ConsentPrompt, error) {
+	client, scopes, err := s.validateAuthorization(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := &domain.ConsentPrompt{
+		ClientID:    client.ClientID,
+		ClientName:  client.Name,
+		RedirectURI: req.RedirectURI,
+		Scopes:      scopes,
+		Request:     *req,
+	}
+
+	consent, err := s.consents.Get(ctx, userID, client.ClientID)
+	if err != nil && !errors.Is(err, domain.ErrConsentNotFound) {
+		return nil, err
+	}
+	if consent != nil {
+		prompt.AlreadyGranted = containsAll(consent.Scopes, scopes)
+	}
+	return prompt, nil
+}
+
+// Authorize zapisuje decyzję użytkownika. Po zgodzie wystawia jednorazowy kod,
+// po odmowie zwraca błąd access_denied - w obu przypadkach przez adres zwrotny.
+func (s *OAuthService) Authorize(ctx context.Context, userID uuid.UUID, decision *domain.ConsentDecision) (*domain.AuthorizationResult, error) {
+	req := &decision.AuthorizationRequest
+	client, scopes, err := s.validateAuthorization(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !decision.Approve {
+		return &domain.AuthorizationResult{
+			RedirectURI: errorRedirect(req, domain.ErrorAccessDenied, "The user denied the request"),
+		}, nil
+	}
+
+	plain, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+
+	now := time.Now().UTC()
+	code := &domain.AuthorizationCode{
+		CodeHash:      hashSecret(plain),
+		ClientID:      client.ClientID,
+		UserID:        userID,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     now.Add(s.codeTTL),
+		CreatedAt:     now,
+	}
+	if err := s.codes.Create(ctx, code); err != nil {
+		return nil, err
+	}
+
+	if err := s.consents.Save(ctx, userID, &domain.Consent{
+		ClientID:  client.ClientID,
+		Scopes:    scopes,
+		GrantedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("code", plain)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return &domain.AuthorizationResult{RedirectURI: withQuery(req.RedirectURI, params)}, nil
+}
+
+// Token obsługuje endpoint tokenów dla grantów authorization_code i refresh_token
+func (s *OAuthService) Token(ctx context.Context, req *domain.TokenRequest) (*domain.TokenResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case domain.GrantTypeAuthorizationCode:
+		return s.exchangeCode(ctx, client, req)
+	case domain.GrantTypeRefreshToken:
+		return s.refresh(ctx, client, req)
+	case "":
+		return nil, domain.NewError(domain.ErrorInvalidRequest, "grant_type is required")
+	default:
+		return nil, domain.NewError(domain.ErrorUnsupportedGrantType, "")
+	}
+}
+
+// Introspect zwraca stan tokenu dla uwierzytelnionej aplikacji poufnej
+// (RFC 7662 2.1). Serwery zasobów z konfiguracji widzą wszystkie tokeny,
+// pozostałe aplikacje tylko tokeny wystawione im samym - cudzy token jest
+// dla nich nieaktywny, żeby endpoint nie służył do sprawdzania wykradzionych
+// tokenów i kluczy API.
+func (s *OAuthService) Introspect(ctx context.Context, clientID, secret, token string) (*authDomain.Introspection, error) {
+	client, err := s.authenticateClient(ctx, clientID, secret)
+	if err != nil {
+		return nil, err
+	}
+	// Klient publiczny nie ma sekretu, więc nie może się uwierzytelnić
+	if client.IsPublic() {
+		return nil, domain.NewError(domain.ErrorInvalidClient, "client authentication failed")
+	}
+
+	result, err := s.tokens.Introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if result.Active && !s.resourceServers[client.ClientID] && result.ClientID != client.ClientID {
+		return &authDomain.Introspection{Active: false}, nil
+	}
+	return result, nil
+}
+
+// ListConsents zwraca aplikacje, którym użytkownik udzielił dostępu
+func (s *OAuthService) ListConsents(ctx context.Context, userID uuid.UUID) ([]domain.Consent, error) {
+	return s.consents.ListByUser(ctx, userID)
+}
+
+// RevokeConsent cofa zgodę i unieważnia tokeny odświeżające aplikacji
+func (s *OAuthService) RevokeConsent(ctx context.Context, userID uuid.UUID, clientID string) error {
+	if err := s.consents.Delete(ctx, userID, clientID); err != nil {
+		return err
+	}
+	return s.tokens.RevokeClientTokens(ctx, userID, clientID)
+}
+
+func (s *OAuthService) exchangeCode(ctx context.Context, client *domain.Client, req *domain.TokenRequest) (*domain.TokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, domain.NewError(domain.ErrorInvalidRequest, "code and code_verifier are required")
+	}
+
+	now := time.Now().UTC()
+	code, err := s.codes.Consume(ctx, hashSecret(req.Code), now)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCodeNotFound):
+			return nil, domain.NewError(domain.ErrorInvalidGrant, "invalid authorization code")
+		case errors.Is(err, domain.ErrCodeUsed):
+			// Ponowne użycie kodu oznacza, że ktoś go przechwycił (RFC 6749 4.1.2) -
+			// unieważniamy tokeny wydane na jego podstawie
+			if err := s.tokens.RevokeClientTokens(ctx, code.UserID, code.ClientID); err != nil {
+				return nil, err
+			}
+			return nil, domain.NewError(domain.ErrorInvalidGrant, "authorization code already used")
+		default:
+			return nil, err
+		}
+	}
+
+	if code.ClientID != client.ClientID || !now.Before(code.ExpiresAt) || code.RedirectURI != req.RedirectURI {
+		return nil, domain.NewError(domain.ErrorInvalidGrant, "invalid authorization code")
+	}
+
+	if !verifyCodeVerifier(req.CodeVerifier, code.CodeChallenge) {
+		return nil, domain.NewError(domain.ErrorInvalidGrant, "code_verifier does not match code_challenge")
+	}
+
+	tokens, err := s.tokens.IssueClientTokens(ctx, code.UserID, client.ClientID, code.Scopes)
+	if err != nil {
+		if errors.Is(err, authDomain.ErrUserNotFound) {
+			return nil, domain.NewError(domain.ErrorInvalidGrant, "invalid authorization code")
+		}
+		return nil, err
+	}
+
+	return tokenResponse(tokens, code.Scopes, now), nil
+}
+
+func (s *OAuthService) refresh(ctx context.Context, client *domain.Client, req *domain.TokenRequest) (*domain.TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, domain.NewError(domain.ErrorInvalidRequest, "refresh_token is required")
+	}
+
+	granted, err := s.tokens.RefreshClientTokens(ctx, req.RefreshToken, client.ClientID, authDomain.ParseScope(req.Scope))
+	if err != nil {
+		switch {
+		case errors.Is(err, authDomain.ErrInvalidRefreshToken), errors.Is(err, authDomain.ErrRefreshTokenReused):
+			return nil, domain.NewError(domain.ErrorInvalidGrant, "invalid refresh token")
+		case errors.Is(err, authDomain.ErrInvalidScope):
+			return nil, domain.NewError(domain.ErrorInvalidScope, "requested scope exceeds the original grant")
+		default:
+			return nil, err
+		}
+	}
+
+	return tokenResponse(granted.TokenPair, granted.Scopes, time.Now().UTC()), nil
+}
+
+// authenticateClient uwierzytelnia aplikację. Klient poufny musi podać sekret,
+// publiczny nie ma sekretu - chroni go PKCE.
+func (s *OAuthService) authenticateClient(ctx context.Context, clientID, secret string) (*domain.Client, error) {
+	if clientID == "" {
+		return nil, domain.NewError(domain.ErrorInvalidClient, "client authentication failed")
+	}
+
+	client, err := s.clients.GetByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrClientNotFound) {
+			return nil, domain.NewError(domain.ErrorInvalidClient, "client authentication failed")
+		}
+		return nil, err
+	}
+
+	if !client.IsActive() {
+		return nil, domain.NewError(domain.ErrorInvalidClient, "client authentication failed")
+	}
+
+	if client.IsPublic() {
+		if secret != "" {
+			return nil, domain.NewError(domain.ErrorInvalidClient, "public clients must not send a secret")
+		}
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(client.ClientSecretHash)) != 1 {
+		return nil, domain.NewError(domain.ErrorInvalidClient, "client authentication failed")
+	}
+	return client, nil
+}
+
+// validateAuthorization sprawdza żądanie autoryzacji. Dopóki nie zweryfikujemy
+// klienta i adresu zwrotnego, błędy nie mogą prowadzić do przekierowania
+// (RFC 6749 4.1.2.1) - inaczej serwis stałby się otwartym przekierowaniem.
+func (s *OAuthService) validateAuthorization(ctx context.Context, req *domain.AuthorizationRequest) (*domain.Client, []string, error) {
+	if req.ClientID == "" {
+		return nil, nil, domain.NewError(domain.ErrorInvalidRequest, "client_id is required")
+	}
+
+	client, err := s.clients.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrClientNotFound) {
+			return nil, nil, domain.NewError(domain.ErrorInvalidRequest, "unknown client")
+		}
+		return nil, nil, err
+	}
+	if !client.IsActive() {
+		return nil, nil, domain.NewError(domain.ErrorInvalidRequest, "unknown client")
+	}
+
+	// Pominięty redirect_uri dopuszczamy tylko, gdy aplikacja ma jeden adres
+	if req.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		req.RedirectURI = client.RedirectURIs[0]
+	}
+	if !client.HasRedirectURI(req.RedirectURI) {
+		return nil, nil, domain.NewError(domain.ErrorInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	if req.ResponseType != "code" {
+		return nil, nil, redirectError(req, domain.ErrorUnsupportedResponseType, "only response_type=code is supported")
+	}
+
+	if req.CodeChallengeMethod != codeChallengeMethodS256 || !validCodeChallenge(req.CodeChallenge) {
+		return nil, nil, redirectError(req, domain.ErrorInvalidRequest, "PKCE with code_challenge_method=S256 is required")
+	}
+
+	scopes := authDomain.ParseScope(req.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !containsAll(client.Scopes, scopes) {
+		return nil, nil, redirectError(req, domain.ErrorInvalidScope, "requested scope is not allowed for this client")
+	}
+
+	return client, scopes, nil
+}
+
+// validRedirectURI wymaga https; http dopuszczamy tylko dla adresów lokalnych (RFC 8252 7.3)
+func validRedirectURI(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Fragment != "" {
+		return false
+	}
+
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	default:
+		return false
+	}
+}
+
+func redirectError(req *domain.AuthorizationRequest, code, description string) *domain.Error {
+	return &domain.Error{
+		Code:        code,
+		Description: description,
+		RedirectURI: errorRedirect(req, code, description),
+	}
+}
+
+func errorRedirect(req *domain.AuthorizationRequest, code, description string) string {
+	params := url.Values{}
+	params.Set("error", code)
+	params.Set("error_description", description)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return withQuery(req.RedirectURI, params)
+}
+
+// withQuery dokleja parametry, zachowując te zapisane w zarejestrowanym adresie
+func withQuery(rawURL string, params url.Values) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func tokenResponse(tokens *authDomain.TokenPair, scopes []string, now time.Time) *domain.TokenResponse {
+	return &domain.TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int64(tokens.AccessExpiresAt.Sub(now).Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        authDomain.FormatScope(scopes),
+	}
+}
+
+// containsAll informuje, czy granted obejmuje wszystkie zakresy z requested
+func containsAll(granted, requested []string) bool {
+	for _, scope := range requested {
+		found := false
+		for _, g := range granted {
+			if g == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
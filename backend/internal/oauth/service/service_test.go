@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/domain"
+)
+
+const testVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk" // RFC 7636, dodatek B
+
+func challengeFor(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type flowFixture struct {
+	svc    *OAuthService
+	client *domain.Client
+	secret string
+	tokens *fakeTokenIssuer
+	userID uuid.UUID
+}
+
+func newFlowFixture() *flowFixture {
+	client, secret := newConfidentialClient("bsc_app")
+	tokens := newFakeTokenIssuer()
+	return &flowFixture{
+		svc:    NewOAuthService(newFakeClientRepo(client), newFakeCodeRepo(), newFakeConsentRepo(), tokens, time.Minute, nil),
+		client: client,
+		secret: secret,
+		tokens: tokens,
+		userID: uuid.New(),
+	}
+}
+
+// authorize przechodzi przez ekran zgody i zwraca kod z adresu zwrotnego
+func (f *flowFixture) authorize(t *testing.T) string {
+	t.Helper()
+	result, err := f.svc.Authorize(context.Background(), f.userID, &domain.ConsentDecision{
+		AuthorizationRequest: domain.AuthorizationRequest{
+			ResponseType:        "code",
+			ClientID:            f.client.ClientID,
+			RedirectURI:         f.client.RedirectURIs[0],
+			State:               "xyz",
+			CodeChallenge:       challengeFor(testVerifier),
+			CodeChallengeMethod: codeChallengeMethodS256,
+		},
+		Approve: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redirect, err := url.Parse(result.RedirectURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := redirect.Query().Get("state"); state != "xyz" {
+		t.Errorf("state = %q, want xyz", state)
+	}
+	return redirect.Query().Get("code")
+}
+
+func (f *flowFixture) exchange(code, redirectURI, verifier string) (*domain.TokenResponse, error) {
+	return f.svc.Token(context.Background(), &domain.TokenRequest{
+		GrantType:    domain.GrantTypeAuthorizationCode,
+		Code:         code,
+		RedirectURI:  redirectURI,
+		CodeVerifier: verifier,
+		ClientID:     f.client.ClientID,
+		ClientSecret: f.secret,
+	})
+}
+
+func wantOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	var oauthErr *domain.Error
+	if !errors.As(err, &oauthErr) || oauthErr.Code != code {
+		t.Fatalf("err = %v, want %s", err, code)
+	}
+}
+
+func TestVerifyCodeVerifier(t *testing.T) {
+	// Para z RFC 7636, dodatek B
+	const challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if got := challengeFor(testVerifier); got != challenge {
+		t.Fatalf("challengeFor = %s, want %s", got, challenge)
+	}
+
+	tests := []struct {
+		name     string
+		verifier string
+		want     bool
+	}{
+		{"matching verifier", testVerifier, true},
+		{"other verifier", strings.Repeat("a", 43), false},
+		{"too short", testVerifier[:42], false},
+		{"too long", strings.Repeat("a", 129), false},
+		{"reserved character", testVerifier[:42] + "+", false},
+	}
+	for _, tt := range tests {
+		if got := verifyCodeVerifier(tt.verifier, challenge); got != tt.want {
+			t.Errorf("%s: verifyCodeVerifier = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTokenExchangesCodeWithPKCE(t *testing.T) {
+	f := newFlowFixture()
+	code := f.authorize(t)
+
+	resp, err := f.exchange(code, f.client.RedirectURIs[0], testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AccessToken == "" || resp.TokenType != tokenTypeBearer {
+		t.Errorf("token response = %+v", resp)
+	}
+}
+
+func TestTokenRejectsWrongVerifier(t *testing.T) {
+	f := newFlowFixture()
+	code := f.authorize(t)
+
+	_, err := f.exchange(code, f.client.RedirectURIs[0], strings.Repeat("a", 43))
+	wantOAuthError(t, err, domain.ErrorInvalidGrant)
+	if len(f.tokens.issued) != 0 {
+		t.Errorf("tokens issued for wrong verifier: %v", f.tokens.issued)
+	}
+}
+
+func TestTokenRejectsRedirectURIMismatch(t *testing.T) {
+	f := newFlowFixture()
+	code := f.authorize(t)
+
+	_, err := f.exchange(code, "https://evil.example.com/callback", testVerifier)
+	wantOAuthError(t, err, domain.ErrorInvalidGrant)
+	if len(f.tokens.issued) != 0 {
+		t.Errorf("tokens issued for mismatched redirect_uri: %v", f.tokens.issued)
+	}
+}
+
+func TestTokenCodeReuseRevokesIssuedTokens(t *testing.T) {
+	f := newFlowFixture()
+	code := f.authorize(t)
+
+	if _, err := f.exchange(code, f.client.RedirectURIs[0], testVerifier); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := f.exchange(code, f.client.RedirectURIs[0], testVerifier)
+	wantOAuthError(t, err, domain.ErrorInvalidGrant)
+	if len(f.tokens.revoked) != 1 || f.tokens.revoked[0] != f.client.ClientID {
+		t.Errorf("revoked = %v, want tokens of %s", f.tokens.revoked, f.client.ClientID)
+	}
+	if len(f.tokens.issued) != 1 {
+		t.Errorf("issued %d token pairs, want 1", len(f.tokens.issued))
+	}
+}
+
+func TestAuthorizeRejectsUnregisteredRedirectURI(t *testing.T) {
+	f := newFlowFixture()
+
+	_, err := f.svc.Authorize(context.Background(), f.userID, &domain.ConsentDecision{
+		AuthorizationRequest: domain.AuthorizationRequest{
+			ResponseType:        "code",
+			ClientID:            f.client.ClientID,
+			RedirectURI:         "https://evil.example.com/callback",
+			CodeChallenge:       challengeFor(testVerifier),
+			CodeChallengeMethod: codeChallengeMethodS256,
+		},
+		Approve: true,
+	})
+
+	// Błąd nie może prowadzić do przekierowania na niezarejestrowany adres
+	var oauthErr *domain.Error
+	if !errors.As(err, &oauthErr) || oauthErr.Code != domain.ErrorInvalidRequest || oauthErr.RedirectURI != "" {
+		t.Fatalf("err = %#v, want invalid_request without redirect", err)
+	}
+}
+
+func TestAuthorizeRequiresS256(t *testing.T) {
+	f := newFlowFixture()
+
+	_, err := f.svc.Authorize(context.Background(), f.userID, &domain.ConsentDecision{
+		AuthorizationRequest: domain.AuthorizationRequest{
+			ResponseType:        "code",
+			ClientID:            f.client.ClientID,
+			RedirectURI:         f.client.RedirectURIs[0],
+			CodeChallenge:       testVerifier,
+			CodeChallengeMethod: "plain",
+		},
+		Approve: true,
+	})
+	wantOAuthError(t, err, domain.ErrorInvalidRequest)
+}
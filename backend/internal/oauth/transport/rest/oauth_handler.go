@@ -0,0 +1,309 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	authDomain "github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/oauth/service"
+)
+
+type OAuthHandler struct {
+	oauthService *service.OAuthService
+}
+
+func NewOAuthHandler(oauthService *service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// @Summary Dane do ekranu zgody na dostęp aplikacji
+// @Description Wywoływane przez frontend z parametrami żądania autoryzacji (RFC 6749 4.1.1, PKCE S256)
+// @Produce json
+// @Success 200 {object} domain.ConsentPrompt
+// @Failure 400 {object} domain.Error
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) PrepareAuthorization(c *gin.Context) {
+	var req domain.AuthorizationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, domain.NewError(domain.ErrorInvalidRequest, ""))
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	prompt, err := h.oauthService.PrepareAuthorization(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prompt)
+}
+
+// @Summary Decyzja użytkownika na ekranie zgody
+// @Description Zwraca adres zwrotny aplikacji z kodem autoryzacyjnym lub błędem access_denied
+// @Accept json
+// @Produce json
+// @Param input body domain.ConsentDecision true "Parametry żądania autoryzacji i decyzja"
+// @Success 200 {object} domain.AuthorizationResult
+// @Failure 400 {object} domain.Error
+// @Router /oauth/authorize [post]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req domain.ConsentDecision
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, domain.NewError(domain.ErrorInvalidRequest, ""))
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	result, err := h.oauthService.Authorize(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Endpoint tokenów OAuth2
+// @Description Granty authorization_code (z code_verifier) i refresh_token. Klient uwierzytelnia się przez HTTP Basic lub client_id/client_secret w formularzu.
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Success 200 {object} domain.TokenResponse
+// @Failure 400 {object} domain.Error
+// @Failure 401 {object} domain.Error
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	// Odpowiedzi z tokenami nie mogą trafić do cache (RFC 6749 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req domain.TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, domain.NewError(domain.ErrorInvalidRequest, ""))
+		return
+	}
+
+	usedBasic, ok := clientCredentials(c, &req.ClientID, &req.ClientSecret)
+	if !ok {
+		return
+	}
+
+	response, err := h.oauthService.Token(c.Request.Context(), &req)
+	if err != nil {
+		handleClientError(c, err, usedBasic)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Introspekcja tokenu dostępowego lub klucza API (RFC 7662)
+// @Description Wymaga uwierzytelnienia aplikacji poufnej (HTTP Basic lub client_id/client_secret).
+// @Description Serwery zasobów z konfiguracji oauth.introspectionClients widzą wszystkie tokeny,
+// @Description pozostałe aplikacje tylko własne.
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Success 200 {object} authDomain.Introspection
+// @Failure 400 {object} domain.Error
+// @Failure 401 {object} domain.Error
+// @Router /auth/introspect [post]
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	var req domain.IntrospectionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, domain.NewError(domain.ErrorInvalidRequest, "token is required"))
+		return
+	}
+
+	usedBasic, ok := clientCredentials(c, &req.ClientID, &req.ClientSecret)
+	if !ok {
+		return
+	}
+
+	result, err := h.oauthService.Introspect(c.Request.Context(), req.ClientID, req.ClientSecret, req.Token)
+	if err != nil {
+		handleClientError(c, err, usedBasic)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Rejestracja aplikacji OAuth
+// @Description Sekret klienta poufnego jest zwracany tylko w tej odpowiedzi
+// @Accept json
+// @Produce json
+// @Param input body domain.ClientCreate true "Nazwa, adresy zwrotne i dozwolone zakresy"
+// @Success 201 {object} domain.CreatedClient
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 409 {object} authRest.ErrorResponse
+// @Router /oauth/clients [post]
+func (h *OAuthHandler) RegisterClient(c *gin.Context) {
+	var req domain.ClientCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	client, err := h.oauthService.RegisterClient(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, client)
+}
+
+// @Summary Lista aplikacji zarejestrowanych przez użytkownika
+// @Produce json
+// @Success 200 {array} domain.Client
+// @Router /oauth/clients [get]
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	clients, err := h.oauthService.ListClients(c.Request.Context(), userID)
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients)
+}
+
+// @Summary Usunięcie aplikacji OAuth
+// @Description Unieważnia wszystkie tokeny odświeżające wystawione aplikacji
+// @Param clientId path string true "Identyfikator klienta"
+// @Success 204
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /oauth/clients/{clientId} [delete]
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	if err := h.oauthService.DeleteClient(c.Request.Context(), userID, c.Param("clientId")); err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Aplikacje z dostępem do konta
+// @Produce json
+// @Success 200 {array} domain.Consent
+// @Router /me/oauth/consents [get]
+func (h *OAuthHandler) ListConsents(c *gin.Context) {
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	consents, err := h.oauthService.ListConsents(c.Request.Context(), userID)
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, consents)
+}
+
+// @Summary Cofnięcie dostępu aplikacji do konta
+// @Param clientId path string true "Identyfikator klienta"
+// @Success 204
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /me/oauth/consents/{clientId} [delete]
+func (h *OAuthHandler) RevokeConsent(c *gin.Context) {
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	if err := h.oauthService.RevokeConsent(c.Request.Context(), userID, c.Param("clientId")); err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// clientCredentials uzupełnia dane aplikacji z nagłówka HTTP Basic, jeśli
+// został wysłany. Przy nieprawidłowym nagłówku odpowiada błędem i zwraca ok=false.
+func clientCredentials(c *gin.Context, clientID, clientSecret *string) (usedBasic, ok bool) {
+	id, secret, found := c.Request.BasicAuth()
+	if !found {
+		return false, true
+	}
+
+	// Wartości w nagłówku Basic są zakodowane jak formularz (RFC 6749 2.3.1)
+	id, errID := url.QueryUnescape(id)
+	secret, errSecret := url.QueryUnescape(secret)
+	if errID != nil || errSecret != nil || (*clientID != "" && *clientID != id) {
+		c.JSON(http.StatusBadRequest, domain.NewError(domain.ErrorInvalidRequest, "malformed client credentials"))
+		return false, false
+	}
+	*clientID, *clientSecret = id, secret
+	return true, true
+}
+
+// handleClientError odpowiada 401 na nieudane uwierzytelnienie aplikacji
+// (RFC 6749 5.2), a pozostałe błędy przekazuje do handleOAuthError
+func handleClientError(c *gin.Context, err error, usedBasic bool) {
+	var oauthErr *domain.Error
+	if errors.As(err, &oauthErr) && oauthErr.Code == domain.ErrorInvalidClient {
+		if usedBasic {
+			c.Header("WWW-Authenticate", `Basic realm="BookSwap"`)
+		}
+		c.JSON(http.StatusUnauthorized, oauthErr)
+		return
+	}
+	handleOAuthError(c, err)
+}
+
+// handleOAuthError mapuje błędy protokołu na format RFC 6749, a pozostałe na ErrorResponse
+func handleOAuthError(c *gin.Context, err error) {
+	var oauthErr *domain.Error
+	if errors.As(err, &oauthErr) {
+		c.JSON(http.StatusBadRequest, oauthErr)
+		return
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrClientNotFound):
+		c.JSON(http.StatusNotFound, authRest.ErrorResponse{
+			Code:    "client-not-found",
+			Message: "Nie znaleziono aplikacji",
+		})
+	case errors.Is(err, domain.ErrConsentNotFound):
+		c.JSON(http.StatusNotFound, authRest.ErrorResponse{
+			Code:    "consent-not-found",
+			Message: "Ta aplikacja nie ma dostępu do konta",
+		})
+	case errors.Is(err, domain.ErrRedirectURIsRequired):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-redirect-uri",
+			Message: "Podaj od 1 do 10 adresów zwrotnych",
+			Field:   "redirectUris",
+		})
+	case errors.Is(err, domain.ErrInvalidRedirectURI):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-redirect-uri",
+			Message: "Adres zwrotny musi używać https (http tylko dla localhost)",
+			Field:   "redirectUris",
+		})
+	case errors.Is(err, authDomain.ErrInvalidScope):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-scope",
+			Message: "Nieznany zakres uprawnień",
+			Field:   "scopes",
+		})
+	case errors.Is(err, domain.ErrClientLimitReached):
+		c.JSON(http.StatusConflict, authRest.ErrorResponse{
+			Code:    "client-limit-reached",
+			Message: "Osiągnięto limit zarejestrowanych aplikacji",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, authRest.ErrorResponse{
+			Code:    "internal-error",
+			Message: "Wystąpił błąd wewnętrzny",
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
 )
 
 // Review reprezentuje ocenę/opinię o użytkowniku
@@ -32,3 +34,8 @@ type ReviewCreate struct {
 	Rating        int       `json:"rating" binding:"required,min=1,max=5"`
 	Comment       string    `json:"comment"`
 }
+
+// ReviewFilter reprezentuje parametry listy opinii o użytkowniku
+type ReviewFilter struct {
+	pagination.Request
+}
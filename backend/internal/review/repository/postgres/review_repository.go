@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/review/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+type ReviewRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReviewRepository(db *pgxpool.Pool) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// reviewKeysets opisuje kolumny sortowania listy opinii
+var reviewKeysets = map[string]pagination.Keyset{
+	pagination.SortNewest: {Key: "r.created_at", ID: "r.id", Desc: true},
+	pagination.SortRating: {Key: "r.rating", ID: "r.id", Desc: true},
+}
+
+// ListForUser zwraca stronę opinii wystawionych użytkownikowi
+func (r *ReviewRepository) ListForUser(ctx context.Context, reviewedID uuid.UUID, page pagination.Params) (*pagination.Page[domain.Review], error) {
+	args := []any{reviewedID}
+	condition := "r.reviewed_id = $1"
+	keyset := reviewKeysets[page.Sort]
+
+	if page.After != nil {
+		key, err := decodeReviewSortKey(page.After, page.Sort)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, key, page.After.ID)
+		condition += " AND " + keyset.After(len(args)-1)
+	}
+
+	args = append(args, page.Limit+1)
+	query := `SELECT ` + reviewColumns + `
+		FROM reviews r
+		JOIN users rv ON rv.id = r.reviewer_id
+		JOIN users rd ON rd.id = r.reviewed_id
+		WHERE ` + condition + `
+		ORDER BY ` + keyset.OrderBy() + fmt.Sprintf(` LIMIT $%d`, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	reviews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Review, error) {
+		return scanReview(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	return pagination.NewPage(reviews, page, func(rv domain.Review) (any, uuid.UUID) {
+		if page.Sort == pagination.SortRating {
+			return rv.Rating, rv.ID
+		}
+		return rv.CreatedAt, rv.ID
+	})
+}
+
+// decodeReviewSortKey odczytuje z kursora klucz sortowania w typie kolumny
+func decodeReviewSortKey(cursor *pagination.Cursor, sort string) (any, error) {
+	if sort == pagination.SortRating {
+		var rating int
+		err := cursor.ScanKey(&rating)
+		return rating, err
+	}
+
+	var createdAt time.Time
+	err := cursor.ScanKey(&createdAt)
+	return createdAt, err
+}
+
+const reviewColumns = `r.id, r.transaction_id, r.rating, COALESCE(r.comment, ''), r.created_at,
+	rv.id, rv.name, rd.id, rd.name`
+
+func scanReview(row pgx.Row) (domain.Review, error) {
+	var rv domain.Review
+	var reviewer, reviewed domain.User
+
+	err := row.Scan(
+		&rv.ID,
+		&rv.TransactionID,
+		&rv.Rating,
+		&rv.Comment,
+		&rv.CreatedAt,
+		&reviewer.ID,
+		&reviewer.Name,
+		&reviewed.ID,
+		&reviewed.Name,
+	)
+	if err != nil {
+		return domain.Review{}, err
+	}
+
+	rv.ReviewerID, rv.Reviewer = reviewer.ID, &reviewer
+	rv.ReviewedID, rv.Reviewed = reviewed.ID, &reviewed
+	return rv, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/review/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+// ReviewRepository interfejs definiujący metody dostępu do danych
+type ReviewRepository interface {
+	ListForUser(ctx context.Context, reviewedID uuid.UUID, page pagination.Params) (*pagination.Page[domain.Review], error)
+}
+
+type ReviewService struct {
+	repo ReviewRepository
+}
+
+func NewReviewService(repo ReviewRepository) *ReviewService {
+	return &ReviewService{
+		repo: repo,
+	}
+}
+
+// ListUserReviews zwraca stronę opinii o użytkowniku, domyślnie od najnowszych
+func (s *ReviewService) ListUserReviews(ctx context.Context, userID uuid.UUID, filter domain.ReviewFilter) (*pagination.Page[domain.Review], error) {
+	page, err := filter.Parse(pagination.SortNewest, pagination.SortRating)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListForUser(ctx, userID, page)
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	"github.com/Ex6linz/BookSwap/backend/internal/review/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/review/service"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+type ReviewHandler struct {
+	reviewService *service.ReviewService
+}
+
+func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+// @Summary Opinie o użytkowniku
+// @Produce json
+// @Param id path string true "ID użytkownika"
+// @Param sort query string false "newest lub rating"
+// @Param limit query int false "Liczba wyników (domyślnie 20, maks. 100)"
+// @Param cursor query string false "Kursor nextCursor z poprzedniej strony"
+// @Success 200 {object} pagination.Page[domain.Review]
+// @Failure 400 {object} authRest.ErrorResponse
+// @Router /users/{id}/reviews [get]
+func (h *ReviewHandler) ListForUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-id",
+			Message: "Nieprawidłowy identyfikator",
+		})
+		return
+	}
+
+	var filter domain.ReviewFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowe parametry zapytania",
+		})
+		return
+	}
+
+	page, err := h.reviewService.ListUserReviews(c.Request.Context(), userID, filter)
+	if err != nil {
+		handleReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func handleReviewError(c *gin.Context, err error) {
+	var pageErr *pagination.Error
+
+	switch {
+	case errors.As(err, &pageErr):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-pagination",
+			Message: "Nieprawidłowa wartość parametru " + pageErr.Field,
+			Field:   pageErr.Field,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, authRest.ErrorResponse{
+			Code:    "internal-error",
+			Message: "Wystąpił błąd wewnętrzny",
+		})
+	}
+}
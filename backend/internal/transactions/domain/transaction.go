@@ -4,6 +4,14 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+// Rola użytkownika w transakcji
+const (
+	RoleLender   = "lender"
+	RoleBorrower = "borrower"
 )
 
 // Transaction reprezentuje transakcję wypożyczenia/wymiany książki
@@ -56,3 +64,11 @@ type TransactionUpdate struct {
 	ReturnDate *time.Time `json:"returnDate"`
 	Notes      string     `json:"notes"`
 }
+
+// TransactionFilter reprezentuje parametry listy transakcji użytkownika
+type TransactionFilter struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending active completed canceled"`
+	Role   string `form:"role" binding:"omitempty,oneof=lender borrower"` // pusty: obie strony
+
+	pagination.Request
+}
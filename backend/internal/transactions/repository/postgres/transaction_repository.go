@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/transactions/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+type TransactionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// transactionKeyset sortuje transakcje od najnowszych
+var transactionKeyset = pagination.Keyset{Key: "t.created_at", ID: "t.id", Desc: true}
+
+// ListForUser zwraca stronę transakcji, w których użytkownik jest stroną
+func (r *TransactionRepository) ListForUser(ctx context.Context, userID uuid.UUID, filter domain.TransactionFilter, page pagination.Params) (*pagination.Page[domain.Transaction], error) {
+	args := []any{userID}
+	var conditions []string
+
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	switch filter.Role {
+	case domain.RoleLender:
+		conditions = append(conditions, "t.lender_id = $1")
+	case domain.RoleBorrower:
+		conditions = append(conditions, "t.borrower_id = $1")
+	default:
+		conditions = append(conditions, "(t.lender_id = $1 OR t.borrower_id = $1)")
+	}
+	if filter.Status != "" {
+		addCondition("t.status = $%d", filter.Status)
+	}
+
+	if page.After != nil {
+		var createdAt time.Time
+		if err := page.After.ScanKey(&createdAt); err != nil {
+			return nil, err
+		}
+		args = append(args, createdAt, page.After.ID)
+		conditions = append(conditions, transactionKeyset.After(len(args)-1))
+	}
+
+	args = append(args, page.Limit+1)
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN books b ON b.id = t.book_id
+		JOIN users l ON l.id = t.lender_id
+		JOIN users br ON br.id = t.borrower_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + transactionKeyset.OrderBy() + fmt.Sprintf(` LIMIT $%d`, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	transactions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Transaction, error) {
+		return scanTransaction(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	return pagination.NewPage(transactions, page, func(t domain.Transaction) (any, uuid.UUID) {
+		return t.CreatedAt, t.ID
+	})
+}
+
+const transactionColumns = `t.id, t.status, t.transaction_type, t.start_date, t.due_date, t.return_date,
+	COALESCE(t.notes, ''), t.created_at, t.updated_at,
+	b.id, b.title, b.author,
+	COALESCE((SELECT array_agg(i.image_url ORDER BY i.created_at) FROM book_images i WHERE i.book_id = b.id), '{}'),
+	l.id, l.name, COALESCE(l.rating, 0)::float8,
+	br.id, br.name, COALESCE(br.rating, 0)::float8`
+
+func scanTransaction(row pgx.Row) (domain.Transaction, error) {
+	var t domain.Transaction
+	var book domain.Book
+	var lender, borrower domain.User
+
+	err := row.Scan(
+		&t.ID,
+		&t.Status,
+		&t.TransactionType,
+		&t.StartDate,
+		&t.DueDate,
+		&t.ReturnDate,
+		&t.Notes,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&book.ID,
+		&book.Title,
+		&book.Author,
+		&book.ImageURLs,
+		&lender.ID,
+		&lender.Name,
+		&lender.Rating,
+		&borrower.ID,
+		&borrower.Name,
+		&borrower.Rating,
+	)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	t.BookID, t.Book = book.ID, &book
+	t.LenderID, t.Lender = lender.ID, &lender
+	t.BorrowerID, t.Borrower = borrower.ID, &borrower
+	return t, nil
+}
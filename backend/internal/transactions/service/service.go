@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/transactions/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+// TransactionRepository interfejs definiujący metody dostępu do danych
+type TransactionRepository interface {
+	ListForUser(ctx context.Context, userID uuid.UUID, filter domain.TransactionFilter, page pagination.Params) (*pagination.Page[domain.Transaction], error)
+}
+
+type TransactionService struct {
+	repo TransactionRepository
+}
+
+func NewTransactionService(repo TransactionRepository) *TransactionService {
+	return &TransactionService{
+		repo: repo,
+	}
+}
+
+// ListTransactions zwraca stronę transakcji użytkownika, od najnowszych
+func (s *TransactionService) ListTransactions(ctx context.Context, userID uuid.UUID, filter domain.TransactionFilter) (*pagination.Page[domain.Transaction], error) {
+	page, err := filter.Parse(pagination.SortNewest)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListForUser(ctx, userID, filter, page)
+}
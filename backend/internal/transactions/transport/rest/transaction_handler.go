@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	"github.com/Ex6linz/BookSwap/backend/internal/transactions/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/transactions/service"
+	"github.com/Ex6linz/BookSwap/backend/pkg/pagination"
+)
+
+type TransactionHandler struct {
+	transactionService *service.TransactionService
+}
+
+func NewTransactionHandler(transactionService *service.TransactionService) *TransactionHandler {
+	return &TransactionHandler{transactionService: transactionService}
+}
+
+// @Summary Transakcje zalogowanego użytkownika
+// @Produce json
+// @Param role query string false "lender lub borrower"
+// @Param status query string false "pending, active, completed lub canceled"
+// @Param limit query int false "Liczba wyników (domyślnie 20, maks. 100)"
+// @Param cursor query string false "Kursor nextCursor z poprzedniej strony"
+// @Success 200 {object} pagination.Page[domain.Transaction]
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 401 {object} authRest.ErrorResponse
+// @Router /me/transactions [get]
+func (h *TransactionHandler) ListMine(c *gin.Context) {
+	var filter domain.TransactionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowe parametry zapytania",
+		})
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	page, err := h.transactionService.ListTransactions(c.Request.Context(), userID, filter)
+	if err != nil {
+		handleTransactionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func handleTransactionError(c *gin.Context, err error) {
+	var pageErr *pagination.Error
+
+	switch {
+	case errors.As(err, &pageErr):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-pagination",
+			Message: "Nieprawidłowa wartość parametru " + pageErr.Field,
+			Field:   pageErr.Field,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, authRest.ErrorResponse{
+			Code:    "internal-error",
+			Message: "Wystąpił błąd wewnętrzny",
+		})
+	}
+}
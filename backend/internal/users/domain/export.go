@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	bookDomain "github.com/Ex6linz/BookSwap/backend/internal/book/domain"
+	messagesDomain "github.com/Ex6linz/BookSwap/backend/internal/messages/domain"
+	reviewDomain "github.com/Ex6linz/BookSwap/backend/internal/review/domain"
+	transactionsDomain "github.com/Ex6linz/BookSwap/backend/internal/transactions/domain"
+)
+
+// Export to komplet danych użytkownika udostępniany na jego żądanie (art. 20 RODO)
+type Export struct {
+	ExportedAt   time.Time                        `json:"exportedAt"`
+	Profile      *User                            `json:"profile"`
+	Books        []bookDomain.Book                `json:"books"`
+	Transactions []transactionsDomain.Transaction `json:"transactions"`
+	Messages     []messagesDomain.Message         `json:"messages"`
+	Reviews      []reviewDomain.Review            `json:"reviews"` // wystawione i otrzymane
+}
@@ -1,23 +1,94 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/pkg/geo"
+)
+
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrDeletionNotRequested = errors.New("account deletion not requested")
+)
+
+// Dane wstawiane w miejsce usuniętych treści
+const (
+	DeletedUserName       = "Usunięty użytkownik"
+	DeletedMessageContent = "[wiadomość usunięta]"
+
+	// BookStatusRemoved ukrywa ogłoszenie, do którego odwołują się transakcje
+	BookStatusRemoved = "removed"
+)
+
+// Maksymalne długości pól profilu (w znakach, zgodnie z kolumnami tabeli users)
+const (
+	MaxNameLength      = 100
+	MaxLocationLength  = 255
+	MaxBioLength       = 1000
+	MaxAvatarURLLength = 255
 )
 
 // User reprezentuje użytkownika aplikacji
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	Name         string    `json:"name"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // nigdy nie wysyłamy hasza w JSONie
-	Location     string    `json:"location,omitempty"`
-	Bio          string    `json:"bio,omitempty"`
-	AvatarURL    string    `json:"avatarUrl,omitempty"`
-	Rating       float64   `json:"rating"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID                  uuid.UUID  `json:"id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	PasswordHash        string     `json:"-"` // nigdy nie wysyłamy hasza w JSONie
+	Location            string     `json:"location,omitempty"`
+	Coordinates         *geo.Point `json:"coordinates,omitempty"` // dokładne, widoczne tylko dla właściciela
+	Bio                 string     `json:"bio,omitempty"`
+	AvatarURL           string     `json:"avatarUrl,omitempty"`
+	Rating              float64    `json:"rating"`
+	Role                string     `json:"role"`
+	EmailVerifiedAt     *time.Time `json:"emailVerifiedAt,omitempty"`
+	DeletionRequestedAt *time.Time `json:"deletionRequestedAt,omitempty"` // prośba o usunięcie w okresie karencji
+	DeletedAt           *time.Time `json:"-"`                             // konto zanonimizowane
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+// IsDeleted informuje, że konto zostało już zanonimizowane
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// PublicProfile to widok użytkownika dla innych osób - bez adresu email i danych konta
+type PublicProfile struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Location    string     `json:"location,omitempty"`
+	Coordinates *geo.Point `json:"coordinates,omitempty"` // zgrubne, zob. geo.Point.Public
+	Bio         string     `json:"bio,omitempty"`
+	AvatarURL   string     `json:"avatarUrl,omitempty"`
+	Rating      float64    `json:"rating"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// Public zwraca publiczny widok profilu
+func (u *User) Public() *PublicProfile {
+	var coordinates *geo.Point
+	if u.Coordinates != nil {
+		public := u.Coordinates.Public()
+		coordinates = &public
+	}
+
+	return &PublicProfile{
+		ID:          u.ID,
+		Name:        u.Name,
+		Location:    u.Location,
+		Coordinates: coordinates,
+		Bio:         u.Bio,
+		AvatarURL:   u.AvatarURL,
+		Rating:      u.Rating,
+		CreatedAt:   u.CreatedAt,
+	}
 }
 
 // UserRegister reprezentuje dane do rejestracji
@@ -34,10 +105,91 @@ type UserLogin struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// UserUpdate reprezentuje dane do aktualizacji profilu
+// UserUpdate reprezentuje dane do aktualizacji profilu. Pominięte pola
+// (nil) zostają bez zmian, pusty tekst czyści pole opcjonalne.
 type UserUpdate struct {
-	Name      string `json:"name"`
-	Location  string `json:"location"`
-	Bio       string `json:"bio"`
-	AvatarURL string `json:"avatarUrl"`
+	Name        *string    `json:"name"`
+	Location    *string    `json:"location"`
+	Coordinates *geo.Point `json:"coordinates"`
+	Bio         *string    `json:"bio"`
+	AvatarURL   *string    `json:"avatarUrl"`
+
+	// RemoveCoordinates usuwa zapisane współrzędne
+	RemoveCoordinates bool `json:"removeCoordinates"`
+}
+
+// ValidationError opisuje nieprawidłową wartość konkretnego pola
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+// Normalize przycina białe znaki i sprawdza długości pól
+func (u *UserUpdate) Normalize() error {
+	for _, field := range []*string{u.Name, u.Location, u.Bio, u.AvatarURL} {
+		if field != nil {
+			*field = strings.TrimSpace(*field)
+		}
+	}
+
+	if u.Name != nil && *u.Name == "" {
+		return &ValidationError{Field: "name", Reason: "required"}
+	}
+	if err := checkLength("name", u.Name, MaxNameLength); err != nil {
+		return err
+	}
+	if err := checkLength("location", u.Location, MaxLocationLength); err != nil {
+		return err
+	}
+	if err := checkLength("bio", u.Bio, MaxBioLength); err != nil {
+		return err
+	}
+	if err := checkLength("avatarUrl", u.AvatarURL, MaxAvatarURLLength); err != nil {
+		return err
+	}
+
+	if u.Coordinates != nil && u.RemoveCoordinates {
+		return &ValidationError{Field: "coordinates", Reason: "cannot be set and removed at once"}
+	}
+	if u.Coordinates != nil && u.Coordinates.Validate() != nil {
+		return &ValidationError{Field: "coordinates", Reason: "out of range"}
+	}
+
+	if u.AvatarURL != nil && *u.AvatarURL != "" {
+		parsed, err := url.Parse(*u.AvatarURL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return &ValidationError{Field: "avatarUrl", Reason: "invalid url"}
+		}
+	}
+
+	return nil
+}
+
+// IsEmpty informuje, że żądanie niczego nie zmienia
+func (u *UserUpdate) IsEmpty() bool {
+	return u.Name == nil && u.Location == nil && u.Bio == nil && u.AvatarURL == nil &&
+		u.Coordinates == nil && !u.RemoveCoordinates
+}
+
+func checkLength(field string, value *string, max int) error {
+	if value != nil && utf8.RuneCountInString(*value) > max {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("longer than %d characters", max)}
+	}
+	return nil
+}
+
+// DeleteAccountRequest reprezentuje prośbę o usunięcie konta
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+	IP       string `json:"-"` // uzupełniany przez handler
+}
+
+// DeletionStatus informuje, kiedy konto zostanie ostatecznie usunięte
+type DeletionStatus struct {
+	RequestedAt time.Time `json:"requestedAt"`
+	ScheduledAt time.Time `json:"scheduledAt"`
 }
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	bookDomain "github.com/Ex6linz/BookSwap/backend/internal/book/domain"
+	messagesDomain "github.com/Ex6linz/BookSwap/backend/internal/messages/domain"
+	reviewDomain "github.com/Ex6linz/BookSwap/backend/internal/review/domain"
+	transactionsDomain "github.com/Ex6linz/BookSwap/backend/internal/transactions/domain"
+)
+
+// ExportRepository zbiera dane użytkownika ze wszystkich modułów na potrzeby eksportu
+type ExportRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewExportRepository(db *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+func (r *ExportRepository) ListBooks(ctx context.Context, ownerID uuid.UUID) ([]bookDomain.Book, error) {
+	query := `SELECT b.id, b.title, b.author, COALESCE(b.description, ''), COALESCE(b.isbn, ''), 
+			b.category_id, b.condition, b.owner_id, b.status, 
+			COALESCE(array_agg(i.image_url ORDER BY i.created_at) FILTER (WHERE i.id IS NOT NULL), '{}'), 
+			b.created_at, b.updated_at 
+		FROM books b 
+		LEFT JOIN book_images i ON i.book_id = b.id 
+		WHERE b.owner_id = $1 
+		GROUP BY b.id 
+		ORDER BY b.created_at`
+
+	rows, err := r.db.Query(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list books: %w", err)
+	}
+
+	books, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (bookDomain.Book, error) {
+		var book bookDomain.Book
+		var categoryID *uuid.UUID
+		err := row.Scan(
+			&book.ID,
+			&book.Title,
+			&book.Author,
+			&book.Description,
+			&book.ISBN,
+			&categoryID,
+			&book.Condition,
+			&book.OwnerID,
+			&book.Status,
+			&book.ImageURLs,
+			&book.CreatedAt,
+			&book.UpdatedAt,
+		)
+		if categoryID != nil {
+			book.CategoryID = *categoryID
+		}
+		return book, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list books: %w", err)
+	}
+	return books, nil
+}
+
+func (r *ExportRepository) ListTransactions(ctx context.Context, userID uuid.UUID) ([]transactionsDomain.Transaction, error) {
+	query := `SELECT id, book_id, lender_id, borrower_id, status, transaction_type, 
+			start_date, due_date, return_date, COALESCE(notes, ''), created_at, updated_at 
+		FROM transactions 
+		WHERE lender_id = $1 OR borrower_id = $1 
+		ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	transactions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (transactionsDomain.Transaction, error) {
+		var t transactionsDomain.Transaction
+		err := row.Scan(
+			&t.ID,
+			&t.BookID,
+			&t.LenderID,
+			&t.BorrowerID,
+			&t.Status,
+			&t.TransactionType,
+			&t.StartDate,
+			&t.DueDate,
+			&t.ReturnDate,
+			&t.Notes,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+		return t, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+func (r *ExportRepository) ListMessages(ctx context.Context, userID uuid.UUID) ([]messagesDomain.Message, error) {
+	query := `SELECT id, sender_id, receiver_id, transaction_id, content, read, created_at 
+		FROM messages 
+		WHERE sender_id = $1 OR receiver_id = $1 
+		ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (messagesDomain.Message, error) {
+		var m messagesDomain.Message
+		err := row.Scan(
+			&m.ID,
+			&m.SenderID,
+			&m.ReceiverID,
+			&m.TransactionID,
+			&m.Content,
+			&m.Read,
+			&m.CreatedAt,
+		)
+		return m, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (r *ExportRepository) ListReviews(ctx context.Context, userID uuid.UUID) ([]reviewDomain.Review, error) {
+	query := `SELECT id, transaction_id, reviewer_id, reviewed_id, rating, COALESCE(comment, ''), created_at 
+		FROM reviews 
+		WHERE reviewer_id = $1 OR reviewed_id = $1 
+		ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	reviews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (reviewDomain.Review, error) {
+		var rv reviewDomain.Review
+		err := row.Scan(
+			&rv.ID,
+			&rv.TransactionID,
+			&rv.ReviewerID,
+			&rv.ReviewedID,
+			&rv.Rating,
+			&rv.Comment,
+			&rv.CreatedAt,
+		)
+		return rv, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	return reviews, nil
+}
@@ -0,0 +1,244 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/users/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/geo"
+)
+
+type UserRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, id))
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	return user, err
+}
+
+// Update zmienia tylko przekazane pola profilu i zwraca zaktualizowanego użytkownika
+func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, update *domain.UserUpdate, now time.Time) (*domain.User, error) {
+	query := `UPDATE users SET
+			name = COALESCE($2, name),
+			location = COALESCE($3, location),
+			bio = COALESCE($4, bio),
+			avatar_url = COALESCE($5, avatar_url),
+			latitude = CASE WHEN $6 THEN NULL ELSE COALESCE($7, latitude) END,
+			longitude = CASE WHEN $6 THEN NULL ELSE COALESCE($8, longitude) END,
+			updated_at = $9
+		WHERE id = $1
+		RETURNING ` + userColumns
+
+	var latitude, longitude *float64
+	if update.Coordinates != nil {
+		latitude, longitude = &update.Coordinates.Latitude, &update.Coordinates.Longitude
+	}
+
+	user, err := scanUser(r.db.QueryRow(ctx, query,
+		id,
+		update.Name,
+		update.Location,
+		update.Bio,
+		update.AvatarURL,
+		update.RemoveCoordinates,
+		latitude,
+		longitude,
+		now,
+	))
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return user, err
+}
+
+// RequestDeletion oznacza konto do usunięcia po okresie karencji
+func (r *UserRepository) RequestDeletion(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE users SET deletion_requested_at = COALESCE(deletion_requested_at, $2), updated_at = $2 
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to request account deletion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// CancelDeletion wycofuje prośbę o usunięcie konta
+func (r *UserRepository) CancelDeletion(ctx context.Context, id uuid.UUID, now time.Time) error {
+	query := `UPDATE users SET deletion_requested_at = NULL, updated_at = $2 
+		WHERE id = $1 AND deleted_at IS NULL AND deletion_requested_at IS NOT NULL`
+
+	tag, err := r.db.Exec(ctx, query, id, now)
+	if err != nil {
+		return fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDeletionNotRequested
+	}
+	return nil
+}
+
+// ListDueForDeletion zwraca konta, których okres karencji minął przed podaną chwilą
+func (r *UserRepository) ListDueForDeletion(ctx context.Context, requestedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	query := `SELECT id FROM users 
+		WHERE deletion_requested_at <= $1 AND deleted_at IS NULL 
+		ORDER BY deletion_requested_at LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, requestedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts due for deletion: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts due for deletion: %w", err)
+	}
+	return ids, nil
+}
+
+// Anonymize usuwa dane osobowe użytkownika w jednej transakcji. Wiersz users
+// zostaje, żeby transakcje i opinie innych osób zachowały spójność; znikają
+// dane logowania, treści prywatne i ogłoszenia, do których nic się nie odwołuje.
+// Zwraca klucze usuniętych zdjęć ogłoszeń - pliki kasuje wywołujący, dopiero
+// po zatwierdzeniu transakcji.
+func (r *UserRepository) Anonymize(ctx context.Context, id uuid.UUID, now time.Time) ([]string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE users SET 
+			name = $2, email = $3, password_hash = '', location = NULL, bio = NULL, avatar_url = NULL, 
+			latitude = NULL, longitude = NULL, 
+			email_verified_at = NULL, deletion_requested_at = NULL, deleted_at = $4, updated_at = $4 
+		WHERE id = $1 AND deleted_at IS NULL`,
+		id,
+		domain.DeletedUserName,
+		fmt.Sprintf("deleted-%s@deleted.invalid", id),
+		now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, domain.ErrUserNotFound
+	}
+
+	// Zdjęcia znikają ze wszystkich ogłoszeń, także tych tylko ukrywanych.
+	// Zdjęcia sprzed migracji 019 nie mają plików w magazynie (storage_key NULL).
+	rows, err := tx.Query(ctx, `WITH deleted AS (
+			DELETE FROM book_images 
+			WHERE book_id IN (SELECT id FROM books WHERE owner_id = $1) 
+			RETURNING storage_key
+		)
+		SELECT storage_key FROM deleted WHERE storage_key IS NOT NULL`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize book images: %w", err)
+	}
+	storageKeys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize book images: %w", err)
+	}
+
+	statements := []struct {
+		name  string
+		query string
+		args  []any
+	}{
+		{"refresh tokens", `DELETE FROM refresh_tokens WHERE user_id = $1`, []any{id}},
+		{"sessions", `DELETE FROM sessions WHERE user_id = $1`, []any{id}},
+		{"api keys", `DELETE FROM api_keys WHERE user_id = $1`, []any{id}},
+		{"one-time tokens", `DELETE FROM one_time_tokens WHERE user_id = $1`, []any{id}},
+		{"mfa", `DELETE FROM user_mfa WHERE user_id = $1`, []any{id}},
+		{"mfa recovery codes", `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, []any{id}},
+		{"identities", `DELETE FROM user_identities WHERE user_id = $1`, []any{id}},
+		{"oauth consents", `DELETE FROM oauth_consents WHERE user_id = $1`, []any{id}},
+		{"oauth codes", `DELETE FROM oauth_authorization_codes WHERE user_id = $1`, []any{id}},
+		// Aplikacje użytkownika wyłączamy razem z tokenami wystawionymi innym osobom
+		{"oauth client tokens", `UPDATE refresh_tokens SET revoked_at = $2 WHERE revoked_at IS NULL 
+			AND client_id IN (SELECT client_id FROM oauth_clients WHERE owner_id = $1)`, []any{id, now}},
+		{"oauth clients", `UPDATE oauth_clients SET revoked_at = COALESCE(revoked_at, $2) WHERE owner_id = $1`,
+			[]any{id, now}},
+		{"wishlist", `DELETE FROM wishlist_items WHERE user_id = $1`, []any{id}},
+		{"notifications", `DELETE FROM notifications WHERE user_id = $1`, []any{id}},
+		// Ogłoszenia bez historii transakcji kasujemy, pozostałe tylko ukrywamy
+		{"books", `DELETE FROM books b WHERE b.owner_id = $1 
+			AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.book_id = b.id)`, []any{id}},
+		{"book listings", `UPDATE books SET status = $2, description = NULL, 
+			pickup_latitude = NULL, pickup_longitude = NULL, updated_at = $3 WHERE owner_id = $1`,
+			[]any{id, domain.BookStatusRemoved, now}},
+		// Rozmówca zachowuje wątek, ale bez treści napisanych przez usuniętego użytkownika
+		{"messages", `UPDATE messages SET content = $2 WHERE sender_id = $1`, []any{id, domain.DeletedMessageContent}},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt.query, stmt.args...); err != nil {
+			return nil, fmt.Errorf("failed to anonymize %s: %w", stmt.name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return storageKeys, nil
+}
+
+// Pola opcjonalne mogą być NULL w starszych wierszach
+const userColumns = `id, name, email, password_hash, COALESCE(location, ''), COALESCE(bio, ''), 
+	COALESCE(avatar_url, ''), COALESCE(rating, 0)::float8, role, email_verified_at, 
+	deletion_requested_at, deleted_at, created_at, updated_at, latitude, longitude`
+
+func scanUser(row pgx.Row) (*domain.User, error) {
+	var user domain.User
+	var latitude, longitude *float64
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Location,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.Rating,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.DeletionRequestedAt,
+		&user.DeletedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&latitude,
+		&longitude,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if latitude != nil && longitude != nil {
+		user.Coordinates = &geo.Point{Latitude: *latitude, Longitude: *longitude}
+	}
+	return &user, nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	authDomain "github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	bookDomain "github.com/Ex6linz/BookSwap/backend/internal/book/domain"
+	messagesDomain "github.com/Ex6linz/BookSwap/backend/internal/messages/domain"
+	reviewDomain "github.com/Ex6linz/BookSwap/backend/internal/review/domain"
+	transactionsDomain "github.com/Ex6linz/BookSwap/backend/internal/transactions/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/users/domain"
+	"github.com/Ex6linz/BookSwap/backend/pkg/mailer"
+)
+
+// Liczba kont anonimizowanych w jednym przebiegu czyszczenia
+const purgeBatchSize = 100
+
+// AccountRepository interfejs definiujący operacje na cyklu życia konta
+type AccountRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	RequestDeletion(ctx context.Context, id uuid.UUID, at time.Time) error
+	CancelDeletion(ctx context.Context, id uuid.UUID, now time.Time) error
+	ListDueForDeletion(ctx context.Context, requestedBefore time.Time, limit int) ([]uuid.UUID, error)
+	Anonymize(ctx context.Context, id uuid.UUID, now time.Time) ([]string, error)
+}
+
+// ExportRepository interfejs definiujący odczyt danych użytkownika z innych modułów
+type ExportRepository interface {
+	ListBooks(ctx context.Context, ownerID uuid.UUID) ([]bookDomain.Book, error)
+	ListTransactions(ctx context.Context, userID uuid.UUID) ([]transactionsDomain.Transaction, error)
+	ListMessages(ctx context.Context, userID uuid.UUID) ([]messagesDomain.Message, error)
+	ListReviews(ctx context.Context, userID uuid.UUID) ([]reviewDomain.Review, error)
+}
+
+// PasswordVerifier ponownie uwierzytelnia użytkownika (implementuje go auth.AuthService)
+type PasswordVerifier interface {
+	VerifyPassword(ctx context.Context, userID uuid.UUID, password, ip string) (*authDomain.User, error)
+}
+
+// SessionRevoker unieważnia wszystkie sesje użytkownika (implementuje go auth.AuthService)
+type SessionRevoker interface {
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+}
+
+// ImageRemover usuwa pliki zdjęć ogłoszeń z magazynu (implementuje go book.BookService)
+type ImageRemover interface {
+	DeleteImageFiles(ctx context.Context, storageKeys []string)
+}
+
+type AccountService struct {
+	repo          AccountRepository
+	exports       ExportRepository
+	passwords     PasswordVerifier
+	sessions      SessionRevoker
+	images        ImageRemover
+	mailer        mailer.Mailer
+	deletionGrace time.Duration
+}
+
+func NewAccountService(
+	repo AccountRepository,
+	exports ExportRepository,
+	passwords PasswordVerifier,
+	sessions SessionRevoker,
+	images ImageRemover,
+	mail mailer.Mailer,
+	deletionGrace time.Duration,
+) *AccountService {
+	return &AccountService{
+		repo:          repo,
+		exports:       exports,
+		passwords:     passwords,
+		sessions:      sessions,
+		images:        images,
+		mailer:        mail,
+		deletionGrace: deletionGrace,
+	}
+}
+
+// RequestDeletion planuje usunięcie konta po okresie karencji i wylogowuje
+// wszystkie sesje. Do końca karencji można się zalogować i anulować usunięcie.
+func (s *AccountService) RequestDeletion(ctx context.Context, userID uuid.UUID, req *domain.DeleteAccountRequest) (*domain.DeletionStatus, error) {
+	authUser, err := s.passwords.VerifyPassword(ctx, userID, req.Password, req.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RequestDeletion(ctx, userID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	// Ponowna prośba nie przesuwa terminu - odczytujemy zapisaną datę
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.DeletionStatus{
+		RequestedAt: *user.DeletionRequestedAt,
+		ScheduledAt: user.DeletionRequestedAt.Add(s.deletionGrace),
+	}
+
+	if err := s.sessions.LogoutAll(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      authUser.Email,
+		Subject: "BookSwap - konto zostanie usunięte",
+		Body: fmt.Sprintf(
+			"Cześć %s,\n\notrzymaliśmy prośbę o usunięcie Twojego konta.\n"+
+				"Konto zostanie trwale usunięte %s.\n"+
+				"Do tego czasu możesz zalogować się i anulować usunięcie w ustawieniach konta.\n",
+			authUser.Name, status.ScheduledAt.Format("2006-01-02 15:04 MST"),
+		),
+	}); err != nil {
+		log.Printf("Nie udało się wysłać potwierdzenia usunięcia konta do %s: %v", authUser.Email, err)
+	}
+
+	return status, nil
+}
+
+// CancelDeletion anuluje zaplanowane usunięcie konta
+func (s *AccountService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.CancelDeletion(ctx, userID, time.Now().UTC())
+}
+
+// Export zbiera wszystkie dane użytkownika
+func (s *AccountService) Export(ctx context.Context, userID uuid.UUID) (*domain.Export, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = ""
+
+	export := &domain.Export{
+		ExportedAt: time.Now().UTC(),
+		Profile:    user,
+	}
+
+	if export.Books, err = s.exports.ListBooks(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Transactions, err = s.exports.ListTransactions(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Messages, err = s.exports.ListMessages(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Reviews, err = s.exports.ListReviews(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// PurgeDue anonimizuje konta, których okres karencji minął, i usuwa z magazynu
+// pliki zdjęć ich ogłoszeń
+func (s *AccountService) PurgeDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	purged := 0
+
+	for {
+		ids, err := s.repo.ListDueForDeletion(ctx, now.Add(-s.deletionGrace), purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for _, id := range ids {
+			storageKeys, err := s.repo.Anonymize(ctx, id, now)
+			if err != nil {
+				return purged, fmt.Errorf("failed to purge account %s: %w", id, err)
+			}
+			s.images.DeleteImageFiles(ctx, storageKeys)
+			purged++
+		}
+
+		if len(ids) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// RunPurger okresowo usuwa konta po okresie karencji, dopóki ctx nie zostanie anulowany
+func (s *AccountService) RunPurger(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeDue(ctx)
+			if err != nil {
+				log.Printf("Błąd usuwania kont: %v", err)
+			}
+			if purged > 0 {
+				log.Printf("Usunięto %d kont po okresie karencji", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/users/domain"
+)
+
+// fakeAccountRepo trzyma konta oczekujące na usunięcie i kolumnę storage_key
+// zdjęć ich ogłoszeń (nil dla zdjęć sprzed migracji 019, bez plików w magazynie)
+type fakeAccountRepo struct {
+	due        []uuid.UUID
+	images     map[uuid.UUID][]*string
+	anonymized []uuid.UUID
+}
+
+func (r *fakeAccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *fakeAccountRepo) RequestDeletion(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return nil
+}
+
+func (r *fakeAccountRepo) CancelDeletion(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return nil
+}
+
+func (r *fakeAccountRepo) ListDueForDeletion(ctx context.Context, requestedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	ids := r.due
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	r.due = r.due[len(ids):]
+	return ids, nil
+}
+
+// Anonymize usuwa wszystkie zdjęcia, ale jak repozytorium Postgres zwraca
+// tylko klucze zdjęć, które mają pliki
+func (r *fakeAccountRepo) Anonymize(ctx context.Context, id uuid.UUID, now time.Time) ([]string, error) {
+	r.anonymized = append(r.anonymized, id)
+	var keys []string
+	for _, key := range r.images[id] {
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+	delete(r.images, id)
+	return keys, nil
+}
+
+type fakeImageRemover struct {
+	deleted []string
+}
+
+func (f *fakeImageRemover) DeleteImageFiles(ctx context.Context, storageKeys []string) {
+	f.deleted = append(f.deleted, storageKeys...)
+}
+
+func storageKey(key string) *string {
+	return &key
+}
+
+func TestPurgeDueDeletesImageFiles(t *testing.T) {
+	withImages, withoutImages := uuid.New(), uuid.New()
+	repo := &fakeAccountRepo{
+		due: []uuid.UUID{withImages, withoutImages},
+		images: map[uuid.UUID][]*string{
+			withImages: {storageKey("books/b1/i1"), storageKey("books/b2/i2")},
+		},
+	}
+	images := &fakeImageRemover{}
+	svc := NewAccountService(repo, nil, nil, nil, images, nil, 30*24*time.Hour)
+
+	purged, err := svc.PurgeDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 || len(repo.anonymized) != 2 {
+		t.Errorf("purged %d accounts (anonymized %v), want 2", purged, repo.anonymized)
+	}
+	if len(images.deleted) != 2 || images.deleted[0] != "books/b1/i1" || images.deleted[1] != "books/b2/i2" {
+		t.Errorf("deleted image files %v, want both images of the purged account", images.deleted)
+	}
+}
+
+func TestPurgeDueSkipsImagesWithoutFiles(t *testing.T) {
+	mixed, legacyOnly, next := uuid.New(), uuid.New(), uuid.New()
+	repo := &fakeAccountRepo{
+		due: []uuid.UUID{legacyOnly, mixed, next},
+		images: map[uuid.UUID][]*string{
+			legacyOnly: {nil},
+			mixed:      {nil, storageKey("books/b1/i1")},
+			next:       {storageKey("books/b2/i2")},
+		},
+	}
+	images := &fakeImageRemover{}
+	svc := NewAccountService(repo, nil, nil, nil, images, nil, 30*24*time.Hour)
+
+	// Zdjęcie bez pliku nie zatrzymuje usuwania tego ani kolejnych kont
+	purged, err := svc.PurgeDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 3 || len(repo.images) != 0 {
+		t.Errorf("purged %d accounts, images left for %d, want 3 and none", purged, len(repo.images))
+	}
+	if len(images.deleted) != 2 || images.deleted[0] != "books/b1/i1" || images.deleted[1] != "books/b2/i2" {
+		t.Errorf("deleted image files %v, want only the images with files", images.deleted)
+	}
+}
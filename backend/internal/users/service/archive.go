@@ -0,0 +1,45 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/users/domain"
+)
+
+// WriteArchive zapisuje eksport jako archiwum ZIP z osobnym plikiem JSON
+// dla każdego rodzaju danych
+func WriteArchive(w io.Writer, export *domain.Export) error {
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"books.json", export.Books},
+		{"transactions.json", export.Transactions},
+		{"messages.json", export.Messages},
+		{"reviews.json", export.Reviews},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", file.name, err)
+		}
+
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Ex6linz/BookSwap/backend/internal/users/domain"
+)
+
+// UserRepository interfejs definiujący dostęp do profili użytkowników
+type UserRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	Update(ctx context.Context, id uuid.UUID, update *domain.UserUpdate, now time.Time) (*domain.User, error)
+}
+
+type UserService struct {
+	repo UserRepository
+}
+
+func NewUserService(repo UserRepository) *UserService {
+	return &UserService{
+		repo: repo,
+	}
+}
+
+// GetMe zwraca pełne dane zalogowanego użytkownika
+func (s *UserService) GetMe(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// UpdateMe aktualizuje profil zalogowanego użytkownika
+func (s *UserService) UpdateMe(ctx context.Context, userID uuid.UUID, update *domain.UserUpdate) (*domain.User, error) {
+	if err := update.Normalize(); err != nil {
+		return nil, err
+	}
+
+	if update.IsEmpty() {
+		return s.GetMe(ctx, userID)
+	}
+
+	user, err := s.repo.Update(ctx, userID, update, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// GetProfile zwraca publiczny profil dowolnego użytkownika
+func (s *UserService) GetProfile(ctx context.Context, userID uuid.UUID) (*domain.PublicProfile, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsDeleted() {
+		return nil, domain.ErrUserNotFound
+	}
+	return user.Public(), nil
+}
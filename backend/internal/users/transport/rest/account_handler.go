@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	"github.com/Ex6linz/BookSwap/backend/internal/users/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/users/service"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// @Summary Usunięcie konta (po okresie karencji)
+// @Description Wylogowuje wszystkie sesje; do terminu usunięcia można się zalogować i je anulować
+// @Accept json
+// @Produce json
+// @Param input body domain.DeleteAccountRequest true "Obecne hasło"
+// @Success 202 {object} domain.DeletionStatus
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 403 {object} authRest.ErrorResponse
+// @Failure 429 {object} authRest.ErrorResponse
+// @Router /me [delete]
+func (h *AccountHandler) DeleteMe(c *gin.Context) {
+	var req domain.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+	req.IP = c.ClientIP()
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	status, err := h.accountService.RequestDeletion(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, status)
+}
+
+// @Summary Anulowanie zaplanowanego usunięcia konta
+// @Success 204
+// @Failure 409 {object} authRest.ErrorResponse
+// @Router /me/deletion/cancel [post]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+	if err := h.accountService.CancelDeletion(c.Request.Context(), userID); err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Eksport danych użytkownika (archiwum ZIP z plikami JSON)
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 401 {object} authRest.ErrorResponse
+// @Router /me/export [get]
+func (h *AccountHandler) Export(c *gin.Context) {
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	export, err := h.accountService.Export(c.Request.Context(), userID)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("bookswap-export-%s.zip", export.ExportedAt.Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// Nagłówki są już wysłane, więc błąd możemy tylko zalogować
+	if err := service.WriteArchive(c.Writer, export); err != nil {
+		log.Printf("Błąd zapisu eksportu danych użytkownika %s: %v", userID, err)
+	}
+}
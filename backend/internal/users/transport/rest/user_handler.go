@@ -0,0 +1,142 @@
+package rest
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	authDomain "github.com/Ex6linz/BookSwap/backend/internal/auth/domain"
+	authRest "github.com/Ex6linz/BookSwap/backend/internal/auth/transport/rest"
+	"github.com/Ex6linz/BookSwap/backend/internal/users/domain"
+	"github.com/Ex6linz/BookSwap/backend/internal/users/service"
+)
+
+type UserHandler struct {
+	userService *service.UserService
+}
+
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// @Summary Dane zalogowanego użytkownika
+// @Produce json
+// @Success 200 {object} domain.User
+// @Failure 401 {object} authRest.ErrorResponse
+// @Router /me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	user, err := h.userService.GetMe(c.Request.Context(), userID)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Aktualizacja profilu zalogowanego użytkownika
+// @Accept json
+// @Produce json
+// @Param input body domain.UserUpdate true "Zmieniane pola profilu"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 401 {object} authRest.ErrorResponse
+// @Router /me [patch]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var req domain.UserUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-request",
+			Message: "Nieprawidłowy format żądania",
+		})
+		return
+	}
+
+	userID, _ := authRest.GetUserIDFromContext(c.Request.Context())
+
+	user, err := h.userService.UpdateMe(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Publiczny profil użytkownika
+// @Produce json
+// @Param id path string true "ID użytkownika"
+// @Success 200 {object} domain.PublicProfile
+// @Failure 400 {object} authRest.ErrorResponse
+// @Failure 404 {object} authRest.ErrorResponse
+// @Router /users/{id} [get]
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-id",
+			Message: "Nieprawidłowy identyfikator",
+		})
+		return
+	}
+
+	profile, err := h.userService.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func handleUserError(c *gin.Context, err error) {
+	var validationErr *domain.ValidationError
+	var tooManyAttempts *authDomain.TooManyAttemptsError
+
+	switch {
+	case errors.As(err, &tooManyAttempts):
+		retryAfter := int(math.Ceil(tooManyAttempts.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(http.StatusTooManyRequests, authRest.ErrorResponse{
+			Code:    "too-many-attempts",
+			Message: "Zbyt wiele nieudanych prób, spróbuj ponownie później",
+		})
+	case errors.Is(err, authDomain.ErrInvalidCurrentPassword):
+		c.JSON(http.StatusForbidden, authRest.ErrorResponse{
+			Code:    "invalid-current-password",
+			Message: "Nieprawidłowe obecne hasło",
+		})
+	case errors.Is(err, authDomain.ErrPasswordNotSet):
+		c.JSON(http.StatusConflict, authRest.ErrorResponse{
+			Code:    "password-not-set",
+			Message: "Konto nie ma hasła - ustaw je przez resetowanie hasła",
+		})
+	case errors.Is(err, domain.ErrDeletionNotRequested):
+		c.JSON(http.StatusConflict, authRest.ErrorResponse{
+			Code:    "deletion-not-requested",
+			Message: "Usunięcie konta nie zostało zaplanowane",
+		})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, authRest.ErrorResponse{
+			Code:    "invalid-field",
+			Message: "Nieprawidłowa wartość pola " + validationErr.Field,
+			Field:   validationErr.Field,
+		})
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, authDomain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, authRest.ErrorResponse{
+			Code:    "user-not-found",
+			Message: "Nie znaleziono użytkownika",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, authRest.ErrorResponse{
+			Code:    "internal-error",
+			Message: "Wystąpił błąd wewnętrzny",
+		})
+	}
+}
@@ -0,0 +1,17 @@
+-- Tabela tokenów odświeżających (refresh tokens)
+-- Przechowujemy wyłącznie hash SHA-256 tokenu, nigdy jego jawną postać.
+-- Tokeny z tej samej "rodziny" (family_id) powstają przez kolejne rotacje
+-- jednego logowania - ponowne użycie zrotowanego tokenu unieważnia całą rodzinę.
+CREATE TABLE refresh_tokens (
+                                id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                family_id UUID NOT NULL,
+                                token_hash VARCHAR(64) NOT NULL UNIQUE,
+                                expires_at TIMESTAMP NOT NULL,
+                                revoked_at TIMESTAMP,
+                                replaced_by UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+                                created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens(family_id);
@@ -0,0 +1,17 @@
+-- Unieważnione tokeny dostępowe (po identyfikatorze jti).
+-- Wpis jest potrzebny tylko do momentu wygaśnięcia tokenu.
+CREATE TABLE revoked_tokens (
+                                jti VARCHAR(64) PRIMARY KEY,
+                                user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                expires_at TIMESTAMP NOT NULL,
+                                revoked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Globalne unieważnienie: wszystkie tokeny użytkownika wystawione
+-- przed revoked_before są nieważne (wylogowanie ze wszystkich urządzeń)
+CREATE TABLE user_token_revocations (
+                                        user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                                        revoked_before TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_revoked_tokens_expires ON revoked_tokens(expires_at);
@@ -0,0 +1,13 @@
+-- Jednorazowe tokeny wysyłane mailem (np. reset hasła).
+-- Przechowujemy wyłącznie hash SHA-256 tokenu.
+CREATE TABLE one_time_tokens (
+                                 id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                 user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                 purpose VARCHAR(50) NOT NULL, -- password_reset
+                                 token_hash VARCHAR(64) NOT NULL UNIQUE,
+                                 expires_at TIMESTAMP NOT NULL,
+                                 used_at TIMESTAMP,
+                                 created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_one_time_tokens_user_purpose ON one_time_tokens(user_id, purpose);
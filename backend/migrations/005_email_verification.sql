@@ -0,0 +1,5 @@
+-- Potwierdzenie adresu email
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- Istniejące konta działały bez weryfikacji - nie odbieramy im uprawnień
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,19 @@
+-- Role użytkowników: user, moderator, admin
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin'));
+
+-- Pierwszego administratora nadajemy ręcznie, np.:
+-- UPDATE users SET role = 'admin' WHERE email = 'admin@example.com';
+
+-- Dziennik działań moderacyjnych (usunięte ogłoszenia i opinie)
+CREATE TABLE moderation_actions (
+                                    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                    moderator_id UUID NOT NULL REFERENCES users(id),
+                                    target_type VARCHAR(20) NOT NULL, -- book, review
+                                    target_id UUID NOT NULL,
+                                    action VARCHAR(20) NOT NULL, -- remove
+                                    reason TEXT,
+                                    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_moderation_actions_target ON moderation_actions(target_type, target_id);
@@ -0,0 +1,9 @@
+-- Liczniki nieudanych logowań (klucz: "account:<email>" lub "ip:<adres>")
+CREATE TABLE login_attempts (
+                                key VARCHAR(320) PRIMARY KEY,
+                                failures INTEGER NOT NULL DEFAULT 0,
+                                last_failure_at TIMESTAMP NOT NULL,
+                                locked_until TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_login_attempts_last_failure ON login_attempts(last_failure_at);
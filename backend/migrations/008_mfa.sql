@@ -0,0 +1,19 @@
+-- Uwierzytelnianie dwuskładnikowe (TOTP)
+CREATE TABLE user_mfa (
+                          user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                          secret_encrypted TEXT NOT NULL,
+                          confirmed_at TIMESTAMP,
+                          last_used_step BIGINT NOT NULL DEFAULT 0,
+                          created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Jednorazowe kody awaryjne (przechowujemy tylko hash SHA-256)
+CREATE TABLE mfa_recovery_codes (
+                                    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                    code_hash VARCHAR(64) NOT NULL,
+                                    used_at TIMESTAMP,
+                                    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
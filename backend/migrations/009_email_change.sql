@@ -0,0 +1,3 @@
+-- Zmiana adresu email: token wysyłany na nowy adres przechowuje ten adres
+-- do chwili potwierdzenia
+ALTER TABLE one_time_tokens ADD COLUMN payload TEXT;
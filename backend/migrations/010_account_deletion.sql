@@ -0,0 +1,7 @@
+-- Usuwanie kont (RODO). Konto najpierw czeka okres karencji, w którym
+-- użytkownik może anulować usunięcie, a potem jest anonimizowane - wiersz
+-- zostaje, bo odwołują się do niego transakcje i opinie innych osób.
+ALTER TABLE users ADD COLUMN deletion_requested_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_users_deletion_requested ON users(deletion_requested_at) WHERE deletion_requested_at IS NOT NULL;
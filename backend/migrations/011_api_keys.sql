@@ -0,0 +1,16 @@
+-- Osobiste klucze API do skryptów i integracji.
+-- Przechowujemy wyłącznie hash SHA-256 klucza; prefiks służy do rozpoznania klucza na liście.
+CREATE TABLE api_keys (
+                          id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                          user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                          name VARCHAR(100) NOT NULL,
+                          prefix VARCHAR(20) NOT NULL,
+                          key_hash VARCHAR(64) NOT NULL UNIQUE,
+                          scopes TEXT[] NOT NULL DEFAULT '{}', -- pusta lista = pełne uprawnienia użytkownika
+                          expires_at TIMESTAMP,
+                          last_used_at TIMESTAMP,
+                          revoked_at TIMESTAMP,
+                          created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys(user_id);
@@ -0,0 +1,43 @@
+-- Serwer autoryzacji OAuth2 (authorization code + PKCE) dla aplikacji zewnętrznych
+
+CREATE TABLE oauth_clients (
+                               id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                               client_id VARCHAR(64) NOT NULL UNIQUE,
+                               client_secret_hash VARCHAR(64), -- NULL dla klientów publicznych (SPA, aplikacje mobilne)
+                               name VARCHAR(100) NOT NULL,
+                               owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               redirect_uris TEXT[] NOT NULL,
+                               scopes TEXT[] NOT NULL, -- zakresy, o które aplikacja może prosić
+                               revoked_at TIMESTAMP,
+                               created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_oauth_clients_owner ON oauth_clients(owner_id);
+
+-- Jednorazowe kody autoryzacyjne (przechowujemy hash SHA-256)
+CREATE TABLE oauth_authorization_codes (
+                                           code_hash VARCHAR(64) PRIMARY KEY,
+                                           client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+                                           user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                           redirect_uri TEXT NOT NULL,
+                                           scopes TEXT[] NOT NULL,
+                                           code_challenge VARCHAR(128) NOT NULL,
+                                           expires_at TIMESTAMP NOT NULL,
+                                           used_at TIMESTAMP,
+                                           created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Zgody użytkowników na dostęp aplikacji do konta
+CREATE TABLE oauth_consents (
+                                user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+                                scopes TEXT[] NOT NULL,
+                                granted_at TIMESTAMP NOT NULL,
+                                PRIMARY KEY (user_id, client_id)
+);
+
+-- Tokeny odświeżające aplikacji pamiętają klienta i nadane zakresy
+ALTER TABLE refresh_tokens ADD COLUMN client_id VARCHAR(64);
+ALTER TABLE refresh_tokens ADD COLUMN scopes TEXT[];
+
+CREATE INDEX idx_refresh_tokens_client ON refresh_tokens(user_id, client_id) WHERE client_id IS NOT NULL;
@@ -0,0 +1,23 @@
+-- Logowanie przez zewnętrznych dostawców OpenID Connect
+
+-- Konta zewnętrzne powiązane z użytkownikami (sub jest unikalny w obrębie dostawcy)
+CREATE TABLE user_identities (
+                                 provider VARCHAR(50) NOT NULL,
+                                 subject VARCHAR(255) NOT NULL,
+                                 user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                 email VARCHAR(255) NOT NULL,
+                                 created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                                 PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
+
+-- Rozpoczęte logowania czekające na powrót użytkownika od dostawcy
+CREATE TABLE oidc_states (
+                             state_hash VARCHAR(64) PRIMARY KEY,
+                             provider VARCHAR(50) NOT NULL,
+                             nonce VARCHAR(64) NOT NULL,
+                             code_verifier VARCHAR(128) NOT NULL,
+                             expires_at TIMESTAMP NOT NULL,
+                             created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
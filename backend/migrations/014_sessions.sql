@@ -0,0 +1,14 @@
+-- Sesje użytkowników (urządzenia, na których są zalogowani).
+-- Identyfikator sesji to family_id jej tokenów odświeżających. Tokeny wydane
+-- przed tą migracją nie mają sesji - znikną z list po wygaśnięciu.
+
+CREATE TABLE sessions (
+                          id UUID PRIMARY KEY,
+                          user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                          ip VARCHAR(45) NOT NULL DEFAULT '',
+                          user_agent TEXT NOT NULL DEFAULT '',
+                          created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                          last_seen_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sessions_user ON sessions(user_id);
@@ -0,0 +1,22 @@
+-- Dziennik audytu działań administratorów w imieniu użytkowników.
+-- Wpisy są tylko dopisywane i przeżywają anonimizację konta.
+
+CREATE TABLE audit_log (
+                           id UUID PRIMARY KEY,
+                           actor_id UUID NOT NULL REFERENCES users(id),
+                           user_id UUID NOT NULL REFERENCES users(id),
+                           action VARCHAR(50) NOT NULL,
+                           token_id VARCHAR(64) NOT NULL,
+                           read_only BOOLEAN NOT NULL DEFAULT TRUE,
+                           reason TEXT NOT NULL DEFAULT '',
+                           method VARCHAR(10) NOT NULL DEFAULT '',
+                           path TEXT NOT NULL DEFAULT '',
+                           status INTEGER NOT NULL DEFAULT 0,
+                           ip VARCHAR(45) NOT NULL DEFAULT '',
+                           user_agent TEXT NOT NULL DEFAULT '',
+                           created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX idx_audit_log_user ON audit_log(user_id, created_at DESC);
+CREATE INDEX idx_audit_log_token ON audit_log(token_id);
@@ -0,0 +1,24 @@
+-- Wyszukiwanie pełnotekstowe ogłoszeń (parametr q listy książek).
+-- PostgreSQL nie ma wbudowanego słownika polskiego, więc polskie teksty
+-- indeksujemy konfiguracją simple (tylko małe litery, bez stemmingu):
+-- "książki" nie znajduje "książka". Zapytania dopasowują początki słów
+-- (prefiks ":*"), co pokrywa część odmian ("książ" znajduje obie formy).
+--
+-- Pełny stemming wymaga słownika ispell (np. z sjp.pl) zainstalowanego na
+-- serwerze bazy: nowej konfiguracji z mapowaniem na ten słownik, użycia jej
+-- poniżej i w zapytaniu w BookRepository.List.
+
+-- Tytuł i ISBN ważą najwięcej, potem autor, na końcu opis. Każdy tekst jest
+-- indeksowany w obu językach, bo ogłoszenia bywają po polsku i po angielsku.
+-- ISBN trafia do indeksu bez myślników.
+ALTER TABLE books ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', title), 'A') ||
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(COALESCE(isbn, ''), '[^0-9Xx]', '', 'g')), 'A') ||
+    setweight(to_tsvector('simple', author), 'B') ||
+    setweight(to_tsvector('english', author), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(description, '')), 'C') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_books_search ON books USING GIN (search_vector);
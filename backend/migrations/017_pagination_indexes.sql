@@ -0,0 +1,14 @@
+-- Indeksy pod stronicowanie kursorem: sortowanie po (klucz, id) w obrębie
+-- filtrów używanych przez listy.
+
+CREATE INDEX idx_books_created ON books(created_at DESC, id DESC);
+CREATE INDEX idx_books_title ON books(title, id);
+
+CREATE INDEX idx_transactions_lender_created ON transactions(lender_id, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_borrower_created ON transactions(borrower_id, created_at DESC, id DESC);
+
+CREATE INDEX idx_messages_sender_created ON messages(sender_id, created_at DESC, id DESC);
+CREATE INDEX idx_messages_receiver_created ON messages(receiver_id, created_at DESC, id DESC);
+
+CREATE INDEX idx_reviews_reviewed_created ON reviews(reviewed_id, created_at DESC, id DESC);
+CREATE INDEX idx_reviews_reviewed_rating ON reviews(reviewed_id, rating DESC, id DESC);
@@ -0,0 +1,14 @@
+-- Współrzędne użytkowników i opcjonalne miejsce odbioru książki.
+-- Odległości liczy wzór haversine w zapytaniach (pkg/geo), więc PostGIS nie jest
+-- wymagany. Publicznie widoczne są tylko współrzędne zaokrąglone do 0,01°.
+
+ALTER TABLE users
+    ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT users_coordinates_pair CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+-- Miejsce odbioru zastępuje lokalizację właściciela, jeśli jest podane
+ALTER TABLE books
+    ADD COLUMN pickup_latitude DOUBLE PRECISION CHECK (pickup_latitude BETWEEN -90 AND 90),
+    ADD COLUMN pickup_longitude DOUBLE PRECISION CHECK (pickup_longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT books_pickup_pair CHECK ((pickup_latitude IS NULL) = (pickup_longitude IS NULL));
@@ -0,0 +1,13 @@
+-- Zdjęcia książek przesyłane jako multipart i zapisywane w magazynie plików
+-- (pkg/storage) w kilku rozmiarach. storage_key to wspólny prefiks kluczy
+-- wariantów, potrzebny do usunięcia plików razem ze zdjęciem.
+
+ALTER TABLE book_images
+    ALTER COLUMN image_url TYPE TEXT,
+    ADD COLUMN storage_key TEXT,
+    ADD COLUMN medium_url TEXT,
+    ADD COLUMN thumbnail_url TEXT,
+    ADD COLUMN width INTEGER,
+    ADD COLUMN height INTEGER;
+
+CREATE INDEX idx_book_images_book ON book_images(book_id, created_at);
@@ -0,0 +1,31 @@
+-- ISBN jest zapisywany jako ISBN-13 bez myślników (pkg/isbn). Istniejące
+-- numery tracą separatory, a poprawne ISBN-10 są zamieniane na ISBN-13.
+-- Numerów z błędną cyfrą kontrolną nie zmieniamy - właściciel poprawi je
+-- przy następnej edycji ogłoszenia.
+
+-- Przedrostek "ISBN", "ISBN-10:" lub "ISBN-13:" trzeba usunąć w całości,
+-- inaczej cyfry 10 lub 13 z przedrostka zostałyby doklejone do numeru
+UPDATE books
+SET isbn = NULLIF(upper(regexp_replace(
+        regexp_replace(isbn, '^\s*ISBN(-1[03])?[:\s]*', '', 'i'),
+        '[^0-9Xx]', '', 'g')), '')
+WHERE isbn IS NOT NULL;
+
+-- ISBN-13 z ISBN-10: przedrostek 978, pierwsze 9 cyfr i nowa cyfra kontrolna
+UPDATE books b
+SET isbn = c.body || ((10 - (
+        SELECT sum(substr(c.body, i, 1)::int * CASE WHEN i % 2 = 1 THEN 1 ELSE 3 END)
+        FROM generate_series(1, 12) i
+    ) % 10) % 10)::text
+FROM (
+    SELECT id, '978' || left(isbn, 9) AS body
+    FROM books
+    WHERE isbn ~ '^[0-9]{9}[0-9X]$'
+      AND (
+          SELECT sum(CASE WHEN substr(isbn, i, 1) = 'X' THEN 10 ELSE substr(isbn, i, 1)::int END * (11 - i))
+          FROM generate_series(1, 10) i
+      ) % 11 = 0
+) c
+WHERE b.id = c.id;
+
+CREATE INDEX idx_books_isbn ON books(isbn);
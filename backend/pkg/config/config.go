@@ -1,10 +1,17 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
+	App struct {
+		// Publiczny adres frontendu, używany w linkach wysyłanych mailem
+		BaseURL string `mapstructure:"baseURL"`
+	} `mapstructure:"app"`
+
 	Server struct {
 		Port string `mapstructure:"port"`
 		Mode string `mapstructure:"mode"`
@@ -20,8 +27,123 @@ type Config struct {
 	} `mapstructure:"database"`
 
 	JWT struct {
-		Secret string `mapstructure:"secret"`
+		Algorithm          string        `mapstructure:"algorithm"` // EdDSA, RS256
+		KeyDir             string        `mapstructure:"keyDir"`    // katalog kluczy rotowanych automatycznie
+		Keys               []JWTKey      `mapstructure:"keys"`      // dodatkowe klucze podane wprost
+		RotationInterval   time.Duration `mapstructure:"rotationInterval"`
+		KeyRetention       time.Duration `mapstructure:"keyRetention"` // jak długo wycofany klucz weryfikuje tokeny
+		KeyReloadInterval  time.Duration `mapstructure:"keyReloadInterval"`
+		AccessTTL          time.Duration `mapstructure:"accessTTL"`
+		RefreshTTL         time.Duration `mapstructure:"refreshTTL"`
+		RevocationCacheTTL time.Duration `mapstructure:"revocationCacheTTL"` // czas pamiętania sprawdzeń unieważnienia
 	} `mapstructure:"jwt"`
+
+	Auth struct {
+		PasswordResetTTL     time.Duration `mapstructure:"passwordResetTTL"`
+		EmailVerificationTTL time.Duration `mapstructure:"emailVerificationTTL"`
+		EmailChangeTTL       time.Duration `mapstructure:"emailChangeTTL"`
+		AccountDeletionGrace time.Duration `mapstructure:"accountDeletionGrace"` // czas na anulowanie usunięcia konta
+		AccountPurgeInterval time.Duration `mapstructure:"accountPurgeInterval"`
+		ImpersonationTTL     time.Duration `mapstructure:"impersonationTTL"` // ważność tokenu administratora w imieniu użytkownika
+
+		Password struct {
+			Algorithm  string `mapstructure:"algorithm"` // argon2id, bcrypt
+			BcryptCost int    `mapstructure:"bcryptCost"`
+			Argon2     struct {
+				Memory      uint32 `mapstructure:"memory"` // KiB
+				Iterations  uint32 `mapstructure:"iterations"`
+				Parallelism uint8  `mapstructure:"parallelism"`
+			} `mapstructure:"argon2"`
+			Policy   PasswordPolicy `mapstructure:"policy"`
+			Breached struct {
+				Dir      string `mapstructure:"dir"` // katalog zakresów SHA-1; pusty wyłącza sprawdzanie
+				MinCount int    `mapstructure:"minCount"`
+			} `mapstructure:"breached"`
+		} `mapstructure:"password"`
+
+		Lockout struct {
+			Store   string        `mapstructure:"store"` // memory, postgres
+			Account LockoutPolicy `mapstructure:"account"`
+			IP      LockoutPolicy `mapstructure:"ip"`
+		} `mapstructure:"lockout"`
+	} `mapstructure:"auth"`
+
+	OIDC struct {
+		RedirectURL string         `mapstructure:"redirectURL"` // strona frontendu, na którą wracają użytkownicy od dostawców
+		StateTTL    time.Duration  `mapstructure:"stateTTL"`
+		Providers   []OIDCProvider `mapstructure:"providers"`
+	} `mapstructure:"oidc"`
+
+	OAuth struct {
+		AuthorizationCodeTTL time.Duration `mapstructure:"authorizationCodeTTL"`
+		IntrospectionClients []string      `mapstructure:"introspectionClients"` // serwery zasobów sprawdzające dowolne tokeny
+	} `mapstructure:"oauth"`
+
+	MFA struct {
+		Issuer        string `mapstructure:"issuer"`        // nazwa widoczna w aplikacji uwierzytelniającej
+		EncryptionKey string `mapstructure:"encryptionKey"` // klucz AES-256 (base64) do szyfrowania sekretów TOTP
+	} `mapstructure:"mfa"`
+
+	Mail struct {
+		Driver string `mapstructure:"driver"` // log, file
+		From   string `mapstructure:"from"`
+		Dir    string `mapstructure:"dir"` // katalog dla sterownika file
+	} `mapstructure:"mail"`
+
+	Storage struct {
+		Driver string `mapstructure:"driver"` // local, s3
+		Local  struct {
+			Dir     string `mapstructure:"dir"`
+			BaseURL string `mapstructure:"baseURL"` // ścieżka zaczynająca się od / jest serwowana przez API
+		} `mapstructure:"local"`
+		S3 struct {
+			Endpoint        string `mapstructure:"endpoint"`
+			Region          string `mapstructure:"region"`
+			Bucket          string `mapstructure:"bucket"`
+			AccessKeyID     string `mapstructure:"accessKeyID"`
+			SecretAccessKey string `mapstructure:"secretAccessKey"`
+			PublicURL       string `mapstructure:"publicURL"` // np. adres CDN; domyślnie adres zasobnika
+			PathStyle       bool   `mapstructure:"pathStyle"` // wymagane przez MinIO
+		} `mapstructure:"s3"`
+	} `mapstructure:"storage"`
+
+	Images struct {
+		MaxSize    int64 `mapstructure:"maxSize"` // bajty
+		MaxPerBook int   `mapstructure:"maxPerBook"`
+		Quality    int   `mapstructure:"quality"` // jakość JPEG wariantów (1-100)
+	} `mapstructure:"images"`
+}
+
+// JWTKey wskazuje klucz w formacie PEM; sam klucz publiczny służy tylko do weryfikacji
+type JWTKey struct {
+	ID             string `mapstructure:"id"`
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	PublicKeyFile  string `mapstructure:"publicKeyFile"`
+}
+
+// OIDCProvider opisuje zewnętrznego dostawcę logowania OpenID Connect
+type OIDCProvider struct {
+	Name         string   `mapstructure:"name"`   // używana w adresach, np. /auth/oidc/google/start
+	Issuer       string   `mapstructure:"issuer"` // adres, pod którym dostępne jest /.well-known/openid-configuration
+	ClientID     string   `mapstructure:"clientID"`
+	ClientSecret string   `mapstructure:"clientSecret"`
+	Scopes       []string `mapstructure:"scopes"` // domyślnie openid email profile
+}
+
+// LockoutPolicy opisuje blokadę logowania po serii nieudanych prób
+type LockoutPolicy struct {
+	Threshold int           `mapstructure:"threshold"`
+	BaseDelay time.Duration `mapstructure:"baseDelay"`
+	MaxDelay  time.Duration `mapstructure:"maxDelay"`
+	Window    time.Duration `mapstructure:"window"`
+}
+
+// PasswordPolicy opisuje wymagania wobec nowych haseł
+type PasswordPolicy struct {
+	MinLength          int  `mapstructure:"minLength"`
+	MaxLength          int  `mapstructure:"maxLength"`
+	MinCharClasses     int  `mapstructure:"minCharClasses"`
+	RejectPersonalInfo bool `mapstructure:"rejectPersonalInfo"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -31,6 +153,50 @@ func LoadConfig(path string) (config Config, err error) {
 
 	viper.AutomaticEnv()
 
+	viper.SetDefault("jwt.algorithm", "EdDSA")
+	viper.SetDefault("jwt.rotationInterval", 30*24*time.Hour)
+	viper.SetDefault("jwt.keyRetention", 48*time.Hour)
+	viper.SetDefault("jwt.keyReloadInterval", time.Minute)
+	viper.SetDefault("jwt.accessTTL", 15*time.Minute)
+	viper.SetDefault("jwt.refreshTTL", 30*24*time.Hour)
+	viper.SetDefault("jwt.revocationCacheTTL", 30*time.Second)
+	viper.SetDefault("auth.passwordResetTTL", time.Hour)
+	viper.SetDefault("auth.emailVerificationTTL", 48*time.Hour)
+	viper.SetDefault("auth.emailChangeTTL", 24*time.Hour)
+	viper.SetDefault("auth.accountDeletionGrace", 30*24*time.Hour)
+	viper.SetDefault("auth.accountPurgeInterval", time.Hour)
+	viper.SetDefault("auth.impersonationTTL", 15*time.Minute)
+	viper.SetDefault("auth.password.algorithm", "argon2id")
+	viper.SetDefault("auth.password.bcryptCost", 12)
+	viper.SetDefault("auth.password.argon2.memory", 64*1024)
+	viper.SetDefault("auth.password.argon2.iterations", 3)
+	viper.SetDefault("auth.password.argon2.parallelism", 4)
+	viper.SetDefault("auth.password.policy.minLength", 10)
+	viper.SetDefault("auth.password.policy.maxLength", 128)
+	viper.SetDefault("auth.password.policy.minCharClasses", 2)
+	viper.SetDefault("auth.password.policy.rejectPersonalInfo", true)
+	viper.SetDefault("auth.password.breached.minCount", 1)
+	viper.SetDefault("auth.lockout.store", "postgres")
+	viper.SetDefault("auth.lockout.account.threshold", 5)
+	viper.SetDefault("auth.lockout.account.baseDelay", 30*time.Second)
+	viper.SetDefault("auth.lockout.account.maxDelay", 15*time.Minute)
+	viper.SetDefault("auth.lockout.account.window", time.Hour)
+	viper.SetDefault("auth.lockout.ip.threshold", 20)
+	viper.SetDefault("auth.lockout.ip.baseDelay", 30*time.Second)
+	viper.SetDefault("auth.lockout.ip.maxDelay", 15*time.Minute)
+	viper.SetDefault("auth.lockout.ip.window", time.Hour)
+	viper.SetDefault("oidc.stateTTL", 10*time.Minute)
+	viper.SetDefault("oauth.authorizationCodeTTL", 5*time.Minute)
+	viper.SetDefault("mfa.issuer", "BookSwap")
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.local.dir", "./data/media")
+	viper.SetDefault("storage.local.baseURL", "/media")
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("images.maxSize", 10<<20)
+	viper.SetDefault("images.maxPerBook", 8)
+	viper.SetDefault("images.quality", 85)
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return
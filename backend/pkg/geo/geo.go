@@ -0,0 +1,130 @@
+// Package geo obsługuje współrzędne użytkowników i ogłoszeń. Odległości liczy
+// wzór haversine na zwykłych kolumnach liczbowych, więc nie wymaga PostGIS.
+//
+// Współrzędne są zapisywane dokładnie, ale publicznie (w odpowiedziach i przy
+// liczeniu odległości do cudzych ogłoszeń) używamy wersji zgrubnej - zaokrąglonej
+// do PublicDecimals miejsc po przecinku, czyli około kilometra. Siatka jest stała,
+// więc wielokrotne zapytania z różnych punktów nie zdradzą dokładnego adresu.
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusKm to średni promień Ziemi
+const EarthRadiusKm = 6371.0088
+
+// PublicDecimals to dokładność współrzędnych widocznych dla innych osób
+const PublicDecimals = 2
+
+var ErrInvalidPoint = errors.New("invalid coordinates")
+
+// Point to punkt na mapie w stopniach (WGS 84)
+type Point struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+}
+
+// ParsePoint odczytuje punkt w formacie "szerokość,długość", np. "52.2297,21.0122"
+func ParsePoint(text string) (Point, error) {
+	latText, lngText, ok := strings.Cut(text, ",")
+	if !ok {
+		return Point{}, ErrInvalidPoint
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	if err != nil {
+		return Point{}, ErrInvalidPoint
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngText), 64)
+	if err != nil {
+		return Point{}, ErrInvalidPoint
+	}
+
+	point := Point{Latitude: lat, Longitude: lng}
+	if err := point.Validate(); err != nil {
+		return Point{}, err
+	}
+	return point, nil
+}
+
+// Validate sprawdza zakres współrzędnych
+func (p Point) Validate() error {
+	if math.IsNaN(p.Latitude) || p.Latitude < -90 || p.Latitude > 90 ||
+		math.IsNaN(p.Longitude) || p.Longitude < -180 || p.Longitude > 180 {
+		return ErrInvalidPoint
+	}
+	return nil
+}
+
+// Public zwraca zgrubną wersję punktu, którą można pokazać innym osobom
+func (p Point) Public() Point {
+	scale := math.Pow10(PublicDecimals)
+	return Point{
+		Latitude:  math.Round(p.Latitude*scale) / scale,
+		Longitude: math.Round(p.Longitude*scale) / scale,
+	}
+}
+
+// PublicSQL zwraca wyrażenie SQL zaokrąglające współrzędną tak jak Point.Public
+func PublicSQL(expr string) string {
+	return fmt.Sprintf("round((%s)::numeric, %d)::float8", expr, PublicDecimals)
+}
+
+// Distance zwraca odległość w kilometrach między punktami (wzór haversine,
+// ten sam co w DistanceSQL)
+func Distance(a, b Point) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return EarthRadiusKm * 2 * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// DistanceSQL zwraca wyrażenie SQL z odległością w kilometrach między kolumnami
+// latExpr, lngExpr a punktem przekazanym w parametrach $latArg i $lngArg
+func DistanceSQL(latExpr, lngExpr string, latArg, lngArg int) string {
+	return fmt.Sprintf(`(%g * 2 * asin(least(1, sqrt(
+		power(sin(radians(%s - $%d::float8) / 2), 2) +
+		cos(radians($%d::float8)) * cos(radians(%s)) * power(sin(radians(%s - $%d::float8) / 2), 2)
+	))))`, EarthRadiusKm, latExpr, latArg, latArg, latExpr, lngExpr, lngArg)
+}
+
+// Box to prostokąt współrzędnych obejmujący okrąg wokół punktu. Służy do
+// wstępnego odsiania wierszy przed dokładnym liczeniem odległości.
+type Box struct {
+	MinLatitude, MaxLatitude   float64
+	MinLongitude, MaxLongitude float64
+	// BoundedLongitude jest fałszywe, gdy okrąg obejmuje biegun lub przecina
+	// południk 180° - wtedy zakres długości nie ogranicza wyników
+	BoundedLongitude bool
+}
+
+// BoundingBox wyznacza prostokąt obejmujący okrąg o promieniu radiusKm
+func BoundingBox(center Point, radiusKm float64) Box {
+	deltaLat := radiusKm / (EarthRadiusKm * math.Pi / 180)
+	box := Box{
+		MinLatitude: math.Max(center.Latitude-deltaLat, -90),
+		MaxLatitude: math.Min(center.Latitude+deltaLat, 90),
+	}
+	if box.MinLatitude <= -90 || box.MaxLatitude >= 90 {
+		return box
+	}
+
+	// Stopień długości jest najkrótszy na krawędzi prostokąta bliższej biegunowi
+	widestLat := math.Max(math.Abs(box.MinLatitude), math.Abs(box.MaxLatitude))
+	deltaLng := deltaLat / math.Cos(widestLat*math.Pi/180)
+	if center.Longitude-deltaLng < -180 || center.Longitude+deltaLng > 180 {
+		return box
+	}
+
+	box.MinLongitude = center.Longitude - deltaLng
+	box.MaxLongitude = center.Longitude + deltaLng
+	box.BoundedLongitude = true
+	return box
+}
@@ -0,0 +1,140 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+var warsaw = Point{Latitude: 52.2297, Longitude: 21.0122}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64 // km
+	}{
+		{"same point", warsaw, warsaw, 0},
+		{"Warszawa - Kraków", warsaw, Point{Latitude: 50.0647, Longitude: 19.9450}, 252.0},
+		{"one degree on the equator", Point{0, 0}, Point{0, 1}, 111.195},
+		{"across the antimeridian", Point{0, 179.5}, Point{0, -179.5}, 111.195},
+		{"antipodes", Point{0, 0}, Point{0, 180}, math.Pi * EarthRadiusKm},
+		{"pole to pole", Point{90, 0}, Point{-90, 0}, math.Pi * EarthRadiusKm},
+	}
+	for _, tt := range tests {
+		got := Distance(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 0.5 {
+			t.Errorf("%s: Distance = %.3f km, want %.3f", tt.name, got, tt.want)
+		}
+		if back := Distance(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+			t.Errorf("%s: Distance is not symmetric: %.6f vs %.6f", tt.name, got, back)
+		}
+	}
+}
+
+// destination zwraca punkt odległy o distanceKm od start w kierunku bearing (stopnie)
+func destination(start Point, bearing, distanceKm float64) Point {
+	lat1 := start.Latitude * math.Pi / 180
+	lng1 := start.Longitude * math.Pi / 180
+	theta := bearing * math.Pi / 180
+	delta := distanceKm / EarthRadiusKm
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(delta) + math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	lng2 := lng1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(lat1), math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2))
+	lng2 = math.Mod(lng2+3*math.Pi, 2*math.Pi) - math.Pi
+	return Point{Latitude: lat2 * 180 / math.Pi, Longitude: lng2 * 180 / math.Pi}
+}
+
+func (b Box) contains(p Point) bool {
+	if p.Latitude < b.MinLatitude || p.Latitude > b.MaxLatitude {
+		return false
+	}
+	return !b.BoundedLongitude || (p.Longitude >= b.MinLongitude && p.Longitude <= b.MaxLongitude)
+}
+
+func TestBoundingBoxContainsCircle(t *testing.T) {
+	tests := []struct {
+		name        string
+		center      Point
+		radiusKm    float64
+		wantBounded bool
+	}{
+		{"Warszawa", warsaw, 25, true},
+		{"southern hemisphere", Point{-33.8688, 151.2093}, 100, true},
+		{"far north", Point{78.2232, 15.6267}, 200, true},
+		{"circle over the north pole", Point{89.9, 0}, 50, false},
+		{"circle over the south pole", Point{-89.5, 120}, 100, false},
+		{"crossing the antimeridian east", Point{0, 179.9}, 50, false},
+		{"crossing the antimeridian west", Point{-16.5, -179.95}, 20, false},
+		{"next to the antimeridian", Point{0, 179}, 50, true},
+	}
+
+	for _, tt := range tests {
+		box := BoundingBox(tt.center, tt.radiusKm)
+		if box.BoundedLongitude != tt.wantBounded {
+			t.Errorf("%s: BoundedLongitude = %v, want %v", tt.name, box.BoundedLongitude, tt.wantBounded)
+		}
+		if box.MinLatitude < -90 || box.MaxLatitude > 90 {
+			t.Errorf("%s: latitude range %v..%v exceeds the globe", tt.name, box.MinLatitude, box.MaxLatitude)
+		}
+
+		// Żaden punkt okręgu nie może wypaść poza prostokąt
+		for bearing := 0.0; bearing < 360; bearing += 5 {
+			p := destination(tt.center, bearing, tt.radiusKm*0.999)
+			if !box.contains(p) {
+				t.Errorf("%s: point %v at bearing %v outside box %+v", tt.name, p, bearing, box)
+				break
+			}
+		}
+	}
+}
+
+func TestPublicRoundsToGrid(t *testing.T) {
+	tests := []struct {
+		in, want Point
+	}{
+		{warsaw, Point{52.23, 21.01}},
+		{Point{52.22499, 21.01501}, Point{52.22, 21.02}},
+		{Point{-33.8688, 151.2093}, Point{-33.87, 151.21}},
+		{Point{-0.004, 179.996}, Point{0, 180}},
+		{Point{90, -180}, Point{90, -180}},
+	}
+	for _, tt := range tests {
+		got := tt.in.Public()
+		if math.Abs(got.Latitude-tt.want.Latitude) > 1e-9 || math.Abs(got.Longitude-tt.want.Longitude) > 1e-9 {
+			t.Errorf("Public(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+		if got.Validate() != nil {
+			t.Errorf("Public(%v) = %v is not a valid point", tt.in, got)
+		}
+	}
+
+	// Punkty w jednej komórce siatki dają ten sam wynik, a przesunięcie
+	// zgrubnego punktu od dokładnego nie przekracza ok. 0.8 km
+	a, b := Point{52.2251, 21.0051}, Point{52.2349, 21.0149}
+	if a.Public() != b.Public() {
+		t.Errorf("points in one grid cell differ: %v vs %v", a.Public(), b.Public())
+	}
+	if d := Distance(b, b.Public()); d > 0.8 {
+		t.Errorf("public point moved by %.3f km", d)
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	valid := map[string]Point{
+		"52.2297,21.0122":     warsaw,
+		" 52.2297 , 21.0122 ": warsaw,
+		"-90,180":             {-90, 180},
+	}
+	for text, want := range valid {
+		got, err := ParsePoint(text)
+		if err != nil || got != want {
+			t.Errorf("ParsePoint(%q) = %v, %v; want %v", text, got, err, want)
+		}
+	}
+
+	for _, text := range []string{"", "52.2297", "52.2297;21.0122", "91,0", "0,-180.5", "NaN,0", "abc,def"} {
+		if _, err := ParsePoint(text); err != ErrInvalidPoint {
+			t.Errorf("ParsePoint(%q) err = %v, want ErrInvalidPoint", text, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package imaging przygotowuje zdjęcia przesłane przez użytkowników do
+// publikacji: sprawdza format, obraca według orientacji EXIF, zmniejsza do
+// kilku rozmiarów i koduje ponownie jako JPEG. Ponowne kodowanie usuwa
+// wszystkie metadane pliku (EXIF z lokalizacją GPS, XMP, profile aparatu).
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// MaxPixels ogranicza rozmiar obrazu po zdekodowaniu, żeby mały plik
+// o ogromnych wymiarach nie zajął całej pamięci serwera
+const MaxPixels = 40_000_000
+
+// ContentType to typ wszystkich plików zwracanych przez Process
+const ContentType = "image/jpeg"
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooManyPixels     = errors.New("image dimensions too large")
+)
+
+// Variant opisuje jeden rozmiar zdjęcia; dłuższy bok nie przekroczy MaxSize
+type Variant struct {
+	Name    string
+	MaxSize int
+}
+
+// Result to zakodowany wariant zdjęcia
+type Result struct {
+	Name   string
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Process dekoduje zdjęcie JPEG lub PNG i zwraca jego warianty w kolejności
+// variants. Obraz nigdy nie jest powiększany.
+func Process(data []byte, variants []Variant, quality int) ([]Result, error) {
+	var decode func([]byte) (image.Image, error)
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	decoded, err := decode(data)
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	img := orient(flatten(decoded), exifOrientation(data))
+
+	results := make([]Result, 0, len(variants))
+	for _, variant := range variants {
+		width, height := fit(img.Bounds().Dx(), img.Bounds().Dy(), variant.MaxSize)
+		// Mniejsze warianty powstają z poprzedniego - szybciej i bez utraty jakości
+		img = resize(img, width, height)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode image: %w", err)
+		}
+		results = append(results, Result{Name: variant.Name, Data: buf.Bytes(), Width: width, Height: height})
+	}
+	return results, nil
+}
+
+// flatten kopiuje obraz do RGBA na białym tle (JPEG nie ma przezroczystości)
+func flatten(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
+}
+
+// fit zwraca wymiary mieszczące się w kwadracie maxSize z zachowaniem proporcji
+func fit(width, height, maxSize int) (int, int) {
+	if maxSize <= 0 || (width <= maxSize && height <= maxSize) {
+		return width, height
+	}
+	if width >= height {
+		return maxSize, max(1, height*maxSize/width)
+	}
+	return max(1, width*maxSize/height), maxSize
+}
+
+// resize zmniejsza obraz, uśredniając piksele źródła przypadające na każdy
+// piksel wyniku (filtr pudełkowy)
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	if width == srcWidth && height == srcHeight {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for dy := 0; dy < height; dy++ {
+		y0 := dy * srcHeight / height
+		y1 := max(y0+1, (dy+1)*srcHeight/height)
+		for dx := 0; dx < width; dx++ {
+			x0 := dx * srcWidth / width
+			x1 := max(x0+1, (dx+1)*srcWidth/width)
+
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride+x0*4 : y*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			count := (x1 - x0) * (y1 - y0)
+			offset := dy*dst.Stride + dx*4
+			for i := range sum {
+				dst.Pix[offset+i] = uint8(sum[i] / count)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,247 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{R: 255, A: 255}
+	blue = color.RGBA{B: 255, A: 255}
+)
+
+// halves zwraca obraz z lewą połową czerwoną i prawą niebieską
+func halves(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation wstawia za znacznikiem SOI segment APP1 z EXIF, w którym
+// jest tylko znacznik Orientation
+func withOrientation(data []byte, orientation uint16, order binary.ByteOrder) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)       // liczba wpisów IFD
+	order.PutUint16(tiff[10:], 0x0112) // Orientation
+	order.PutUint16(tiff[12:], 3)      // SHORT
+	order.PutUint32(tiff[14:], 1)      // jedna wartość
+	order.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func near(c color.Color, want color.RGBA) bool {
+	r, g, b, _ := c.RGBA()
+	diff := func(a uint32, b uint8) bool {
+		d := int(a>>8) - int(b)
+		return d > -40 && d < 40
+	}
+	return diff(r, want.R) && diff(g, want.G) && diff(b, want.B)
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestProcessRejectsUnsupportedFormats(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, halves(8, 8), nil); err != nil {
+		t.Fatal(err)
+	}
+	valid := encodeJPEG(t, halves(8, 8))
+
+	tests := map[string][]byte{
+		"empty":          nil,
+		"text":           []byte("<html><body>not an image</body></html>"),
+		"gif":            gifData.Bytes(),
+		"truncated jpeg": valid[:len(valid)/2],
+		"jpeg header":    valid[:4],
+	}
+	for name, data := range tests {
+		if _, err := Process(data, []Variant{{Name: "large", MaxSize: 100}}, 85); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("%s: err = %v, want ErrUnsupportedFormat", name, err)
+		}
+	}
+}
+
+func TestProcessRejectsHugeDimensions(t *testing.T) {
+	// Mały plik PNG z nagłówkiem deklarującym 10000 x 5000 pikseli
+	data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	const ihdr = 8 + 4 // sygnatura PNG i długość fragmentu
+	binary.BigEndian.PutUint32(data[ihdr+4:], 10000)
+	binary.BigEndian.PutUint32(data[ihdr+8:], 5000)
+	binary.BigEndian.PutUint32(data[ihdr+17:], crc32.ChecksumIEEE(data[ihdr:ihdr+17]))
+
+	if _, err := Process(data, []Variant{{Name: "large", MaxSize: 100}}, 85); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("err = %v, want ErrTooManyPixels", err)
+	}
+}
+
+func TestProcessVariantSizes(t *testing.T) {
+	variants := []Variant{{Name: "large", MaxSize: 100}, {Name: "medium", MaxSize: 50}, {Name: "thumbnail", MaxSize: 10}}
+
+	tests := []struct {
+		name          string
+		width, height int
+		want          [][2]int
+	}{
+		{"landscape", 200, 100, [][2]int{{100, 50}, {50, 25}, {10, 5}}},
+		{"portrait", 60, 240, [][2]int{{25, 100}, {12, 50}, {2, 10}}},
+		{"smaller than large is not enlarged", 30, 20, [][2]int{{30, 20}, {30, 20}, {10, 6}}},
+		{"thin strip keeps at least one pixel", 400, 2, [][2]int{{100, 1}, {50, 1}, {10, 1}}},
+	}
+
+	for _, tt := range tests {
+		results, err := Process(encodePNG(t, halves(tt.width, tt.height)), variants, 85)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(results) != len(variants) {
+			t.Fatalf("%s: got %d results, want %d", tt.name, len(results), len(variants))
+		}
+		for i, result := range results {
+			if result.Name != variants[i].Name {
+				t.Errorf("%s: result %d is %s, want %s", tt.name, i, result.Name, variants[i].Name)
+			}
+			if result.Width != tt.want[i][0] || result.Height != tt.want[i][1] {
+				t.Errorf("%s/%s: %dx%d, want %dx%d", tt.name, result.Name, result.Width, result.Height, tt.want[i][0], tt.want[i][1])
+			}
+			bounds := decode(t, result.Data).Bounds()
+			if bounds.Dx() != result.Width || bounds.Dy() != result.Height {
+				t.Errorf("%s/%s: encoded %v, reported %dx%d", tt.name, result.Name, bounds, result.Width, result.Height)
+			}
+		}
+	}
+}
+
+func TestProcessAppliesEXIFOrientation(t *testing.T) {
+	// Lewa połowa czerwona, prawa niebieska; rx, ry to punkt, który po obróceniu
+	// ma być czerwony (w ułamkach szerokości i wysokości wyniku)
+	tests := []struct {
+		orientation   uint16
+		width, height int
+		rx, ry        float64
+	}{
+		{1, 40, 20, 0.25, 0.5},
+		{2, 40, 20, 0.75, 0.5}, // odbicie w poziomie
+		{3, 40, 20, 0.75, 0.5}, // obrót o 180°
+		{6, 20, 40, 0.5, 0.25}, // obrót w prawo: lewa krawędź na górze
+		{8, 20, 40, 0.5, 0.75}, // obrót w lewo: lewa krawędź na dole
+	}
+
+	source := encodeJPEG(t, halves(40, 20))
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for _, tt := range tests {
+			data := withOrientation(source, tt.orientation, order)
+			if got := exifOrientation(data); got != int(tt.orientation) {
+				t.Fatalf("exifOrientation = %d, want %d", got, tt.orientation)
+			}
+
+			results, err := Process(data, []Variant{{Name: "large", MaxSize: 100}}, 95)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := results[0]
+			if result.Width != tt.width || result.Height != tt.height {
+				t.Errorf("orientation %d: %dx%d, want %dx%d", tt.orientation, result.Width, result.Height, tt.width, tt.height)
+				continue
+			}
+
+			img := decode(t, result.Data)
+			rx, ry := int(tt.rx*float64(tt.width)), int(tt.ry*float64(tt.height))
+			bx, by := tt.width-1-rx, tt.height-1-ry
+			if !near(img.At(rx, ry), red) || !near(img.At(bx, by), blue) {
+				t.Errorf("orientation %d: (%d,%d)=%v (%d,%d)=%v, want red and blue",
+					tt.orientation, rx, ry, img.At(rx, ry), bx, by, img.At(bx, by))
+			}
+
+			// Wynik nie zawiera już EXIF - obrót jest zapisany w pikselach
+			if bytes.Contains(result.Data, []byte("Exif")) {
+				t.Errorf("orientation %d: output still contains EXIF", tt.orientation)
+			}
+		}
+	}
+}
+
+func TestExifOrientationIgnoresMalformedData(t *testing.T) {
+	source := encodeJPEG(t, halves(8, 8))
+	broken := withOrientation(source, 6, binary.BigEndian)
+	broken[4], broken[5] = 0xFF, 0xFF // długość segmentu poza plikiem
+
+	tests := map[string][]byte{
+		"no exif":           source,
+		"png":               encodePNG(t, halves(8, 8)),
+		"out of range":      withOrientation(source, 9, binary.LittleEndian),
+		"bad segment size":  broken,
+		"only start marker": {0xFF, 0xD8},
+	}
+	for name, data := range tests {
+		if got := exifOrientation(data); got != 1 {
+			t.Errorf("%s: exifOrientation = %d, want 1", name, got)
+		}
+	}
+}
+
+func TestProcessFlattensTransparency(t *testing.T) {
+	transparent := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	results, err := Process(encodePNG(t, transparent), []Variant{{Name: "large", MaxSize: 100}}, 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := decode(t, results[0].Data).At(5, 5); !near(c, color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("transparent pixel = %v, want white", c)
+	}
+}
@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientation odczytuje znacznik Orientation (0x0112) z segmentu APP1
+// pliku JPEG. Zwraca 1 (bez zmian), gdy znacznika nie ma lub jest uszkodzony.
+func exifOrientation(data []byte) int {
+	const (
+		markerSOS        = 0xDA
+		markerAPP1       = 0xE1
+		tagOrientation   = 0x0112
+		exifHeader       = "Exif\x00\x00"
+		ifdEntrySize     = 12
+		defaultDirection = 1
+	)
+
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return defaultDirection
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return defaultDirection
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++ // bajty wypełnienia między segmentami
+			continue
+		}
+		if marker == markerSOS {
+			return defaultDirection
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return defaultDirection
+		}
+		segment := data[pos+4 : end]
+		pos = end
+
+		if marker != markerAPP1 || len(segment) < len(exifHeader) || string(segment[:len(exifHeader)]) != exifHeader {
+			continue
+		}
+
+		tiff := segment[len(exifHeader):]
+		if len(tiff) < 8 {
+			return defaultDirection
+		}
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return defaultDirection
+		}
+
+		ifd := int(order.Uint32(tiff[4:]))
+		if ifd < 8 || ifd+2 > len(tiff) {
+			return defaultDirection
+		}
+		entries := int(order.Uint16(tiff[ifd:]))
+		for i := 0; i < entries; i++ {
+			entry := ifd + 2 + i*ifdEntrySize
+			if entry+ifdEntrySize > len(tiff) {
+				return defaultDirection
+			}
+			if order.Uint16(tiff[entry:]) != tagOrientation {
+				continue
+			}
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value < 1 || value > 8 {
+				return defaultDirection
+			}
+			return value
+		}
+		return defaultDirection
+	}
+	return defaultDirection
+}
+
+// orient obraca lub odbija obraz tak, żeby wyglądał jak w aparacie, bo po
+// ponownym zakodowaniu znacznik orientacji znika razem z resztą EXIF
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dstWidth, dstHeight := w, h
+	if orientation >= 5 {
+		dstWidth, dstHeight = h, w
+	}
+
+	// source zwraca współrzędne piksela źródła dla piksela wyniku (dx, dy)
+	var source func(dx, dy int) (int, int)
+	switch orientation {
+	case 2: // odbicie w poziomie
+		source = func(dx, dy int) (int, int) { return w - 1 - dx, dy }
+	case 3: // obrót o 180°
+		source = func(dx, dy int) (int, int) { return w - 1 - dx, h - 1 - dy }
+	case 4: // odbicie w pionie
+		source = func(dx, dy int) (int, int) { return dx, h - 1 - dy }
+	case 5: // transpozycja
+		source = func(dx, dy int) (int, int) { return dy, dx }
+	case 6: // obrót o 90° w prawo
+		source = func(dx, dy int) (int, int) { return dy, h - 1 - dx }
+	case 7: // transpozycja względem drugiej przekątnej
+		source = func(dx, dy int) (int, int) { return w - 1 - dy, h - 1 - dx }
+	case 8: // obrót o 90° w lewo
+		source = func(dx, dy int) (int, int) { return w - 1 - dy, dx }
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for dy := 0; dy < dstHeight; dy++ {
+		for dx := 0; dx < dstWidth; dx++ {
+			sx, sy := source(dx, dy)
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
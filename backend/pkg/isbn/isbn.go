@@ -0,0 +1,92 @@
+// Package isbn sprawdza numery ISBN-10 i ISBN-13 i sprowadza je do jednej
+// postaci: ISBN-13 bez myślników. Dzięki temu ta sama książka wpisana
+// w dowolnej formie ma w bazie ten sam numer.
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidFormat   = errors.New("expected ISBN-10 or ISBN-13")
+	ErrInvalidChecksum = errors.New("invalid ISBN check digit")
+)
+
+// Normalize przyjmuje ISBN-10 lub ISBN-13, także z myślnikami, spacjami
+// i przedrostkiem "ISBN", i zwraca 13 cyfr ISBN-13
+func Normalize(text string) (string, error) {
+	code := compact(text)
+
+	switch len(code) {
+	case 10:
+		if !allDigits(code[:9]) || !(allDigits(code[9:]) || code[9] == 'X') {
+			return "", ErrInvalidFormat
+		}
+		if !valid10(code) {
+			return "", ErrInvalidChecksum
+		}
+		body := "978" + code[:9]
+		return body + string(checkDigit13(body)), nil
+	case 13:
+		if !allDigits(code) || !(strings.HasPrefix(code, "978") || strings.HasPrefix(code, "979")) {
+			return "", ErrInvalidFormat
+		}
+		if checkDigit13(code[:12]) != code[12] {
+			return "", ErrInvalidChecksum
+		}
+		return code, nil
+	default:
+		return "", ErrInvalidFormat
+	}
+}
+
+// compact usuwa przedrostek "ISBN", "ISBN-10:" lub "ISBN-13:" oraz separatory
+func compact(text string) string {
+	code := strings.ToUpper(strings.TrimSpace(text))
+	if rest, ok := strings.CutPrefix(code, "ISBN"); ok {
+		for _, variant := range []string{"-10", "-13"} {
+			if after, ok := strings.CutPrefix(rest, variant); ok && (after == "" || after[0] == ':' || after[0] == ' ') {
+				rest = after
+				break
+			}
+		}
+		code = strings.TrimLeft(rest, ": ")
+	}
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// valid10 sprawdza cyfrę kontrolną ISBN-10 (suma ważona modulo 11, X oznacza 10)
+func valid10(code string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		digit := int(code[i] - '0')
+		if code[i] == 'X' {
+			digit = 10
+		}
+		sum += digit * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+// checkDigit13 wylicza cyfrę kontrolną ISBN-13 dla pierwszych 12 cyfr
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(body[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func allDigits(code string) bool {
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package isbn
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"9780306406157", "9780306406157"},
+		{"978-0-306-40615-7", "9780306406157"},
+		{"0306406152", "9780306406157"},
+		{"0-306-40615-2", "9780306406157"},
+		{"ISBN 0-306-40615-2", "9780306406157"},
+		{"ISBN-10: 0-306-40615-2", "9780306406157"},
+		{"isbn-13: 978-0-306-40615-7", "9780306406157"},
+		{"ISBN:9780306406157", "9780306406157"},
+		{"  978 0 306 40615 7  ", "9780306406157"},
+		// Cyfra kontrolna X (10) w ISBN-10
+		{"080442957X", "9780804429573"},
+		{"0-8044-2957-x", "9780804429573"},
+		{"ISBN 0-9752298-0-X", "9780975229804"},
+		// Przedrostek 979 nie ma odpowiednika ISBN-10
+		{"979-10-90636-07-1", "9791090636071"},
+		{"9798886451740", "9798886451740"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{"", ErrInvalidFormat},
+		{"ISBN", ErrInvalidFormat},
+		{"030640615", ErrInvalidFormat},           // 9 cyfr
+		{"97803064061577", ErrInvalidFormat},      // 14 cyfr
+		{"03064061X2", ErrInvalidFormat},          // X poza ostatnią pozycją
+		{"978030640615X", ErrInvalidFormat},       // X w ISBN-13
+		{"9770306406157", ErrInvalidFormat},       // ISSN, nie ISBN
+		{"0306.40615.2", ErrInvalidFormat},        // kropki nie są separatorem
+		{"abcdefghij", ErrInvalidFormat},          // 10 znaków, ale nie cyfry
+		{"0306406153", ErrInvalidChecksum},        // zła cyfra kontrolna ISBN-10
+		{"030640615X", ErrInvalidChecksum},        // X w miejscu innej cyfry
+		{"9780306406158", ErrInvalidChecksum},     // zła cyfra kontrolna ISBN-13
+		{"9791090636072", ErrInvalidChecksum},     // zła cyfra kontrolna 979
+		{"978-0-306-40651-7", ErrInvalidChecksum}, // przestawione cyfry
+	}
+	for _, tt := range tests {
+		if got, err := Normalize(tt.in); err != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+var (
+	sqlPrefix     = regexp.MustCompile(`(?i)^\s*ISBN(-1[03])?[:\s]*`)
+	sqlSeparators = regexp.MustCompile(`[^0-9Xx]`)
+	sqlISBN10     = regexp.MustCompile(`^[0-9]{9}[0-9X]$`)
+)
+
+// sqlNormalize przepisuje wprost migrację 020_isbn_normalization.sql:
+// usunięcie przedrostka i separatorów, a potem zamianę poprawnego ISBN-10 na
+// ISBN-13 z indeksami od 1 jak w generate_series
+func sqlNormalize(raw string) string {
+	code := sqlPrefix.ReplaceAllString(raw, "")
+	code = strings.ToUpper(sqlSeparators.ReplaceAllString(code, ""))
+
+	if !sqlISBN10.MatchString(code) {
+		return code
+	}
+	sum10 := 0
+	for i := 1; i <= 10; i++ {
+		digit := int(code[i-1] - '0')
+		if code[i-1] == 'X' {
+			digit = 10
+		}
+		sum10 += digit * (11 - i)
+	}
+	if sum10%11 != 0 {
+		return code
+	}
+
+	body := "978" + code[:9]
+	sum13 := 0
+	for i := 1; i <= 12; i++ {
+		weight := 3
+		if i%2 == 1 {
+			weight = 1
+		}
+		sum13 += int(body[i-1]-'0') * weight
+	}
+	return body + fmt.Sprint((10-sum13%10)%10)
+}
+
+// Migracja musi zapisać w bazie to samo, co Normalize dla nowych ogłoszeń,
+// inaczej wyszukiwanie po ISBN nie znajdzie starszych książek
+func TestMigrationAgreesWithNormalize(t *testing.T) {
+	migration, err := os.ReadFile("../../migrations/020_isbn_normalization.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fragmenty, które sqlNormalize odtwarza
+	for _, fragment := range []string{
+		`regexp_replace(isbn, '^\s*ISBN(-1[03])?[:\s]*', '', 'i')`,
+		`'[^0-9Xx]', '', 'g'`,
+		`isbn ~ '^[0-9]{9}[0-9X]$'`,
+		`CASE WHEN substr(isbn, i, 1) = 'X' THEN 10 ELSE substr(isbn, i, 1)::int END * (11 - i)`,
+		`'978' || left(isbn, 9)`,
+		`CASE WHEN i % 2 = 1 THEN 1 ELSE 3 END`,
+		`((10 - (`,
+		`) % 10) % 10)`,
+	} {
+		if !strings.Contains(string(migration), fragment) {
+			t.Fatalf("migration no longer contains %q - update sqlNormalize", fragment)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 2000; n++ {
+		body := fmt.Sprintf("%09d", rng.Intn(1_000_000_000))
+		sum := 0
+		for i := 0; i < 9; i++ {
+			sum += int(body[i]-'0') * (10 - i)
+		}
+		check := "0123456789X"[(11-sum%11)%11 : (11-sum%11)%11+1]
+		isbn10 := body + check
+
+		for _, raw := range []string{
+			isbn10,
+			body[:1] + "-" + body[1:4] + "-" + body[4:9] + "-" + check,
+			"ISBN " + isbn10,
+			"ISBN-10: " + isbn10,
+			strings.ToLower(isbn10),
+		} {
+			want, err := Normalize(raw)
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", raw, err)
+			}
+			if got := sqlNormalize(raw); got != want {
+				t.Errorf("migration converts %q to %q, Normalize gives %q", raw, got, want)
+			}
+			if got := sqlNormalize("ISBN-13: " + want); got != want {
+				t.Errorf("migration changes ISBN-13 %q to %q", want, got)
+			}
+		}
+	}
+
+	// Numerów z błędną cyfrą kontrolną migracja nie zamienia na ISBN-13
+	if got := sqlNormalize("0-306-40615-3"); got != "0306406153" {
+		t.Errorf("invalid ISBN-10 converted to %q", got)
+	}
+}
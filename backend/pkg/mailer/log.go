@@ -0,0 +1,20 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// LogMailer wypisuje wiadomości do logu zamiast je wysyłać (środowisko lokalne)
+type LogMailer struct {
+	from string
+}
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	log.Printf("[mail] od: %s, do: %s, temat: %s\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message reprezentuje wiadomość email w postaci tekstowej
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer interfejs wysyłki wiadomości email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New tworzy mailer na podstawie nazwy sterownika z konfiguracji
+func New(driver, from, dir string) (Mailer, error) {
+	switch driver {
+	case "", "log":
+		return NewLogMailer(from), nil
+	case "file":
+		return NewFileMailer(from, dir)
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %q", driver)
+	}
+}
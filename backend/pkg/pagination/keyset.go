@@ -0,0 +1,29 @@
+package pagination
+
+import "fmt"
+
+// Keyset opisuje sortowanie zapytania SQL po kluczu, z ID rozstrzygającym remisy
+type Keyset struct {
+	Key  string // wyrażenie SQL klucza sortowania
+	ID   string // wyrażenie SQL identyfikatora
+	Desc bool
+}
+
+// OrderBy zwraca treść klauzuli ORDER BY
+func (k Keyset) OrderBy() string {
+	direction := "ASC"
+	if k.Desc {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s, %s %s", k.Key, direction, k.ID, direction)
+}
+
+// After zwraca warunek wybierający elementy za kursorem. Klucz i ID kursora
+// trafiają do zapytania jako parametry $arg i $arg+1.
+func (k Keyset) After(arg int) string {
+	operator := ">"
+	if k.Desc {
+		operator = "<"
+	}
+	return fmt.Sprintf("(%s, %s) %s ($%d, $%d)", k.Key, k.ID, operator, arg, arg+1)
+}
@@ -0,0 +1,155 @@
+// Package pagination obsługuje stronicowanie list kursorem (keyset). Kursor
+// wskazuje ostatni element poprzedniej strony: jego klucz sortowania i ID, więc
+// kolejne strony nie gubią ani nie powtarzają elementów, gdy lista się zmienia.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Domyślna i maksymalna liczba elementów na stronie
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Sposoby sortowania list. Każda lista dopuszcza tylko część z nich.
+const (
+	SortNewest    = "newest"
+	SortTitle     = "title"
+	SortDistance  = "distance"
+	SortRating    = "rating"
+	SortRelevance = "relevance" // tylko przy wyszukiwaniu pełnotekstowym
+)
+
+// Error opisuje nieprawidłowy parametr stronicowania
+type Error struct {
+	Field  string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+// Request zawiera parametry stronicowania z zapytania
+type Request struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+	Sort   string `form:"sort"`
+}
+
+// Params to sprawdzone parametry stronicowania przekazywane do repozytorium
+type Params struct {
+	Limit int
+	Sort  string
+	After *Cursor // nil na pierwszej stronie
+}
+
+// Parse sprawdza parametry strony. Pierwszy z dozwolonych sposobów sortowania
+// jest domyślny, a kursor musi pochodzić z listy sortowanej tak samo.
+func (r Request) Parse(sorts ...string) (Params, error) {
+	params := Params{Limit: r.Limit, Sort: r.Sort}
+
+	if params.Limit == 0 {
+		params.Limit = DefaultLimit
+	}
+	if params.Limit < 1 || params.Limit > MaxLimit {
+		return Params{}, &Error{Field: "limit", Reason: fmt.Sprintf("must be between 1 and %d", MaxLimit)}
+	}
+
+	if params.Sort == "" && len(sorts) > 0 {
+		params.Sort = sorts[0]
+	}
+	if !slices.Contains(sorts, params.Sort) {
+		return Params{}, &Error{Field: "sort", Reason: "unsupported sort"}
+	}
+
+	if r.Cursor != "" {
+		cursor, err := decodeCursor(r.Cursor)
+		if err != nil || cursor.Sort != params.Sort {
+			return Params{}, &Error{Field: "cursor", Reason: "malformed or issued for a different sort"}
+		}
+		params.After = cursor
+	}
+	return params, nil
+}
+
+// Cursor wskazuje ostatni element poprzedniej strony
+type Cursor struct {
+	Sort string          `json:"s"`
+	Key  json.RawMessage `json:"k"`
+	ID   uuid.UUID       `json:"i"`
+}
+
+// ScanKey odczytuje klucz sortowania do zmiennej właściwego typu
+func (c *Cursor) ScanKey(dest any) error {
+	if err := json.Unmarshal(c.Key, dest); err != nil {
+		return &Error{Field: "cursor", Reason: "unexpected sort key"}
+	}
+	return nil
+}
+
+// Page to wspólna koperta odpowiedzi list
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// NewPage buduje stronę z wyników pobranych z zapasem jednego elementu
+// (Limit+1). Jeśli zapas istnieje, nextCursor wskazuje ostatni element strony.
+// keyOf zwraca klucz sortowania i ID elementu.
+func NewPage[T any](items []T, params Params, keyOf func(T) (any, uuid.UUID)) (*Page[T], error) {
+	page := &Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(items) <= params.Limit {
+		return page, nil
+	}
+
+	page.Items = items[:params.Limit]
+	key, id := keyOf(page.Items[params.Limit-1])
+
+	cursor, err := encodeCursor(params.Sort, key, id)
+	if err != nil {
+		return nil, err
+	}
+	page.NextCursor = cursor
+	return page, nil
+}
+
+func encodeCursor(sort string, key any, id uuid.UUID) (string, error) {
+	rawKey, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor key: %w", err)
+	}
+
+	data, err := json.Marshal(Cursor{Sort: sort, Key: rawKey, ID: id})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if len(cursor.Key) == 0 || cursor.ID == uuid.Nil {
+		return nil, fmt.Errorf("incomplete cursor")
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,185 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func cursorFor(t *testing.T, sort string, key any, id uuid.UUID) string {
+	t.Helper()
+	cursor, err := encodeCursor(sort, key, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cursor
+}
+
+func TestRequestParse(t *testing.T) {
+	id := uuid.New()
+	newest := cursorFor(t, SortNewest, "2024-05-01T10:00:00Z", id)
+	title := cursorFor(t, SortTitle, "Lalka", id)
+
+	tests := []struct {
+		name      string
+		req       Request
+		wantLimit int
+		wantSort  string
+		wantAfter bool
+		wantField string // pole błędu; puste, gdy żądanie jest poprawne
+	}{
+		{"defaults", Request{}, DefaultLimit, SortNewest, false, ""},
+		{"limit 0 means default", Request{Limit: 0}, DefaultLimit, SortNewest, false, ""},
+		{"minimal limit", Request{Limit: 1}, 1, SortNewest, false, ""},
+		{"maximal limit", Request{Limit: MaxLimit}, MaxLimit, SortNewest, false, ""},
+		{"limit above maximum", Request{Limit: MaxLimit + 1}, 0, "", false, "limit"},
+		{"negative limit", Request{Limit: -1}, 0, "", false, "limit"},
+		{"allowed sort", Request{Sort: SortTitle}, DefaultLimit, SortTitle, false, ""},
+		{"sort not allowed for the list", Request{Sort: SortDistance}, 0, "", false, "sort"},
+		{"cursor of the same sort", Request{Cursor: newest}, DefaultLimit, SortNewest, true, ""},
+		{"cursor of another sort", Request{Cursor: title}, 0, "", false, "cursor"},
+		{"cursor of another explicit sort", Request{Cursor: newest, Sort: SortTitle}, 0, "", false, "cursor"},
+		{"cursor not in base64", Request{Cursor: "not base64!"}, 0, "", false, "cursor"},
+		{"cursor not in JSON", Request{Cursor: base64.RawURLEncoding.EncodeToString([]byte("{oops"))}, 0, "", false, "cursor"},
+		{"cursor without id", Request{Cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"s":"newest","k":"x"}`))}, 0, "", false, "cursor"},
+		{"cursor without key", Request{Cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"s":"newest","i":"` + id.String() + `"}`))}, 0, "", false, "cursor"},
+	}
+
+	for _, tt := range tests {
+		params, err := tt.req.Parse(SortNewest, SortTitle)
+
+		if tt.wantField != "" {
+			var perr *Error
+			if !errors.As(err, &perr) || perr.Field != tt.wantField {
+				t.Errorf("%s: err = %v, want invalid %s", tt.name, err, tt.wantField)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if params.Limit != tt.wantLimit || params.Sort != tt.wantSort || (params.After != nil) != tt.wantAfter {
+			t.Errorf("%s: params = %+v, want limit %d, sort %s, cursor %v", tt.name, params, tt.wantLimit, tt.wantSort, tt.wantAfter)
+		}
+	}
+}
+
+func TestRequestParseDefaultSortIsFirstAllowed(t *testing.T) {
+	params, err := Request{}.Parse(SortRelevance, SortNewest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Sort != SortRelevance {
+		t.Errorf("sort = %s, want %s", params.Sort, SortRelevance)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.New()
+	params, err := Request{Cursor: cursorFor(t, SortRating, 4.5, id)}.Parse(SortRating)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var key float64
+	if err := params.After.ScanKey(&key); err != nil {
+		t.Fatal(err)
+	}
+	if key != 4.5 || params.After.ID != id {
+		t.Errorf("cursor = (%v, %s), want (4.5, %s)", key, params.After.ID, id)
+	}
+
+	var wrongType string
+	if err := params.After.ScanKey(&wrongType); err == nil {
+		t.Error("ScanKey accepted a key of another type")
+	}
+}
+
+type item struct {
+	id    uuid.UUID
+	title string
+}
+
+func TestNewPage(t *testing.T) {
+	items := make([]item, 4)
+	for i := range items {
+		items[i] = item{id: uuid.New(), title: string(rune('a' + i))}
+	}
+	keyOf := func(it item) (any, uuid.UUID) { return it.title, it.id }
+
+	tests := []struct {
+		name       string
+		items      []item
+		limit      int
+		wantItems  int
+		wantCursor bool
+	}{
+		{"nil result", nil, 3, 0, false},
+		{"fewer than limit", items[:2], 3, 2, false},
+		{"exactly limit is the last page", items[:3], 3, 3, false},
+		{"extra item trimmed", items, 3, 3, true},
+	}
+
+	for _, tt := range tests {
+		params := Params{Limit: tt.limit, Sort: SortTitle}
+		page, err := NewPage(tt.items, params, keyOf)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if page.Items == nil || len(page.Items) != tt.wantItems {
+			t.Errorf("%s: %d items (nil %v), want %d", tt.name, len(page.Items), page.Items == nil, tt.wantItems)
+		}
+		if (page.NextCursor != "") != tt.wantCursor {
+			t.Errorf("%s: nextCursor = %q, want present %v", tt.name, page.NextCursor, tt.wantCursor)
+		}
+		if !tt.wantCursor {
+			continue
+		}
+
+		// Kursor wskazuje ostatni element strony, nie element zapasowy
+		next, err := Request{Cursor: page.NextCursor, Limit: tt.limit}.Parse(SortTitle)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var key string
+		if err := next.After.ScanKey(&key); err != nil {
+			t.Fatal(err)
+		}
+		last := page.Items[len(page.Items)-1]
+		if key != last.title || next.After.ID != last.id {
+			t.Errorf("%s: cursor = (%s, %s), want (%s, %s)", tt.name, key, next.After.ID, last.title, last.id)
+		}
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	tests := []struct {
+		keyset      Keyset
+		arg         int
+		wantOrderBy string
+		wantAfter   string
+	}{
+		{
+			Keyset{Key: "b.title", ID: "b.id"}, 3,
+			"b.title ASC, b.id ASC",
+			"(b.title, b.id) > ($3, $4)",
+		},
+		{
+			Keyset{Key: "b.created_at", ID: "b.id", Desc: true}, 1,
+			"b.created_at DESC, b.id DESC",
+			"(b.created_at, b.id) < ($1, $2)",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.keyset.OrderBy(); got != tt.wantOrderBy {
+			t.Errorf("OrderBy() = %q, want %q", got, tt.wantOrderBy)
+		}
+		if got := tt.keyset.After(tt.arg); got != tt.wantAfter {
+			t.Errorf("After(%d) = %q, want %q", tt.arg, got, tt.wantAfter)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	valid := []string{"books/b1/i1/large.jpg", "a", "a/b.c/d", "..a/b", "a../b"}
+	for _, key := range valid {
+		if !validKey(key) {
+			t.Errorf("validKey(%q) = false, want true", key)
+		}
+	}
+
+	invalid := []string{"", "/etc/passwd", "../secret", "books/../../secret", "books/..", "./a", "a//b", "a/", `a\..\b`, "."}
+	for _, key := range invalid {
+		if validKey(key) {
+			t.Errorf("validKey(%q) = true, want false", key)
+		}
+	}
+}
+
+func TestLocalStorageRejectsTraversal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "uploads")
+	store, err := NewLocalStorage(dir, "/uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outside := filepath.Join(root, "outside.txt")
+	if err := os.WriteFile(outside, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, key := range []string{"../outside.txt", "books/../../outside.txt", outside} {
+		if err := store.Put(ctx, key, []byte("overwritten"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded", key)
+		}
+	}
+
+	if data, err := os.ReadFile(outside); err != nil || string(data) != "keep" {
+		t.Errorf("file outside storage changed: %q, %v", data, err)
+	}
+}
+
+func TestLocalStoragePutDeleteURL(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStorage(dir, "/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	key := "books/b1/i1/large.jpg"
+	if err := store.Put(ctx, key, []byte("jpeg"), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "books", "b1", "i1", "large.jpg")
+	if data, err := os.ReadFile(path); err != nil || string(data) != "jpeg" {
+		t.Fatalf("stored file = %q, %v", data, err)
+	}
+	// Po zapisie w katalogu nie zostają pliki tymczasowe
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("directory has %d entries, want 1", len(entries))
+	}
+
+	if got := store.URL(key); got != "/uploads/books/b1/i1/large.jpg" {
+		t.Errorf("URL = %s", got)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+	// Usunięcie nieistniejącego pliku nie jest błędem
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("second Delete: %v", err)
+	}
+}
+
+func TestLocalStorageDeleteRemovesEmptyDirectories(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStorage(dir, "/uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	keys := []string{"books/b1/i1/large.jpg", "books/b1/i1/thumbnail.jpg", "books/b1/i2/large.jpg"}
+	for _, key := range keys {
+		if err := store.Put(ctx, key, []byte("jpeg"), "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exists := func(path ...string) bool {
+		_, err := os.Stat(filepath.Join(append([]string{dir}, path...)...))
+		return err == nil
+	}
+
+	// Katalog zostaje, dopóki są w nim inne warianty
+	if err := store.Delete(ctx, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if !exists("books", "b1", "i1", "thumbnail.jpg") {
+		t.Fatal("Delete removed another variant of the image")
+	}
+
+	if err := store.Delete(ctx, keys[1]); err != nil {
+		t.Fatal(err)
+	}
+	if exists("books", "b1", "i1") {
+		t.Error("empty image directory left after deleting all variants")
+	}
+	if !exists("books", "b1", "i2", "large.jpg") {
+		t.Fatal("Delete removed another image of the book")
+	}
+
+	if err := store.Delete(ctx, keys[2]); err != nil {
+		t.Fatal(err)
+	}
+	if exists("books") {
+		t.Error("empty book directories left after deleting all images")
+	}
+	// Katalog magazynu zostaje nawet pusty
+	if !exists() {
+		t.Error("storage directory removed")
+	}
+}
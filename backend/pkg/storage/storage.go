@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Storage interfejs magazynu plików publikowanych pod stałym adresem (np. zdjęć)
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL zwraca publiczny adres pliku o podanym kluczu
+	URL(key string) string
+}
+
+// Config opisuje magazyn wybrany w konfiguracji
+type Config struct {
+	Driver string // local, s3
+
+	LocalDir     string
+	LocalBaseURL string
+
+	S3 S3Config
+}
+
+// New tworzy magazyn na podstawie nazwy sterownika z konfiguracji
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalDir, cfg.LocalBaseURL)
+	case "s3":
+		return NewS3Storage(cfg.S3, nil)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %q", cfg.Driver)
+	}
+}
+
+// validKey odrzuca klucze, które mogłyby wyjść poza katalog lub zasobnik
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}